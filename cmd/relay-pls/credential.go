@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// useVault determines whether persistent (BigQuery and Vault) storage has
+// been configured.
+//
+// TODO Implement cleaner (and more exact) handling for determining the type of server
+func useVault(cfg *opt.Config) bool {
+	return cfg.Table != "" && cfg.Project != "" && cfg.Dataset != ""
+}
+
+func newCredentialManager(ctx context.Context, cfg *opt.Config) (model.CredentialManager, func(), error) {
+	var credentialManager model.CredentialManager
+	var cleanup func()
+	var err error
+
+	if useVault(cfg) {
+		credentialManager, cleanup, err = NewVaultCredentialManager(ctx, cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize Vault credential manager: %w", err)
+		}
+	} else {
+		credentialManager, cleanup, err = NewInMemoryCredentialManager(ctx, cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize in memory credential manager: %w", err)
+		}
+	}
+
+	if !cfg.CredentialSignedTokens {
+		return credentialManager, cleanup, nil
+	}
+
+	var signedCredentialManager *manager.SignedCredentialManager
+	var signedCleanup func()
+
+	if useVault(cfg) {
+		signedCredentialManager, signedCleanup, err = NewVaultSignedCredentialManager(ctx, cfg, credentialManager)
+	} else {
+		signedCredentialManager, signedCleanup, err = NewInMemorySignedCredentialManager(ctx, cfg, credentialManager)
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to initialize signed credential manager: %w", err)
+	}
+
+	return signedCredentialManager, func() {
+		signedCleanup()
+		cleanup()
+	}, nil
+}
+
+func newRootCredentialManager(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*manager.RootCredentialManager, func(), error) {
+	if useVault(cfg) {
+		return NewVaultRootCredentialManager(ctx, cfg, credentialManager)
+	}
+
+	return NewInMemoryRootCredentialManager(ctx, cfg, credentialManager)
+}
+
+func newCredentialSweeper(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager, meter *metric.Meter) (*manager.CredentialSweeper, func(), error) {
+	if useVault(cfg) {
+		return NewVaultCredentialSweeper(ctx, cfg, credentialManager, meter)
+	}
+
+	return NewInMemoryCredentialSweeper(ctx, cfg, credentialManager, meter)
+}
+
+// rotateRoot implements the credential rotate-root command.
+func rotateRoot(ctx context.Context, cfg *opt.Config) error {
+	if !useVault(cfg) {
+		return fmt.Errorf("the root credential can only be rotated in persistent storage")
+	}
+
+	credentialManager, cleanup, err := newCredentialManager(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	rootCredentialManager, rootCleanup, err := newRootCredentialManager(ctx, cfg, credentialManager)
+	if err != nil {
+		return fmt.Errorf("failed to initialize root credential manager: %w", err)
+	}
+	defer rootCleanup()
+
+	if err := rootCredentialManager.Rotate(ctx); err != nil {
+		return fmt.Errorf("failed to rotate root credential: %w", err)
+	}
+
+	return nil
+}
+
+func runCommand(ctx context.Context, cfg *opt.Config, args []string) error {
+	if len(args) == 2 && args[0] == "credential" && args[1] == "rotate-root" {
+		return rotateRoot(ctx, cfg)
+	}
+
+	return fmt.Errorf("unknown command %q; supported commands: credential rotate-root", args)
+}
@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"os"
 
 	"github.com/puppetlabs/relay-pls/pkg/opt"
 	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/puppetlabs/relay-pls/pkg/server"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
 
+// defaultMaxRecvMsgSize is the gRPC default limit on received messages.
+const defaultMaxRecvMsgSize = 4 * 1024 * 1024
+
 func main() {
 	ctx := context.Background()
 
@@ -20,33 +25,127 @@ func main() {
 		log.Fatalf("failed to configure options: %v", err)
 	}
 
+	if len(os.Args) > 1 {
+		if err := runCommand(ctx, cfg, os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+
+		return
+	}
+
+	// Every component records its metrics with the meter of the one exporter
+	// that the telemetry server serves.
+	exporter, exporterCleanup, err := NewPrometheusExporter(ctx, cfg)
+	if err != nil {
+		log.Fatalf("failed to initialize metrics exporter: %v", err)
+	}
+	defer exporterCleanup()
+
+	meter, meterCleanup, err := NewMeter(ctx, cfg, exporter)
+	if err != nil {
+		log.Fatalf("failed to initialize meter: %v", err)
+	}
+	defer meterCleanup()
+
 	var srv plspb.LogServer
 	var cleanup func()
 
-	// TODO Implement cleaner (and more exact) handling for determining the type of server
-	if cfg.Table != "" && cfg.Project != "" && cfg.Dataset != "" {
-		srv, cleanup, err = NewBigQueryServer(ctx, cfg)
+	if useVault(cfg) {
+		srv, cleanup, err = NewBigQueryServer(ctx, cfg, meter)
 		if err != nil {
 			log.Fatal("failed to initialize BigQuery server")
 		}
+
+		_, purgerCleanup, err := NewBigQueryMessagePurger(ctx, cfg, meter)
+		if err != nil {
+			log.Fatal("failed to initialize message purger")
+		}
+		defer purgerCleanup()
 	} else {
 		srv, cleanup, err = NewInMemoryServer(ctx, cfg)
 		if err != nil {
 			log.Fatal("failed to initialize in memory server")
 		}
+	}
+
+	credentialManager, credentialManagerCleanup, err := newCredentialManager(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rootCredentialManager, rootCredentialCleanup, err := newRootCredentialManager(ctx, cfg, credentialManager)
+	if err != nil {
+		log.Fatal("failed to initialize root credential manager")
+	}
+
+	// The root token itself must never be logged.
+	if created, err := rootCredentialManager.Bootstrap(ctx); err != nil {
+		log.Fatalf("failed to bootstrap root credential: %v", err)
+	} else if created {
+		log.Print("created root credential; its token has been written to the configured destination")
+	}
+
+	_, credentialSweeperCleanup, err := newCredentialSweeper(ctx, cfg, credentialManager, meter)
+	if err != nil {
+		log.Fatal("failed to initialize credential sweeper")
+	}
+
+	credentialSrv, credentialCleanup, err := NewCredentialServer(ctx, cfg, credentialManager)
+	if err != nil {
+		log.Fatal("failed to initialize credential server")
+	}
+
+	authInterceptor, authCleanup, err := NewAuthInterceptor(ctx, cfg, credentialManager)
+	if err != nil {
+		log.Fatal("failed to initialize authentication")
+	}
+
+	auditor, auditCleanup, err := NewAuditor(ctx, cfg, srv, meter)
+	if err != nil {
+		log.Fatal("failed to initialize audit")
+	}
+
+	// Errors are translated innermost so that the audit trail and telemetry
+	// record the status returned to the client.
+	errorInterceptor := server.NewErrorInterceptor()
 
+	// Leave room beyond the largest payload for the rest of the request, so
+	// that oversized payloads are rejected with a useful error.
+	maxRecvMsgSize := cfg.MessageMaxPayloadSize + 64*1024
+	if maxRecvMsgSize < defaultMaxRecvMsgSize {
+		maxRecvMsgSize = defaultMaxRecvMsgSize
 	}
 
 	gs := grpc.NewServer(
-		grpc.UnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
-		grpc.StreamInterceptor(otelgrpc.StreamServerInterceptor()),
+		grpc.MaxRecvMsgSize(maxRecvMsgSize),
+		grpc.ChainUnaryInterceptor(
+			otelgrpc.UnaryServerInterceptor(),
+			auditor.UnaryRejectionInterceptor(),
+			authInterceptor.UnaryServerInterceptor(),
+			auditor.UnaryServerInterceptor(),
+			errorInterceptor.UnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			otelgrpc.StreamServerInterceptor(),
+			auditor.StreamRejectionInterceptor(),
+			authInterceptor.StreamServerInterceptor(),
+			auditor.StreamServerInterceptor(),
+			errorInterceptor.StreamServerInterceptor(),
+		),
 	)
 
 	plspb.RegisterLogServer(gs, srv)
+	plspb.RegisterCredentialServer(gs, credentialSrv)
 
 	defer cleanup()
+	defer credentialManagerCleanup()
+	defer rootCredentialCleanup()
+	defer credentialSweeperCleanup()
+	defer credentialCleanup()
+	defer authCleanup()
+	defer auditCleanup()
 
-	telemetryServer, telemetryCleanup, err := NewTelemetryServer(ctx, cfg)
+	telemetryServer, telemetryCleanup, err := NewTelemetryServer(ctx, cfg, exporter)
 	if err != nil {
 		log.Printf("failed to initialize telemetry server: %v", err)
 	}
@@ -7,17 +7,33 @@ import (
 	"context"
 
 	"github.com/google/wire"
+	"github.com/puppetlabs/relay-pls/pkg/audit"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
 	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
 	"github.com/puppetlabs/relay-pls/pkg/opt"
 	"github.com/puppetlabs/relay-pls/pkg/plspb"
 	"github.com/puppetlabs/relay-pls/pkg/server"
 	"github.com/puppetlabs/relay-pls/pkg/telemetry"
 	"github.com/puppetlabs/relay-pls/pkg/vault"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
 )
 
-func NewBigQueryServer(ctx context.Context, cfg *opt.Config) (plspb.LogServer, func(), error) {
+func NewPrometheusExporter(ctx context.Context, cfg *opt.Config) (*prometheus.Exporter, func(), error) {
+	panic(wire.Build(
+		telemetry.ExporterSet,
+	))
+}
+
+func NewMeter(ctx context.Context, cfg *opt.Config, exporter *prometheus.Exporter) (*metric.Meter, func(), error) {
+	panic(wire.Build(
+		telemetry.MeterSet,
+	))
+}
+
+func NewBigQueryServer(ctx context.Context, cfg *opt.Config, meter *metric.Meter) (plspb.LogServer, func(), error) {
 	panic(wire.Build(
-		telemetry.ProviderSet,
 		vault.ProviderSet,
 		manager.KeyManagerProviderSet,
 		manager.VaultProviderSet,
@@ -25,6 +41,15 @@ func NewBigQueryServer(ctx context.Context, cfg *opt.Config) (plspb.LogServer, f
 	))
 }
 
+func NewBigQueryMessagePurger(ctx context.Context, cfg *opt.Config, meter *metric.Meter) (*server.MessagePurger, func(), error) {
+	panic(wire.Build(
+		vault.ProviderSet,
+		manager.VaultProviderSet,
+		manager.VaultLeaseProviderSet,
+		server.BigQueryMessagePurgerSet,
+	))
+}
+
 func NewInMemoryServer(ctx context.Context, cfg *opt.Config) (plspb.LogServer, func(), error) {
 	panic(wire.Build(
 		vault.ProviderSet,
@@ -34,9 +59,78 @@ func NewInMemoryServer(ctx context.Context, cfg *opt.Config) (plspb.LogServer, f
 	))
 }
 
-func NewTelemetryServer(ctx context.Context, cfg *opt.Config) (*telemetry.TelemetryServer, func(), error) {
+func NewVaultCredentialManager(ctx context.Context, cfg *opt.Config) (model.CredentialManager, func(), error) {
+	panic(wire.Build(
+		vault.ProviderSet,
+		manager.VaultCredentialProviderSet,
+	))
+}
+
+func NewInMemoryCredentialManager(ctx context.Context, cfg *opt.Config) (model.CredentialManager, func(), error) {
+	panic(wire.Build(
+		manager.InMemoryCredentialProviderSet,
+	))
+}
+
+func NewVaultSignedCredentialManager(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*manager.SignedCredentialManager, func(), error) {
+	panic(wire.Build(
+		vault.ProviderSet,
+		manager.VaultSignedCredentialProviderSet,
+	))
+}
+
+func NewInMemorySignedCredentialManager(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*manager.SignedCredentialManager, func(), error) {
+	panic(wire.Build(
+		manager.InMemorySignedCredentialProviderSet,
+	))
+}
+
+func NewVaultRootCredentialManager(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*manager.RootCredentialManager, func(), error) {
+	panic(wire.Build(
+		vault.ProviderSet,
+		manager.VaultRootCredentialProviderSet,
+	))
+}
+
+func NewInMemoryRootCredentialManager(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*manager.RootCredentialManager, func(), error) {
+	panic(wire.Build(
+		manager.InMemoryRootCredentialProviderSet,
+	))
+}
+
+func NewVaultCredentialSweeper(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager, meter *metric.Meter) (*manager.CredentialSweeper, func(), error) {
+	panic(wire.Build(
+		vault.ProviderSet,
+		manager.VaultCredentialSweeperProviderSet,
+	))
+}
+
+func NewInMemoryCredentialSweeper(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager, meter *metric.Meter) (*manager.CredentialSweeper, func(), error) {
+	panic(wire.Build(
+		manager.InMemoryCredentialSweeperProviderSet,
+	))
+}
+
+func NewCredentialServer(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (plspb.CredentialServer, func(), error) {
+	panic(wire.Build(
+		server.CredentialServerSet,
+	))
+}
+
+func NewAuthInterceptor(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*auth.Interceptor, func(), error) {
+	panic(wire.Build(
+		auth.ProviderSet,
+	))
+}
+
+func NewAuditor(ctx context.Context, cfg *opt.Config, logServer plspb.LogServer, meter *metric.Meter) (*audit.Auditor, func(), error) {
+	panic(wire.Build(
+		audit.ProviderSet,
+	))
+}
+
+func NewTelemetryServer(ctx context.Context, cfg *opt.Config, exporter *prometheus.Exporter) (*telemetry.TelemetryServer, func(), error) {
 	panic(wire.Build(
-		telemetry.ProviderSet,
 		telemetry.ServerSet,
 	))
 }
@@ -8,17 +8,38 @@ package main
 
 import (
 	"context"
+	"github.com/puppetlabs/relay-pls/pkg/audit"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
 	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
 	"github.com/puppetlabs/relay-pls/pkg/opt"
 	"github.com/puppetlabs/relay-pls/pkg/plspb"
 	"github.com/puppetlabs/relay-pls/pkg/server"
 	"github.com/puppetlabs/relay-pls/pkg/telemetry"
 	"github.com/puppetlabs/relay-pls/pkg/vault"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
 )
 
 // Injectors from wire.go:
 
-func NewBigQueryServer(ctx context.Context, cfg *opt.Config) (plspb.LogServer, func(), error) {
+func NewPrometheusExporter(ctx context.Context, cfg *opt.Config) (*prometheus.Exporter, func(), error) {
+	config := telemetry.ProvidePrometheusConfig()
+	exporter, err := telemetry.ProvidePrometheusExporter(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return exporter, func() {
+	}, nil
+}
+
+func NewMeter(ctx context.Context, cfg *opt.Config, exporter *prometheus.Exporter) (*metric.Meter, func(), error) {
+	meter := telemetry.ProvideMeter(exporter)
+	return meter, func() {
+	}, nil
+}
+
+func NewBigQueryServer(ctx context.Context, cfg *opt.Config, meter *metric.Meter) (plspb.LogServer, func(), error) {
 	keyManager := manager.NewKeyManager()
 	client, err := vault.NewClient(ctx, cfg)
 	if err != nil {
@@ -28,6 +49,12 @@ func NewBigQueryServer(ctx context.Context, cfg *opt.Config) (plspb.LogServer, f
 	if err != nil {
 		return nil, nil, err
 	}
+	mediaTypeRegistry, err := server.NewMediaTypeRegistry(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	rateLimiter := server.NewRateLimiter(cfg)
+	idempotencyCache := server.NewIdempotencyCache(cfg)
 	bigqueryClient, err := server.NewBigQueryClient(ctx, cfg)
 	if err != nil {
 		return nil, nil, err
@@ -36,14 +63,38 @@ func NewBigQueryServer(ctx context.Context, cfg *opt.Config) (plspb.LogServer, f
 	if err != nil {
 		return nil, nil, err
 	}
-	config := telemetry.ProvidePrometheusConfig()
-	exporter, err := telemetry.ProvidePrometheusExporter(config)
+	logServer := server.NewBigQueryServer(cfg, keyManager, logMetadataManager, mediaTypeRegistry, rateLimiter, idempotencyCache, bigqueryClient, table, meter)
+	return logServer, func() {
+	}, nil
+}
+
+func NewBigQueryMessagePurger(ctx context.Context, cfg *opt.Config, meter *metric.Meter) (*server.MessagePurger, func(), error) {
+	client, err := vault.NewClient(ctx, cfg)
 	if err != nil {
 		return nil, nil, err
 	}
-	meter := telemetry.ProvideMeter(exporter)
-	logServer := server.NewBigQueryServer(cfg, keyManager, logMetadataManager, bigqueryClient, table, meter)
-	return logServer, func() {
+	logMetadataManager, err := manager.NewVaultLogMetadataManager(cfg, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	leaseManager, err := manager.NewVaultLeaseManager(cfg, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	bigqueryClient, err := server.NewBigQueryClient(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	table, err := server.NewBigQueryTable(ctx, cfg, bigqueryClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	messagePurger, cleanup, err := server.NewMessagePurger(ctx, cfg, logMetadataManager, leaseManager, bigqueryClient, table, meter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return messagePurger, func() {
+		cleanup()
 	}, nil
 }
 
@@ -57,17 +108,156 @@ func NewInMemoryServer(ctx context.Context, cfg *opt.Config) (plspb.LogServer, f
 	if err != nil {
 		return nil, nil, err
 	}
-	logServer := server.NewInMemoryServer(cfg, keyManager, logMetadataManager)
+	mediaTypeRegistry, err := server.NewMediaTypeRegistry(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	rateLimiter := server.NewRateLimiter(cfg)
+	idempotencyCache := server.NewIdempotencyCache(cfg)
+	logServer := server.NewInMemoryServer(cfg, keyManager, logMetadataManager, mediaTypeRegistry, rateLimiter, idempotencyCache)
 	return logServer, func() {
 	}, nil
 }
 
-func NewTelemetryServer(ctx context.Context, cfg *opt.Config) (*telemetry.TelemetryServer, func(), error) {
-	config := telemetry.ProvidePrometheusConfig()
-	exporter, err := telemetry.ProvidePrometheusExporter(config)
+func NewVaultCredentialManager(ctx context.Context, cfg *opt.Config) (model.CredentialManager, func(), error) {
+	client, err := vault.NewClient(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	credentialManager, err := manager.NewVaultCredentialManager(cfg, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return credentialManager, func() {
+	}, nil
+}
+
+func NewInMemoryCredentialManager(ctx context.Context, cfg *opt.Config) (model.CredentialManager, func(), error) {
+	credentialManager := manager.NewInMemoryCredentialManager()
+	return credentialManager, func() {
+	}, nil
+}
+
+func NewVaultSignedCredentialManager(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*manager.SignedCredentialManager, func(), error) {
+	client, err := vault.NewClient(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	revocationManager, err := manager.NewVaultRevocationManager(cfg, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	revocationList, cleanup, err := manager.NewRevocationList(ctx, cfg, revocationManager)
+	if err != nil {
+		return nil, nil, err
+	}
+	handle, err := manager.NewVaultSigningKeyset(ctx, cfg, client)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	signedCredentialManager, err := manager.NewSignedCredentialManager(cfg, credentialManager, revocationList, handle)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return signedCredentialManager, func() {
+		cleanup()
+	}, nil
+}
+
+func NewInMemorySignedCredentialManager(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*manager.SignedCredentialManager, func(), error) {
+	revocationManager := manager.NewInMemoryRevocationManager()
+	revocationList, cleanup, err := manager.NewRevocationList(ctx, cfg, revocationManager)
+	if err != nil {
+		return nil, nil, err
+	}
+	handle, err := manager.NewInMemorySigningKeyset()
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	signedCredentialManager, err := manager.NewSignedCredentialManager(cfg, credentialManager, revocationList, handle)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return signedCredentialManager, func() {
+		cleanup()
+	}, nil
+}
+
+func NewVaultRootCredentialManager(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*manager.RootCredentialManager, func(), error) {
+	client, err := vault.NewClient(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	rootCredentialManager, err := manager.NewVaultRootCredentialManager(cfg, client, credentialManager)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rootCredentialManager, func() {
+	}, nil
+}
+
+func NewInMemoryRootCredentialManager(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*manager.RootCredentialManager, func(), error) {
+	rootCredentialManager := manager.NewInMemoryRootCredentialManager(cfg, credentialManager)
+	return rootCredentialManager, func() {
+	}, nil
+}
+
+func NewVaultCredentialSweeper(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager, meter *metric.Meter) (*manager.CredentialSweeper, func(), error) {
+	client, err := vault.NewClient(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	leaseManager, err := manager.NewVaultLeaseManager(cfg, client)
 	if err != nil {
 		return nil, nil, err
 	}
+	credentialSweeper, cleanup, err := manager.NewCredentialSweeper(ctx, cfg, credentialManager, leaseManager, meter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return credentialSweeper, func() {
+		cleanup()
+	}, nil
+}
+
+func NewInMemoryCredentialSweeper(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager, meter *metric.Meter) (*manager.CredentialSweeper, func(), error) {
+	leaseManager := manager.NewInMemoryLeaseManager()
+	credentialSweeper, cleanup, err := manager.NewCredentialSweeper(ctx, cfg, credentialManager, leaseManager, meter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return credentialSweeper, func() {
+		cleanup()
+	}, nil
+}
+
+func NewCredentialServer(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (plspb.CredentialServer, func(), error) {
+	credentialServer := server.NewCredentialServer(cfg, credentialManager)
+	return credentialServer, func() {
+	}, nil
+}
+
+func NewAuthInterceptor(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager) (*auth.Interceptor, func(), error) {
+	interceptor := auth.NewInterceptor(credentialManager)
+	return interceptor, func() {
+	}, nil
+}
+
+func NewAuditor(ctx context.Context, cfg *opt.Config, logServer plspb.LogServer, meter *metric.Meter) (*audit.Auditor, func(), error) {
+	auditor, cleanup, err := audit.NewAuditor(cfg, logServer, meter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return auditor, func() {
+		cleanup()
+	}, nil
+}
+
+func NewTelemetryServer(ctx context.Context, cfg *opt.Config, exporter *prometheus.Exporter) (*telemetry.TelemetryServer, func(), error) {
 	telemetryServer := telemetry.NewTelemetryServer(exporter, cfg)
 	return telemetryServer, func() {
 	}, nil
@@ -0,0 +1,306 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/google/wire"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var ProviderSet = wire.NewSet(
+	NewAuditor,
+)
+
+var (
+	ErrClosed               = errors.New("audit: auditor is closed")
+	ErrDropped              = errors.New("audit: event dropped because the queue is full")
+	ErrKeyRequired          = errors.New("audit: a key is required to record audit events")
+	ErrSystemLogUnsupported = errors.New("audit: the log server cannot write system logs")
+)
+
+const (
+	// queueSize is the most recorded events waiting to be written before
+	// Record blocks.
+	queueSize = 1024
+
+	// enqueueTimeout bounds how long Record waits for room in a full queue
+	// before dropping the event.
+	enqueueTimeout = 5 * time.Second
+
+	// writeBatchSize is the most events written to the sinks at once.
+	writeBatchSize = 100
+
+	// writeTimeout bounds each write to the sinks.
+	writeTimeout = 30 * time.Second
+)
+
+// Event is a single entry in the audit trail.
+//
+// Events are chained: each carries the hash of the event recorded before it
+// in the same chain, and its own hash, an HMAC keyed with the audit key,
+// covers that link. An auditor continues the chain at the end of its audit
+// file across restarts, or otherwise starts a new one, so each replica keeps
+// its own chain. Removing or altering an entry breaks its chain from that
+// point on, and the chain cannot be rebuilt without the key. Removing entries
+// from the end of a chain is only detected against a later copy of it, such
+// as the system log.
+type Event struct {
+	Chain              string    `json:"chain"`
+	Time               time.Time `json:"time"`
+	CredentialID       string    `json:"credential_id,omitempty"`
+	Method             string    `json:"method"`
+	LogID              string    `json:"log_id,omitempty"`
+	Contexts           []string  `json:"contexts,omitempty"`
+	TargetCredentialID string    `json:"target_credential_id,omitempty"`
+	Outcome            string    `json:"outcome"`
+	PreviousHash       string    `json:"previous_hash,omitempty"`
+	Hash               string    `json:"hash"`
+}
+
+// Sink stores audit events, in the order they were recorded.
+type Sink interface {
+	Write(ctx context.Context, events []*Event) error
+}
+
+// Auditor records events to each of its sinks. Events are chained as they
+// are recorded and written to the sinks in the background, so that a slow
+// sink does not hold up requests.
+type Auditor struct {
+	key   []byte
+	sinks []Sink
+	queue chan *Event
+	done  chan struct{}
+	meter *metric.Meter
+
+	mu       sync.Mutex
+	chain    string
+	lastHash string
+	closed   bool
+}
+
+// Record chains the event to the previously recorded one and queues it to be
+// written to every sink. It only blocks if the queue is full, and then for at
+// most a bounded time, after which the event is dropped and counted. The
+// context of the call being audited is not waited on, as calls that end
+// because their client went away or their deadline passed must still be
+// recorded.
+func (a *Auditor) Record(ctx context.Context, event *Event) error {
+	if len(a.sinks) == 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+
+	event.Chain = a.chain
+	event.PreviousHash = a.lastHash
+
+	if err := sign(a.key, event); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(enqueueTimeout)
+	defer timer.Stop()
+
+	// A dropped event is left out of the chain, so the chain stays intact.
+	select {
+	case a.queue <- event:
+	case <-timer.C:
+		a.countMetric(model.MetricAuditEventDropped, 1)
+		log.Printf("dropped audit event for %s: the queue is full", event.Method)
+		return ErrDropped
+	}
+
+	a.lastHash = event.Hash
+
+	return nil
+}
+
+func (a *Auditor) countMetric(name string, n int64) {
+	if a.meter == nil {
+		return
+	}
+
+	counter := metric.Must(*a.meter).NewInt64Counter(name)
+	counter.Add(context.Background(), n, attribute.String(model.MetricLabelModule, "auditor"))
+}
+
+// Close stops recording events and waits for those already recorded to be
+// written.
+func (a *Auditor) Close() {
+	if len(a.sinks) == 0 {
+		return
+	}
+
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	<-a.done
+}
+
+func (a *Auditor) run() {
+	defer close(a.done)
+
+	for event := range a.queue {
+		events := []*Event{event}
+
+	batch:
+		for len(events) < writeBatchSize {
+			select {
+			case event, ok := <-a.queue:
+				if !ok {
+					break batch
+				}
+
+				events = append(events, event)
+			default:
+				break batch
+			}
+		}
+
+		a.write(events)
+	}
+}
+
+// write writes events to every sink. A failing sink does not prevent the
+// events from being written to the others.
+func (a *Auditor) write(events []*Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	for _, sink := range a.sinks {
+		if err := sink.Write(ctx, events); err != nil {
+			log.Printf("failed to write %d audit events: %v", len(events), err)
+		}
+	}
+}
+
+// sign sets the hash of an event.
+func sign(key []byte, event *Event) error {
+	e := *event
+	e.Hash = ""
+
+	b, err := json.Marshal(&e)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(b)
+	event.Hash = hex.EncodeToString(mac.Sum(nil))
+
+	return nil
+}
+
+// Verify checks that events, as read back from a sink, were recorded with
+// the given key and that every chain among them is unbroken. The events of
+// each chain must be in the order they were recorded, but may be interleaved
+// with those of other chains.
+func Verify(key []byte, events []*Event) bool {
+	last := make(map[string]string)
+	for _, event := range events {
+		if previous, found := last[event.Chain]; found && event.PreviousHash != previous {
+			return false
+		}
+
+		expected := *event
+		if err := sign(key, &expected); err != nil || !hmac.Equal([]byte(expected.Hash), []byte(event.Hash)) {
+			return false
+		}
+
+		last[event.Chain] = event.Hash
+	}
+
+	return true
+}
+
+// NewAuditor creates an auditor for the configured sinks. To write to the
+// system log, the log server must implement model.SystemLogWriter.
+func NewAuditor(cfg *opt.Config, logServer plspb.LogServer, meter *metric.Meter) (*Auditor, func(), error) {
+	if cfg.AuditFile == "" && !cfg.AuditSystemLog {
+		return &Auditor{}, func() {}, nil
+	}
+
+	if cfg.AuditKey == "" {
+		return nil, nil, ErrKeyRequired
+	}
+
+	a := &Auditor{
+		key:   []byte(cfg.AuditKey),
+		queue: make(chan *Event, queueSize),
+		done:  make(chan struct{}),
+		meter: meter,
+		chain: uuid.New().String(),
+	}
+
+	var fs *FileSink
+	if cfg.AuditFile != "" {
+		var err error
+		if fs, err = NewFileSink(cfg.AuditFile); err != nil {
+			return nil, nil, err
+		}
+
+		last, err := fs.Last()
+		if err != nil {
+			_ = fs.Close()
+			return nil, nil, err
+		}
+
+		switch {
+		case last == nil:
+		case Verify(a.key, []*Event{last}):
+			a.chain = last.Chain
+			a.lastHash = last.Hash
+		default:
+			log.Printf("audit file %s does not end with an event recorded with the audit key; starting a new chain", cfg.AuditFile)
+		}
+
+		a.sinks = append(a.sinks, fs)
+	}
+
+	if cfg.AuditSystemLog {
+		writer, ok := logServer.(model.SystemLogWriter)
+		if !ok {
+			if fs != nil {
+				_ = fs.Close()
+			}
+
+			return nil, nil, ErrSystemLogUnsupported
+		}
+
+		a.sinks = append(a.sinks, NewLogSink(writer))
+	}
+
+	go a.run()
+
+	cleanup := func() {
+		a.Close()
+
+		if fs != nil {
+			_ = fs.Close()
+		}
+	}
+
+	return a, cleanup, nil
+}
@@ -0,0 +1,206 @@
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/puppetlabs/relay-pls/pkg/audit"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockLogServer struct {
+	plspb.UnimplementedLogServer
+
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+func (s *mockLogServer) WriteSystemLog(ctx context.Context, name string, messages []*model.SystemLogMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, message := range messages {
+		s.messages = append(s.messages, message.Payload)
+	}
+
+	return nil
+}
+
+func readEvents(t *testing.T, name string) []*audit.Event {
+	f, err := os.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var events []*audit.Event
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		event := &audit.Event{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), event))
+
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestAuditor(t *testing.T) {
+	cfg := &opt.Config{
+		AuditFile:      filepath.Join(t.TempDir(), "audit.jsonl"),
+		AuditSystemLog: true,
+		AuditKey:       "secret",
+	}
+	key := []byte(cfg.AuditKey)
+
+	ls := &mockLogServer{}
+
+	a, cleanup, err := audit.NewAuditor(cfg, ls, nil)
+	require.NoError(t, err)
+
+	ctx := auth.WithCredential(context.Background(), &model.Credential{ID: "actor"})
+
+	interceptor := a.UnaryServerInterceptor()
+
+	_, err = interceptor(ctx, &plspb.LogCreateRequest{Context: "tenant-a", Name: "stdout"},
+		&grpc.UnaryServerInfo{FullMethod: "/plspb.Log/Create"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &plspb.LogCreateResponse{LogId: "log-1"}, nil
+		})
+	require.NoError(t, err)
+
+	_, err = interceptor(ctx, &plspb.LogMessageAppendRequest{LogId: "log-2"},
+		&grpc.UnaryServerInfo{FullMethod: "/plspb.Log/MessageAppend"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, auth.ErrPermissionDenied
+		})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	_, err = interceptor(ctx, &plspb.CredentialRevokeRequest{CredentialId: "child"},
+		&grpc.UnaryServerInfo{FullMethod: "/plspb.Credential/Revoke"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "not found")
+		})
+	require.Error(t, err)
+
+	// Requests rejected before the auditing interceptor, as by failed
+	// authentication, are recorded once by the rejection interceptor.
+	rejection := a.UnaryRejectionInterceptor()
+
+	_, err = rejection(context.Background(), &plspb.LogDeleteRequest{LogId: "log-3"},
+		&grpc.UnaryServerInfo{FullMethod: "/plspb.Log/Delete"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, auth.ErrUnauthenticated
+		})
+	assert.Equal(t, auth.ErrUnauthenticated, err)
+
+	_, err = rejection(ctx, &plspb.LogDeleteRequest{LogId: "log-4"},
+		&grpc.UnaryServerInfo{FullMethod: "/plspb.Log/Delete"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/plspb.Log/Delete"},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					return &plspb.LogDeleteResponse{}, nil
+				})
+		})
+	require.NoError(t, err)
+
+	// Events are written in the background until the auditor is closed.
+	cleanup()
+
+	events := readEvents(t, cfg.AuditFile)
+	require.Len(t, events, 5)
+
+	assert.Equal(t, "actor", events[0].CredentialID)
+	assert.Equal(t, "/plspb.Log/Create", events[0].Method)
+	assert.Equal(t, "log-1", events[0].LogID)
+	assert.Equal(t, []string{"tenant-a"}, events[0].Contexts)
+	assert.Equal(t, codes.OK.String(), events[0].Outcome)
+
+	assert.Equal(t, "log-2", events[1].LogID)
+	assert.Equal(t, codes.PermissionDenied.String(), events[1].Outcome)
+
+	assert.Equal(t, "child", events[2].TargetCredentialID)
+	assert.Equal(t, codes.NotFound.String(), events[2].Outcome)
+
+	assert.Empty(t, events[3].CredentialID)
+	assert.Equal(t, "log-3", events[3].LogID)
+	assert.Equal(t, codes.Unauthenticated.String(), events[3].Outcome)
+
+	assert.Equal(t, "actor", events[4].CredentialID)
+	assert.Equal(t, "log-4", events[4].LogID)
+
+	assert.True(t, audit.Verify(key, events))
+	assert.False(t, audit.Verify([]byte("other"), events))
+
+	// The same events are in the system log.
+	require.Len(t, ls.messages, 5)
+	for i, message := range ls.messages {
+		event := &audit.Event{}
+		require.NoError(t, json.Unmarshal(message, event))
+		assert.Equal(t, events[i].Hash, event.Hash)
+	}
+
+	// Tampering with an event breaks the chain.
+	tampered := *events[1]
+	tampered.Outcome = codes.OK.String()
+	assert.False(t, audit.Verify(key, []*audit.Event{events[0], &tampered, events[2]}))
+
+	assert.False(t, audit.Verify(key, []*audit.Event{events[0], events[2]}))
+
+	// A restarted auditor continues the chain at the end of the file.
+	a, cleanup, err = audit.NewAuditor(cfg, ls, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Record(ctx, &audit.Event{Method: "/plspb.Log/Get", Outcome: codes.OK.String()}))
+	cleanup()
+
+	events = readEvents(t, cfg.AuditFile)
+	require.Len(t, events, 6)
+	assert.Equal(t, events[4].Chain, events[5].Chain)
+	assert.True(t, audit.Verify(key, events))
+
+	// Without a key, no chain can be kept.
+	_, _, err = audit.NewAuditor(&opt.Config{AuditSystemLog: true}, ls, nil)
+	assert.Equal(t, audit.ErrKeyRequired, err)
+}
+
+func TestAuditorCanceledCall(t *testing.T) {
+	cfg := &opt.Config{
+		AuditFile: filepath.Join(t.TempDir(), "audit.jsonl"),
+		AuditKey:  "secret",
+	}
+
+	a, cleanup, err := audit.NewAuditor(cfg, &mockLogServer{}, nil)
+	require.NoError(t, err)
+
+	// Calls that end because their client went away, as follow streams do,
+	// are still recorded.
+	ctx, cancel := context.WithCancel(auth.WithCredential(context.Background(), &model.Credential{ID: "actor"}))
+	cancel()
+
+	_, err = a.UnaryServerInterceptor()(ctx, &plspb.LogMessageListRequest{LogId: "log-1", Follow: true},
+		&grpc.UnaryServerInfo{FullMethod: "/plspb.Log/MessageList"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Canceled, ctx.Err().Error())
+		})
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	require.NoError(t, a.Record(ctx, &audit.Event{Method: "/plspb.Log/Get", Outcome: codes.Canceled.String()}))
+	cleanup()
+
+	events := readEvents(t, cfg.AuditFile)
+	require.Len(t, events, 2)
+	assert.Equal(t, "log-1", events[0].LogID)
+	assert.Equal(t, codes.Canceled.String(), events[0].Outcome)
+	assert.True(t, audit.Verify([]byte(cfg.AuditKey), events))
+}
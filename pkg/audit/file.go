@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// fileTailSize is how much of the end of an audit file is read to find its
+// last event.
+const fileTailSize = 64 * 1024
+
+// FileSink appends events to a file as JSON lines.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (fs *FileSink) Write(ctx context.Context, events []*Event) error {
+	var buf bytes.Buffer
+	for _, event := range events {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	_, err := fs.file.Write(buf.Bytes())
+	return err
+}
+
+// Last returns the last event in the file, or nil if there is none or the
+// last line is not an event.
+func (fs *FileSink) Last() (*Event, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fi, err := fs.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := fi.Size() - fileTailSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	b := make([]byte, fi.Size()-offset)
+	if _, err := fs.file.ReadAt(b, offset); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	b = bytes.TrimRight(b, "\n")
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		b = b[i+1:]
+	} else if offset > 0 {
+		return nil, nil
+	}
+
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	event := &Event{}
+	if err := json.Unmarshal(b, event); err != nil {
+		return nil, nil
+	}
+
+	return event, nil
+}
+
+func (fs *FileSink) Close() error {
+	return fs.file.Close()
+}
+
+func NewFileSink(name string) (*FileSink, error) {
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{
+		file: f,
+	}, nil
+}
@@ -0,0 +1,182 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+type auditedContextKey struct{}
+
+// markAudited notes that an RPC reached the interceptors that audit it, so
+// that it is not recorded again as rejected.
+func markAudited(ctx context.Context) {
+	if audited, ok := ctx.Value(auditedContextKey{}).(*int32); ok {
+		atomic.StoreInt32(audited, 1)
+	}
+}
+
+// UnaryRejectionInterceptor records an event for every unary RPC rejected
+// before it reaches UnaryServerInterceptor, such as one that fails
+// authentication. It must run before authentication.
+func (a *Auditor) UnaryRejectionInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		audited := new(int32)
+
+		resp, err := handler(context.WithValue(ctx, auditedContextKey{}, audited), req)
+
+		if atomic.LoadInt32(audited) == 0 {
+			_ = a.Record(ctx, newEvent(ctx, info.FullMethod, req, nil, err))
+		}
+
+		return resp, err
+	}
+}
+
+// StreamRejectionInterceptor records an event for every streaming RPC
+// rejected before it reaches StreamServerInterceptor. It must run before
+// authentication.
+func (a *Auditor) StreamRejectionInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		audited := new(int32)
+
+		err := handler(srv, &contextServerStream{
+			ServerStream: ss,
+			ctx:          context.WithValue(ss.Context(), auditedContextKey{}, audited),
+		})
+
+		if atomic.LoadInt32(audited) == 0 {
+			_ = a.Record(ss.Context(), newEvent(ss.Context(), info.FullMethod, nil, nil, err))
+		}
+
+		return err
+	}
+}
+
+// UnaryServerInterceptor records an event for every unary RPC. It must run
+// after authentication so that the actor is known.
+func (a *Auditor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		markAudited(ctx)
+
+		resp, err := handler(ctx, req)
+
+		_ = a.Record(ctx, newEvent(ctx, info.FullMethod, req, resp, err))
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor records an event for every streaming RPC once the
+// stream ends. The target is taken from the first message received.
+func (a *Auditor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		markAudited(ss.Context())
+
+		as := &serverStream{ServerStream: ss}
+
+		err := handler(srv, as)
+
+		_ = a.Record(ss.Context(), newEvent(ss.Context(), info.FullMethod, as.request(), nil, err))
+
+		return err
+	}
+}
+
+type logIDGetter interface {
+	GetLogId() string
+}
+
+type contextGetter interface {
+	GetContext() string
+}
+
+type contextsGetter interface {
+	GetContexts() []string
+}
+
+type credentialIDGetter interface {
+	GetCredentialId() string
+}
+
+func newEvent(ctx context.Context, method string, req, resp interface{}, err error) *Event {
+	event := &Event{
+		Time:    time.Now().UTC(),
+		Method:  method,
+		Outcome: status.Code(err).String(),
+	}
+
+	if credential, ok := auth.CredentialFromContext(ctx); ok {
+		event.CredentialID = credential.ID
+	}
+
+	// The response takes precedence, as it identifies the actual target of,
+	// for example, a create or issue request.
+	for _, m := range []interface{}{req, resp} {
+		if m == nil {
+			continue
+		}
+
+		if g, ok := m.(logIDGetter); ok && g.GetLogId() != "" {
+			event.LogID = g.GetLogId()
+		}
+
+		if g, ok := m.(contextGetter); ok && g.GetContext() != "" {
+			event.Contexts = []string{g.GetContext()}
+		}
+
+		if g, ok := m.(contextsGetter); ok && len(g.GetContexts()) > 0 {
+			event.Contexts = g.GetContexts()
+		}
+
+		if g, ok := m.(credentialIDGetter); ok && g.GetCredentialId() != "" {
+			event.TargetCredentialID = g.GetCredentialId()
+		}
+	}
+
+	return event
+}
+
+type contextServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (ss *contextServerStream) Context() context.Context {
+	return ss.ctx
+}
+
+type serverStream struct {
+	grpc.ServerStream
+
+	mu  sync.Mutex
+	req interface{}
+}
+
+func (ss *serverStream) RecvMsg(m interface{}) error {
+	if err := ss.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.req == nil {
+		ss.req = m
+	}
+
+	return nil
+}
+
+func (ss *serverStream) request() interface{} {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	return ss.req
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/puppetlabs/relay-pls/pkg/model"
+)
+
+const (
+	// SystemLogName is the name of the log, in the system context, that
+	// audit events are written to. It can be read, but not written to, by
+	// credentials granted the system context explicitly, as with a context
+	// of @system.
+	SystemLogName = "audit"
+
+	mediaTypeJSON = "application/json"
+)
+
+// LogSink writes events as messages to a system log kept by the service
+// itself.
+type LogSink struct {
+	writer model.SystemLogWriter
+}
+
+func (ls *LogSink) Write(ctx context.Context, events []*Event) error {
+	messages := make([]*model.SystemLogMessage, len(events))
+	for i, event := range events {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		messages[i] = &model.SystemLogMessage{
+			Timestamp: event.Time,
+			MediaType: mediaTypeJSON,
+			Payload:   b,
+		}
+	}
+
+	return ls.writer.WriteSystemLog(ctx, SystemLogName, messages)
+}
+
+func NewLogSink(writer model.SystemLogWriter) *LogSink {
+	return &LogSink{
+		writer: writer,
+	}
+}
@@ -0,0 +1,336 @@
+package auth
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/puppetlabs/relay-pls/pkg/model"
+)
+
+type credentialContextKey struct{}
+
+// WithCredential returns a new context carrying the authenticated credential.
+func WithCredential(ctx context.Context, credential *model.Credential) context.Context {
+	return context.WithValue(ctx, credentialContextKey{}, credential)
+}
+
+// CredentialFromContext returns the authenticated credential for a request, if
+// any.
+func CredentialFromContext(ctx context.Context) (*model.Credential, bool) {
+	credential, ok := ctx.Value(credentialContextKey{}).(*model.Credential)
+	return credential, ok && credential != nil
+}
+
+// contextSeparator separates the levels of a hierarchical log context, as in
+// tenant/workflow/run.
+const contextSeparator = "/"
+
+// ValidContext determines whether a log context is well-formed. Each level of
+// the context must be non-empty and may not be "." or "..", which would
+// otherwise let a context read as one of its ancestors or siblings once it
+// is made into a storage path. A log context may not contain a wildcard.
+func ValidContext(logContext string) bool {
+	return !strings.Contains(logContext, model.WildcardContext) && validLevels(logContext)
+}
+
+// ValidPattern determines whether a context pattern is well-formed. A pattern
+// is either an exact log context, the wildcard context, or a prefix followed
+// by a separator and the wildcard, as in tenant-a/*, which matches every log
+// context beneath the prefix.
+func ValidPattern(pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+
+	if pattern == model.WildcardContext {
+		return true
+	}
+
+	return ValidContext(strings.TrimSuffix(pattern, contextSeparator+model.WildcardContext))
+}
+
+func validLevels(logContext string) bool {
+	for _, level := range strings.Split(logContext, contextSeparator) {
+		if level == "" || level == "." || level == ".." {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchContext determines whether a context pattern matches a log context. No
+// pattern matches a log context that is not valid.
+func MatchContext(pattern, logContext string) bool {
+	if !ValidContext(logContext) {
+		return false
+	}
+
+	if pattern == model.WildcardContext {
+		return true
+	}
+
+	if prefix := strings.TrimSuffix(pattern, model.WildcardContext); prefix != pattern {
+		return strings.HasPrefix(logContext, prefix) && len(logContext) > len(prefix)
+	}
+
+	return pattern == logContext
+}
+
+// Covers determines whether every log context matched by one pattern is
+// also matched by another.
+func Covers(pattern, other string) bool {
+	if prefix := strings.TrimSuffix(other, model.WildcardContext); prefix != other {
+		// A prefix pattern is only covered by the same or a shorter prefix.
+		return pattern == model.WildcardContext ||
+			strings.HasSuffix(pattern, contextSeparator+model.WildcardContext) &&
+				strings.HasPrefix(prefix, strings.TrimSuffix(pattern, model.WildcardContext))
+	}
+
+	return MatchContext(pattern, other)
+}
+
+// Grants determines whether the given credential has access to a log context.
+// The system context is only granted by a pattern within it, such as
+// @system/*, and never by the wildcard context, so that only credentials
+// issued for the purpose can see the logs kept by the service. Access to it
+// is read-only; see Authorize.
+func Grants(credential *model.Credential, logContext string) bool {
+	system := isSystemContext(logContext)
+
+	for _, c := range credential.Contexts {
+		if system && !isSystemContext(c) {
+			continue
+		}
+
+		if MatchContext(c, logContext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// grantsScope determines whether an operation is permitted in a log context
+// at all. The logs in the system context are only ever written by the service
+// itself, so they may only be read.
+func grantsScope(scope, logContext string) bool {
+	return !isSystemContext(logContext) || scope == model.ScopeLogRead
+}
+
+// grantsPattern determines whether the given credential has access to every
+// log context matched by a pattern.
+func grantsPattern(credential *model.Credential, pattern string) bool {
+	for _, c := range credential.Contexts {
+		if Covers(c, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSystemContext determines whether a log context or pattern is the system
+// context or beneath it.
+func isSystemContext(logContext string) bool {
+	return logContext == model.SystemContext || strings.HasPrefix(logContext, model.SystemContext+contextSeparator)
+}
+
+// HasScope determines whether the given credential is permitted an operation.
+// Credentials without any scopes predate them and are permitted everything.
+func HasScope(credential *model.Credential, scope string) bool {
+	if len(credential.Scopes) == 0 {
+		return true
+	}
+
+	for _, s := range credential.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequireScope checks that the credential authenticating the request is
+// permitted an operation.
+func RequireScope(ctx context.Context, scope string) error {
+	credential, ok := CredentialFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	if !HasScope(credential, scope) {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// Authorize checks that the credential authenticating the request is
+// permitted an operation on the given log.
+func Authorize(ctx context.Context, scope string, log *model.Log) error {
+	if err := RequireScope(ctx, scope); err != nil {
+		return err
+	}
+
+	credential, _ := CredentialFromContext(ctx)
+	if log == nil || !Grants(credential, log.Context) || !grantsScope(scope, log.Context) {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// ResolveContext determines the context to operate on with the given scope,
+// such as the context to create a log in. If no context is requested and the
+// authenticated credential only has access to one context, that context is
+// used.
+func ResolveContext(ctx context.Context, scope, requested string) (string, error) {
+	credential, ok := CredentialFromContext(ctx)
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+
+	if requested == "" {
+		if len(credential.Contexts) != 1 || !ValidContext(credential.Contexts[0]) {
+			return "", ErrContextRequired
+		}
+
+		requested = credential.Contexts[0]
+	} else if !ValidContext(requested) {
+		return "", ErrInvalidContext
+	}
+
+	if !Grants(credential, requested) || !grantsScope(scope, requested) {
+		return "", ErrPermissionDenied
+	}
+
+	return requested, nil
+}
+
+// ListMatcher determines which log contexts the credential authenticating the
+// request may list, optionally restricted to the requested context patterns.
+func ListMatcher(ctx context.Context, requested []string) (func(logContext string) bool, error) {
+	credential, ok := CredentialFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	for _, r := range requested {
+		if !ValidPattern(r) {
+			return nil, ErrInvalidPattern
+		}
+	}
+
+	return func(logContext string) bool {
+		if !Grants(credential, logContext) {
+			return false
+		}
+
+		if len(requested) == 0 {
+			return true
+		}
+
+		for _, r := range requested {
+			if MatchContext(r, logContext) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+// ListPrefixes returns the log contexts at or beneath which every context
+// accepted by ListMatcher for the same request must lie, so that a listing
+// need only search beneath them. It returns nil if every context must be
+// searched.
+func ListPrefixes(ctx context.Context, requested []string) []string {
+	patterns := requested
+	if len(patterns) == 0 {
+		credential, ok := CredentialFromContext(ctx)
+		if !ok {
+			return nil
+		}
+
+		patterns = credential.Contexts
+	}
+
+	var prefixes []string
+	for _, pattern := range patterns {
+		if pattern == model.WildcardContext {
+			return nil
+		}
+
+		prefixes = append(prefixes, strings.TrimSuffix(pattern, contextSeparator+model.WildcardContext))
+	}
+
+	// Drop any prefix beneath another, so that no context is searched twice.
+	sort.Strings(prefixes)
+
+	var r []string
+	for _, prefix := range prefixes {
+		if n := len(r); n > 0 && (prefix == r[n-1] || strings.HasPrefix(prefix, r[n-1]+contextSeparator)) {
+			continue
+		}
+
+		r = append(r, prefix)
+	}
+
+	return r
+}
+
+// ConstrainChild validates the contexts, scopes and expiration of a new
+// credential against its parent. Contexts and scopes default to those of the
+// parent. Each context must be a valid pattern covered by one of the parent's,
+// and each scope must be held by the parent. The expiration is clamped to that
+// of the parent.
+func ConstrainChild(parent, child *model.Credential) (*model.Credential, error) {
+	c := *child
+
+	if len(c.Contexts) == 0 {
+		c.Contexts = append([]string(nil), parent.Contexts...)
+	}
+
+	for _, pattern := range c.Contexts {
+		if !ValidPattern(pattern) {
+			return nil, ErrInvalidPattern
+		}
+
+		if !grantsPattern(parent, pattern) {
+			return nil, ErrPermissionDenied
+		}
+	}
+
+	if len(c.Scopes) == 0 {
+		c.Scopes = append([]string(nil), parent.Scopes...)
+	}
+
+	for _, scope := range c.Scopes {
+		if !validScope(scope) {
+			return nil, ErrInvalidScope
+		}
+
+		if !HasScope(parent, scope) {
+			return nil, ErrPermissionDenied
+		}
+	}
+
+	if !parent.ExpiresAt.IsZero() && c.ExpiresAt.After(parent.ExpiresAt) {
+		c.ExpiresAt = parent.ExpiresAt
+	}
+
+	return &c, nil
+}
+
+func validScope(scope string) bool {
+	for _, s := range model.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,245 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchContext(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		logContext string
+		expected   bool
+	}{
+		{pattern: "*", logContext: "tenant-a", expected: true},
+		{pattern: "*", logContext: "tenant-a/workflow/run", expected: true},
+		{pattern: "tenant-a", logContext: "tenant-a", expected: true},
+		{pattern: "tenant-a", logContext: "tenant-a/workflow", expected: false},
+		{pattern: "tenant-a/*", logContext: "tenant-a/workflow", expected: true},
+		{pattern: "tenant-a/*", logContext: "tenant-a/workflow/run", expected: true},
+		{pattern: "tenant-a/*", logContext: "tenant-a", expected: false},
+		{pattern: "tenant-a/*", logContext: "tenant-ab/workflow", expected: false},
+		{pattern: "tenant-a/workflow/*", logContext: "tenant-a/other/run", expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.logContext, func(t *testing.T) {
+			assert.Equal(t, test.expected, auth.MatchContext(test.pattern, test.logContext))
+		})
+	}
+}
+
+func TestConstrainChild(t *testing.T) {
+	parent := &model.Credential{
+		Contexts: []string{"tenant-a/*", "tenant-b/workflow"},
+	}
+
+	for _, contexts := range [][]string{
+		{"tenant-a/*"},
+		{"tenant-a/workflow/*"},
+		{"tenant-a/workflow/run", "tenant-b/workflow"},
+	} {
+		_, err := auth.ConstrainChild(parent, &model.Credential{Contexts: contexts})
+		assert.NoError(t, err, "contexts %v", contexts)
+	}
+
+	for _, contexts := range [][]string{
+		{"*"},
+		{"tenant-a"},
+		{"tenant-b/*"},
+		{"tenant-b/workflow/run"},
+	} {
+		_, err := auth.ConstrainChild(parent, &model.Credential{Contexts: contexts})
+		assert.Equal(t, auth.ErrPermissionDenied, err, "contexts %v", contexts)
+	}
+
+	for _, contexts := range [][]string{
+		{"tenant-a/*/run"},
+		{"tenant-a*"},
+		{"/*"},
+	} {
+		_, err := auth.ConstrainChild(parent, &model.Credential{Contexts: contexts})
+		assert.Equal(t, auth.ErrInvalidPattern, err, "contexts %v", contexts)
+	}
+}
+
+func TestConstrainChildScopes(t *testing.T) {
+	parent := &model.Credential{
+		Contexts: []string{"a"},
+		Scopes:   []string{model.ScopeLogAppend, model.ScopeLogRead},
+	}
+
+	child, err := auth.ConstrainChild(parent, &model.Credential{})
+	require.NoError(t, err)
+	assert.Equal(t, parent.Scopes, child.Scopes)
+
+	child, err = auth.ConstrainChild(parent, &model.Credential{Scopes: []string{model.ScopeLogRead}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{model.ScopeLogRead}, child.Scopes)
+
+	_, err = auth.ConstrainChild(parent, &model.Credential{Scopes: []string{model.ScopeLogDelete}})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	_, err = auth.ConstrainChild(parent, &model.Credential{Scopes: []string{"log:write"}})
+	assert.Equal(t, auth.ErrInvalidScope, err)
+
+	// Credentials without scopes are permitted everything.
+	child, err = auth.ConstrainChild(&model.Credential{Contexts: []string{"a"}}, &model.Credential{Scopes: []string{model.ScopeLogDelete}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{model.ScopeLogDelete}, child.Scopes)
+}
+
+func TestListMatcher(t *testing.T) {
+	ctx := auth.WithCredential(context.Background(), &model.Credential{
+		Contexts: []string{"tenant-a/*"},
+	})
+
+	match, err := auth.ListMatcher(ctx, nil)
+	require.NoError(t, err)
+	assert.True(t, match("tenant-a/workflow/run"))
+	assert.False(t, match("tenant-b/workflow/run"))
+
+	match, err = auth.ListMatcher(ctx, []string{"tenant-a/workflow/*", "tenant-b/*"})
+	require.NoError(t, err)
+	assert.True(t, match("tenant-a/workflow/run"))
+	assert.False(t, match("tenant-a/other/run"))
+	assert.False(t, match("tenant-b/workflow/run"))
+
+	_, err = auth.ListMatcher(ctx, []string{"tenant-a/*/run"})
+	assert.Equal(t, auth.ErrInvalidPattern, err)
+
+	_, err = auth.ResolveContext(ctx, model.ScopeLogCreate, "")
+	assert.Equal(t, auth.ErrContextRequired, err)
+
+	logContext, err := auth.ResolveContext(ctx, model.ScopeLogCreate, "tenant-a/workflow/run")
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-a/workflow/run", logContext)
+}
+
+func TestListPrefixes(t *testing.T) {
+	ctx := auth.WithCredential(context.Background(), &model.Credential{
+		Contexts: []string{"tenant-b", "tenant-a/*"},
+	})
+
+	assert.Equal(t, []string{"tenant-a", "tenant-b"}, auth.ListPrefixes(ctx, nil))
+	assert.Equal(t, []string{"tenant-a/workflow", "tenant-c"}, auth.ListPrefixes(ctx, []string{"tenant-c", "tenant-a/workflow/run", "tenant-a/workflow/*"}))
+	assert.Equal(t, []string{"tenant-a", "tenant-ab"}, auth.ListPrefixes(ctx, []string{"tenant-a/workflow", "tenant-a", "tenant-ab"}))
+	assert.Nil(t, auth.ListPrefixes(ctx, []string{"tenant-a", model.WildcardContext}))
+
+	root := auth.WithCredential(context.Background(), &model.Credential{
+		Contexts: []string{model.WildcardContext},
+	})
+	assert.Nil(t, auth.ListPrefixes(root, nil))
+}
+
+func TestSystemContext(t *testing.T) {
+	root := &model.Credential{Contexts: []string{model.WildcardContext}}
+
+	assert.False(t, auth.Grants(root, model.SystemContext))
+	assert.False(t, auth.Grants(root, model.SystemContext+"/audit"))
+	assert.True(t, auth.Grants(root, model.SystemContext+"-other"))
+
+	ctx := auth.WithCredential(context.Background(), root)
+
+	_, err := auth.ResolveContext(ctx, model.ScopeLogRead, model.SystemContext)
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	err = auth.Authorize(ctx, model.ScopeLogRead, &model.Log{Context: model.SystemContext, Name: "audit"})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	match, err := auth.ListMatcher(ctx, nil)
+	require.NoError(t, err)
+	assert.False(t, match(model.SystemContext))
+
+	// The system context may be granted explicitly, but only for reading.
+	for _, pattern := range []string{model.SystemContext, model.SystemContext + "/*"} {
+		child, err := auth.ConstrainChild(root, &model.Credential{Contexts: []string{pattern}})
+		require.NoError(t, err, "pattern %s", pattern)
+
+		assert.False(t, auth.Grants(child, "tenant-a"), "pattern %s", pattern)
+	}
+
+	reader, err := auth.ConstrainChild(root, &model.Credential{
+		Contexts: []string{model.SystemContext, model.WildcardContext},
+	})
+	require.NoError(t, err)
+	assert.True(t, auth.Grants(reader, model.SystemContext))
+
+	ctx = auth.WithCredential(context.Background(), reader)
+
+	logContext, err := auth.ResolveContext(ctx, model.ScopeLogRead, model.SystemContext)
+	require.NoError(t, err)
+	assert.Equal(t, model.SystemContext, logContext)
+
+	_, err = auth.ResolveContext(ctx, model.ScopeLogCreate, model.SystemContext)
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	audit := &model.Log{Context: model.SystemContext, Name: "audit"}
+	assert.NoError(t, auth.Authorize(ctx, model.ScopeLogRead, audit))
+
+	for _, scope := range []string{model.ScopeLogAppend, model.ScopeLogCreate, model.ScopeLogDelete} {
+		assert.Equal(t, auth.ErrPermissionDenied, auth.Authorize(ctx, scope, audit), "scope %s", scope)
+	}
+
+	match, err = auth.ListMatcher(ctx, nil)
+	require.NoError(t, err)
+	assert.True(t, match(model.SystemContext))
+	assert.True(t, match("tenant-a"))
+}
+
+func TestContextTraversal(t *testing.T) {
+	tenant := &model.Credential{Contexts: []string{"tenant-a/*"}}
+	root := &model.Credential{Contexts: []string{model.WildcardContext}}
+
+	for _, logContext := range []string{
+		"tenant-a/../tenant-b",
+		"tenant-a/./workflow",
+		"tenant-a/workflow/..",
+		"tenant-a//workflow",
+		"tenant-a/workflow/",
+		"tenant-a/../" + model.SystemContext + "/audit",
+		"..",
+		".",
+	} {
+		assert.False(t, auth.ValidContext(logContext), "context %s", logContext)
+		assert.False(t, auth.Grants(tenant, logContext), "context %s", logContext)
+		assert.False(t, auth.Grants(root, logContext), "context %s", logContext)
+
+		_, err := auth.ResolveContext(auth.WithCredential(context.Background(), tenant), model.ScopeLogCreate, logContext)
+		assert.Equal(t, auth.ErrInvalidContext, err, "context %s", logContext)
+
+		_, err = auth.ResolveContext(auth.WithCredential(context.Background(), root), model.ScopeLogCreate, logContext)
+		assert.Equal(t, auth.ErrInvalidContext, err, "context %s", logContext)
+	}
+
+	for _, pattern := range []string{
+		"tenant-a/../tenant-b",
+		"tenant-a/../*",
+		"tenant-a/./*",
+		"tenant-a//*",
+		"../*",
+		"./*",
+	} {
+		assert.False(t, auth.ValidPattern(pattern), "pattern %s", pattern)
+
+		_, err := auth.ConstrainChild(root, &model.Credential{Contexts: []string{pattern}})
+		assert.Equal(t, auth.ErrInvalidPattern, err, "pattern %s", pattern)
+
+		_, err = auth.ListMatcher(auth.WithCredential(context.Background(), root), []string{pattern})
+		assert.Equal(t, auth.ErrInvalidPattern, err, "pattern %s", pattern)
+	}
+
+	// A credential for a single traversing context, as might have been
+	// issued before levels were validated, cannot create logs with it.
+	_, err := auth.ResolveContext(auth.WithCredential(context.Background(), &model.Credential{
+		Contexts: []string{"tenant-a/../tenant-b"},
+	}), model.ScopeLogCreate, "")
+	assert.Equal(t, auth.ErrContextRequired, err)
+
+	assert.True(t, auth.ValidContext("tenant-a/.hidden/..."))
+}
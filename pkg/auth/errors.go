@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	ErrContextRequired  = status.Error(codes.InvalidArgument, "auth: a context is required for this credential")
+	ErrInvalidContext   = status.Error(codes.InvalidArgument, "auth: a log context may not contain a wildcard or an empty, \".\" or \"..\" level")
+	ErrInvalidPattern   = status.Error(codes.InvalidArgument, "auth: a context pattern may only end in a wildcard and may not contain an empty, \".\" or \"..\" level")
+	ErrInvalidScope     = status.Error(codes.InvalidArgument, "auth: unknown scope")
+	ErrPermissionDenied = status.Error(codes.PermissionDenied, "auth: permission denied")
+	ErrUnauthenticated  = status.Error(codes.Unauthenticated, "auth: missing or invalid credential")
+)
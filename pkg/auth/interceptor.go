@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/wire"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	AuthorizationMetadataKey = "authorization"
+	BearerScheme             = "bearer"
+)
+
+var ProviderSet = wire.NewSet(
+	NewInterceptor,
+)
+
+type Interceptor struct {
+	credentialManager model.CredentialManager
+}
+
+func (i *Interceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func (i *Interceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (i *Interceptor) authenticate(ctx context.Context) (context.Context, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	credential, err := i.credentialManager.Authenticate(ctx, token)
+	switch err {
+	case nil:
+	case manager.ErrInvalidToken, manager.ErrCredentialExpired:
+		return nil, ErrUnauthenticated
+	default:
+		return nil, err
+	}
+
+	return WithCredential(ctx, credential), nil
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	for _, value := range md.Get(AuthorizationMetadataKey) {
+		parts := strings.SplitN(value, " ", 2)
+		if len(parts) == 2 && strings.EqualFold(parts[0], BearerScheme) && parts[1] != "" {
+			return strings.TrimSpace(parts[1]), true
+		}
+	}
+
+	return "", false
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+func NewInterceptor(credentialManager model.CredentialManager) *Interceptor {
+	return &Interceptor{
+		credentialManager: credentialManager,
+	}
+}
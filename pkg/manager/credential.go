@@ -0,0 +1,366 @@
+package manager
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/google/wire"
+	"github.com/hashicorp/vault/api"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/util/vaultutil"
+)
+
+var VaultCredentialProviderSet = wire.NewSet(
+	NewVaultCredentialManager,
+)
+
+type credentialRecord struct {
+	credential *model.Credential
+	tokenHash  string
+	version    int
+}
+
+func (r *credentialRecord) data() map[string]interface{} {
+	return map[string]interface{}{
+		"contexts":   r.credential.Contexts,
+		"expires_at": formatTime(r.credential.ExpiresAt),
+		"parent_id":  r.credential.ParentID,
+		"scopes":     r.credential.Scopes,
+		"token_hash": r.tokenHash,
+	}
+}
+
+func credentialRecordFromData(id string, data map[string]interface{}, version int) (*credentialRecord, error) {
+	expiresAt, err := timeValue(data, "expires_at")
+	if err != nil {
+		return nil, err
+	}
+
+	return &credentialRecord{
+		credential: &model.Credential{
+			ID:        id,
+			ParentID:  stringValue(data, "parent_id"),
+			Contexts:  stringsValue(data, "contexts"),
+			Scopes:    stringsValue(data, "scopes"),
+			ExpiresAt: expiresAt,
+		},
+		tokenHash: stringValue(data, "token_hash"),
+		version:   version,
+	}, nil
+}
+
+type VaultCredentialManager struct {
+	client      *api.Client
+	engineMount string
+}
+
+func (cm *VaultCredentialManager) Authenticate(ctx context.Context, token string) (*model.Credential, error) {
+	id, secret, err := parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := cm.read(ctx, id)
+	if err == ErrCredentialNotFound {
+		return nil, ErrInvalidToken
+	} else if err != nil {
+		return nil, err
+	}
+
+	if !checkTokenSecret(record.tokenHash, secret) {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+	if credentialExpired(record.credential, now) {
+		return nil, ErrCredentialExpired
+	}
+
+	// A credential is only valid as long as all of its ancestors are. This
+	// also guards against children issued concurrently with the revocation
+	// of their parent.
+	for parentID := record.credential.ParentID; parentID != ""; {
+		parent, err := cm.read(ctx, parentID)
+		if err == ErrCredentialNotFound {
+			return nil, ErrInvalidToken
+		} else if err != nil {
+			return nil, err
+		}
+
+		if credentialExpired(parent.credential, now) {
+			return nil, ErrCredentialExpired
+		}
+
+		parentID = parent.credential.ParentID
+	}
+
+	return record.credential, nil
+}
+
+func (cm *VaultCredentialManager) Children(ctx context.Context, id string) ([]string, error) {
+	if _, err := cm.read(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return listSecrets(ctx, cm.client, metadataPath(cm.engineMount, "credentials", id, "children"))
+}
+
+func (cm *VaultCredentialManager) Create(ctx context.Context, credential *model.Credential) (*model.CredentialMetadata, error) {
+	c := copyCredential(credential)
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+
+	token, hash, err := newToken(c.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ParentID != "" {
+		parent, err := cm.read(ctx, c.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if credentialExpired(parent.credential, time.Now()) {
+			return nil, ErrCredentialExpired
+		}
+
+		// The child is registered with its parent before it is written so
+		// that it can never exist without being reachable for revocation.
+		child := map[string]interface{}{
+			"created_at": formatTime(time.Now()),
+		}
+
+		if err := writeSecretData(cm.client, cm.childDataPath(c.ParentID, c.ID), child, -1); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cm.indexExpiry(c); err != nil {
+		return nil, err
+	}
+
+	record := &credentialRecord{
+		credential: c,
+		tokenHash:  hash,
+	}
+
+	if err := writeSecretData(cm.client, cm.dataPath(c.ID), record.data(), 0); err != nil {
+		if isCheckAndSetError(err) {
+			return nil, ErrCredentialExists
+		}
+
+		return nil, err
+	}
+
+	return &model.CredentialMetadata{
+		Credential: copyCredential(c),
+		Token:      token,
+	}, nil
+}
+
+func (cm *VaultCredentialManager) Get(ctx context.Context, id string) (*model.Credential, error) {
+	record, err := cm.read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return record.credential, nil
+}
+
+// ListExpired reads the expiry index rather than every credential. Only
+// buckets that have ended are read, so that no credential is read before it
+// expires; a credential may therefore be returned up to an hour after it
+// expires. Index entries of credentials that no longer exist or whose expiry
+// has since changed are removed as they are found.
+func (cm *VaultCredentialManager) ListExpired(ctx context.Context, now time.Time, limit int) ([]*model.Credential, error) {
+	buckets, err := listSecrets(ctx, cm.client, metadataPath(cm.engineMount, "credential_expiry"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Buckets are named so that they sort in time order.
+	sort.Strings(buckets)
+
+	var expired []*model.Credential
+	for _, bucket := range buckets {
+		bucket = strings.TrimSuffix(bucket, "/")
+
+		start, err := time.Parse(expiryBucketFormat, bucket)
+		if err != nil {
+			continue
+		} else if start.Add(expiryBucketWidth).After(now) {
+			break
+		}
+
+		ids, err := listSecrets(ctx, cm.client, metadataPath(cm.engineMount, "credential_expiry", bucket))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			if len(expired) >= limit {
+				return expired, nil
+			}
+
+			record, err := cm.read(ctx, id)
+			if err != nil && err != ErrCredentialNotFound {
+				return nil, err
+			}
+
+			if err == ErrCredentialNotFound || expiryBucket(record.credential) != bucket {
+				if err := deleteSecret(cm.client, metadataPath(cm.engineMount, "credential_expiry", bucket, id)); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			if credentialExpired(record.credential, now) {
+				expired = append(expired, record.credential)
+			}
+		}
+	}
+
+	return expired, nil
+}
+
+func (cm *VaultCredentialManager) Refresh(ctx context.Context, id string, expiresAt time.Time) (*model.CredentialMetadata, error) {
+	record, err := cm.read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	token, hash, err := newToken(id)
+	if err != nil {
+		return nil, err
+	}
+
+	record.credential.ExpiresAt = expiresAt
+	record.tokenHash = hash
+
+	if err := cm.indexExpiry(record.credential); err != nil {
+		return nil, err
+	}
+
+	if err := writeSecretData(cm.client, cm.dataPath(id), record.data(), record.version); err != nil {
+		return nil, err
+	}
+
+	return &model.CredentialMetadata{
+		Credential: record.credential,
+		Token:      token,
+	}, nil
+}
+
+// Revoke deletes a credential and all of its descendants. Descendants are
+// removed before their parents, so an interrupted revocation leaves a tree
+// that can be revoked again.
+func (cm *VaultCredentialManager) Revoke(ctx context.Context, id string) error {
+	record, err := cm.read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := cm.revokeTree(ctx, id); err != nil {
+		return err
+	}
+
+	if record.credential.ParentID != "" {
+		return deleteSecret(cm.client, metadataPath(cm.engineMount, "credentials", record.credential.ParentID, "children", id))
+	}
+
+	return nil
+}
+
+func (cm *VaultCredentialManager) revokeTree(ctx context.Context, id string) error {
+	children, err := listSecrets(ctx, cm.client, metadataPath(cm.engineMount, "credentials", id, "children"))
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if err := cm.revokeTree(ctx, child); err != nil {
+			return err
+		}
+
+		if err := deleteSecret(cm.client, metadataPath(cm.engineMount, "credentials", id, "children", child)); err != nil {
+			return err
+		}
+	}
+
+	return deleteSecret(cm.client, metadataPath(cm.engineMount, "credentials", id, "credential"))
+}
+
+// indexExpiry adds a credential to the expiry index read by ListExpired. It
+// is written before the credential itself so that no credential that expires
+// is missing from the index.
+func (cm *VaultCredentialManager) indexExpiry(credential *model.Credential) error {
+	bucket := expiryBucket(credential)
+	if bucket == "" {
+		return nil
+	}
+
+	return writeSecretData(cm.client, dataPath(cm.engineMount, "credential_expiry", bucket, credential.ID), map[string]interface{}{
+		"expires_at": formatTime(credential.ExpiresAt),
+	}, -1)
+}
+
+func (cm *VaultCredentialManager) read(ctx context.Context, id string) (*credentialRecord, error) {
+	data, version, err := readSecretData(ctx, cm.client, cm.dataPath(id))
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, ErrCredentialNotFound
+	}
+
+	return credentialRecordFromData(id, data, version)
+}
+
+func (cm *VaultCredentialManager) dataPath(id string) string {
+	return dataPath(cm.engineMount, "credentials", id, "credential")
+}
+
+func (cm *VaultCredentialManager) childDataPath(parentID, id string) string {
+	return dataPath(cm.engineMount, "credentials", parentID, "children", id)
+}
+
+const (
+	// expiryBucketFormat names the buckets of the expiry index, each holding
+	// the credentials that expire within expiryBucketWidth of its start.
+	expiryBucketFormat = "2006010215"
+	expiryBucketWidth  = time.Hour
+)
+
+// expiryBucket returns the bucket of the expiry index of a credential, or ""
+// if it never expires.
+func expiryBucket(credential *model.Credential) string {
+	if credential.ExpiresAt.IsZero() {
+		return ""
+	}
+
+	return credential.ExpiresAt.UTC().Truncate(expiryBucketWidth).Format(expiryBucketFormat)
+}
+
+func credentialExpired(credential *model.Credential, now time.Time) bool {
+	return !credential.ExpiresAt.IsZero() && !now.Before(credential.ExpiresAt)
+}
+
+func NewVaultCredentialManager(cfg *opt.Config, vaultClient *api.Client) (model.CredentialManager, error) {
+	vaultEngineMount, err := vaultutil.CheckNormalizeEngineMount(vaultClient, cfg.VaultEngineMount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VaultCredentialManager{
+		client:      vaultClient,
+		engineMount: vaultEngineMount,
+	}, nil
+}
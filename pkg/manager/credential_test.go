@@ -0,0 +1,211 @@
+package manager_test
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVaultCredentialManager(t *testing.T) (model.CredentialManager, *fakeVault) {
+	fv, cfg, client := newFakeVault(t)
+
+	cm, err := manager.NewVaultCredentialManager(cfg, client)
+	require.NoError(t, err)
+
+	return cm, fv
+}
+
+func TestVaultCredentialManager(t *testing.T) {
+	ctx := context.Background()
+	cm, fv := newVaultCredentialManager(t)
+
+	parent, err := cm.Create(ctx, &model.Credential{
+		ID:       "parent",
+		Contexts: []string{"tenant"},
+		Scopes:   []string{model.ScopeLogRead},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, parent.Token)
+
+	_, err = cm.Create(ctx, &model.Credential{ID: "parent"})
+	assert.Equal(t, manager.ErrCredentialExists, err)
+
+	credential, err := cm.Authenticate(ctx, parent.Token)
+	require.NoError(t, err)
+	assert.Equal(t, &model.Credential{
+		ID:       "parent",
+		Contexts: []string{"tenant"},
+		Scopes:   []string{model.ScopeLogRead},
+	}, credential)
+
+	_, err = cm.Authenticate(ctx, parent.Token+"x")
+	assert.Equal(t, manager.ErrInvalidToken, err)
+
+	child, err := cm.Create(ctx, &model.Credential{
+		ParentID:  "parent",
+		Contexts:  []string{"tenant/workflow"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, child.Credential.ID)
+
+	children, err := cm.Children(ctx, "parent")
+	require.NoError(t, err)
+	assert.Equal(t, []string{child.Credential.ID}, children)
+
+	_, err = cm.Authenticate(ctx, child.Token)
+	require.NoError(t, err)
+
+	// Refreshing a credential replaces its token.
+	refreshed, err := cm.Refresh(ctx, child.Credential.ID, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+
+	_, err = cm.Authenticate(ctx, child.Token)
+	assert.Equal(t, manager.ErrInvalidToken, err)
+
+	_, err = cm.Authenticate(ctx, refreshed.Token)
+	require.NoError(t, err)
+
+	// Revoking a credential revokes its descendants.
+	require.NoError(t, cm.Revoke(ctx, "parent"))
+
+	for _, token := range []string{parent.Token, refreshed.Token} {
+		_, err = cm.Authenticate(ctx, token)
+		assert.Equal(t, manager.ErrInvalidToken, err)
+	}
+
+	_, err = cm.Get(ctx, child.Credential.ID)
+	assert.Equal(t, manager.ErrCredentialNotFound, err)
+	assert.Empty(t, fv.paths("credentials/"))
+
+	_, err = cm.Create(ctx, &model.Credential{ParentID: "parent"})
+	assert.Equal(t, manager.ErrCredentialNotFound, err)
+}
+
+func TestVaultCredentialManagerExpiry(t *testing.T) {
+	ctx := context.Background()
+	cm, fv := newVaultCredentialManager(t)
+
+	now := time.Now()
+
+	_, err := cm.Create(ctx, &model.Credential{ID: "parent", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	child, err := cm.Create(ctx, &model.Credential{ID: "child", ParentID: "parent"})
+	require.NoError(t, err)
+
+	parent, err := cm.Refresh(ctx, "parent", now.Add(-2*time.Hour))
+	require.NoError(t, err)
+
+	// A credential is expired along with its parent.
+	for _, token := range []string{parent.Token, child.Token} {
+		_, err = cm.Authenticate(ctx, token)
+		assert.Equal(t, manager.ErrCredentialExpired, err)
+	}
+
+	_, err = cm.Create(ctx, &model.Credential{ParentID: "parent"})
+	assert.Equal(t, manager.ErrCredentialExpired, err)
+
+	for _, id := range []string{"expired", "extended", "revoked"} {
+		_, err := cm.Create(ctx, &model.Credential{ID: id, ExpiresAt: now.Add(-2 * time.Hour)})
+		require.NoError(t, err)
+	}
+
+	_, err = cm.Refresh(ctx, "extended", now.Add(48*time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, cm.Revoke(ctx, "revoked"))
+
+	expired, err := cm.ListExpired(ctx, now, 10)
+	require.NoError(t, err)
+
+	var ids []string
+	for _, credential := range expired {
+		ids = append(ids, credential.ID)
+	}
+	assert.ElementsMatch(t, []string{"parent", "expired"}, ids)
+
+	// The index entries of the revoked credential and of the extended
+	// credential's previous expiry have been removed.
+	var indexed []string
+	for _, p := range fv.paths("credential_expiry/") {
+		indexed = append(indexed, strings.TrimPrefix(p, "credential_expiry/"))
+	}
+
+	bucket := func(t time.Time) string {
+		return t.UTC().Truncate(time.Hour).Format("2006010215")
+	}
+	assert.ElementsMatch(t, []string{
+		bucket(now.Add(time.Hour)) + "/parent",
+		bucket(now.Add(-2*time.Hour)) + "/parent",
+		bucket(now.Add(-2*time.Hour)) + "/expired",
+		bucket(now.Add(48*time.Hour)) + "/extended",
+	}, indexed)
+
+	expired, err = cm.ListExpired(ctx, now, 1)
+	require.NoError(t, err)
+	assert.Len(t, expired, 1)
+}
+
+func TestVaultCredentialManagerRefreshConflict(t *testing.T) {
+	ctx := context.Background()
+	cm, fv := newVaultCredentialManager(t)
+
+	_, err := cm.Create(ctx, &model.Credential{ID: "credential"})
+	require.NoError(t, err)
+
+	// A credential revoked and recreated while it is refreshed is not
+	// overwritten.
+	var raced int32
+	fv.setBeforeWrite(func(p string) error {
+		if p == "credentials/credential/credential" && atomic.CompareAndSwapInt32(&raced, 0, 1) {
+			fv.put(p, fv.get(p))
+		}
+
+		return nil
+	})
+
+	_, err = cm.Refresh(ctx, "credential", time.Time{})
+	assert.Error(t, err)
+}
+
+func TestVaultRootCredentialManager(t *testing.T) {
+	ctx := context.Background()
+	fv, cfg, client := newFakeVault(t)
+
+	cfg.RootTokenVaultPath = "root/token"
+
+	cm, err := manager.NewVaultCredentialManager(cfg, client)
+	require.NoError(t, err)
+
+	rcm, err := manager.NewVaultRootCredentialManager(cfg, client, cm)
+	require.NoError(t, err)
+
+	created, err := rcm.Bootstrap(ctx)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	token, _ := fv.get("root/token")["token"].(string)
+	credential, err := cm.Authenticate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, manager.RootCredentialID, credential.ID)
+
+	created, err = rcm.Bootstrap(ctx)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	require.NoError(t, rcm.Rotate(ctx))
+
+	_, err = cm.Authenticate(ctx, token)
+	assert.Equal(t, manager.ErrInvalidToken, err)
+
+	token, _ = fv.get("root/token")["token"].(string)
+	_, err = cm.Authenticate(ctx, token)
+	assert.NoError(t, err)
+}
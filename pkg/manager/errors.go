@@ -0,0 +1,16 @@
+package manager
+
+import "errors"
+
+var (
+	ErrConcurrentUpdate   = errors.New("manager: too many concurrent updates; try again")
+	ErrCredentialExists   = errors.New("manager: credential already exists")
+	ErrCredentialExpired  = errors.New("manager: credential has expired")
+	ErrCredentialNotFound = errors.New("manager: credential not found")
+	ErrInvalidLog         = errors.New("manager: log context and name must not be empty or contain empty levels")
+	ErrInvalidToken       = errors.New("manager: invalid token")
+	ErrLogNotFound        = errors.New("manager: log not found")
+	ErrLogSealed          = errors.New("manager: log is sealed")
+
+	ErrRootTokenDestinationRequired = errors.New("manager: a file or Vault path is required for the root token")
+)
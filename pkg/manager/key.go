@@ -20,6 +20,27 @@ var KeyManagerProviderSet = wire.NewSet(
 type KeyManager struct {
 }
 
+type aeadCipher struct {
+	a tink.AEAD
+}
+
+func (c *aeadCipher) Decrypt(data []byte) ([]byte, error) {
+	return c.a.Decrypt(data, nil)
+}
+
+func (c *aeadCipher) Encrypt(data []byte) ([]byte, error) {
+	return c.a.Encrypt(data, nil)
+}
+
+func (m *KeyManager) Cipher(ctx context.Context, key string) (model.Cipher, error) {
+	a, err := m.cipher(ctx, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aeadCipher{a: a}, nil
+}
+
 func (m *KeyManager) Create(ctx context.Context) (string, error) {
 	kt := aead.AES256GCMKeyTemplate()
 
@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/google/wire"
+	"github.com/hashicorp/vault/api"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/util/vaultutil"
+)
+
+var VaultLeaseProviderSet = wire.NewSet(
+	NewVaultLeaseManager,
+)
+
+// VaultLeaseManager coordinates work between replicas using leases stored in
+// Vault. Each lease is taken with a check-and-set write, so at most one
+// replica holds it at a time.
+type VaultLeaseManager struct {
+	client      *api.Client
+	engineMount string
+	holder      string
+}
+
+func (lm *VaultLeaseManager) Acquire(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	leasePath := dataPath(lm.engineMount, "leases", name)
+
+	data, version, err := readSecretData(ctx, lm.client, leasePath)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+
+	if data != nil && stringValue(data, "holder") != lm.holder {
+		expiresAt, err := timeValue(data, "expires_at")
+		if err != nil {
+			return false, err
+		}
+
+		if now.Before(expiresAt) {
+			return false, nil
+		}
+	}
+
+	lease := map[string]interface{}{
+		"holder":     lm.holder,
+		"expires_at": formatTime(now.Add(ttl)),
+	}
+
+	if err := writeSecretData(lm.client, leasePath, lease, version); err != nil {
+		if isCheckAndSetError(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func NewVaultLeaseManager(cfg *opt.Config, vaultClient *api.Client) (model.LeaseManager, error) {
+	vaultEngineMount, err := vaultutil.CheckNormalizeEngineMount(vaultClient, cfg.VaultEngineMount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VaultLeaseManager{
+		client:      vaultClient,
+		engineMount: vaultEngineMount,
+		holder:      uuid.New().String(),
+	}, nil
+}
+
+// InMemoryLeaseManager hands out every lease, as in-memory storage is never
+// shared with another replica.
+type InMemoryLeaseManager struct{}
+
+func (lm *InMemoryLeaseManager) Acquire(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func NewInMemoryLeaseManager() model.LeaseManager {
+	return &InMemoryLeaseManager{}
+}
@@ -0,0 +1,65 @@
+package manager_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultLeaseManager(t *testing.T) {
+	ctx := context.Background()
+	fv, cfg, client := newFakeVault(t)
+
+	a, err := manager.NewVaultLeaseManager(cfg, client)
+	require.NoError(t, err)
+
+	b, err := manager.NewVaultLeaseManager(cfg, client)
+	require.NoError(t, err)
+
+	acquired, err := a.Acquire(ctx, "sweep", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = b.Acquire(ctx, "sweep", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// Leases are independent of each other.
+	acquired, err = b.Acquire(ctx, "other", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// The holder may renew its lease, here letting it expire immediately.
+	acquired, err = a.Acquire(ctx, "sweep", -time.Second)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// An expired lease may be taken over by another replica.
+	acquired, err = b.Acquire(ctx, "sweep", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = a.Acquire(ctx, "sweep", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// A replica that loses the race to write the lease does not hold it.
+	var raced int32
+	fv.setBeforeWrite(func(p string) error {
+		if p == "leases/contended" && atomic.CompareAndSwapInt32(&raced, 0, 1) {
+			_, err := b.Acquire(ctx, "contended", time.Hour)
+			return err
+		}
+
+		return nil
+	})
+
+	acquired, err = a.Acquire(ctx, "contended", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+}
@@ -2,10 +2,15 @@ package manager
 
 import (
 	"context"
-	"path"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/google/wire"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/hashicorp/vault/api"
 	"github.com/puppetlabs/leg/encoding/transfer"
 	"github.com/puppetlabs/leg/timeutil/pkg/retry"
@@ -18,154 +23,801 @@ var VaultProviderSet = wire.NewSet(
 	NewVaultLogMetadataManager,
 )
 
+// logMetadataCacheSize is the most logs whose metadata is cached. The
+// context, name, creator and key of a log never change, so once cached only
+// the sequence secret, which records the seal and deletion of the log, is
+// read again.
+const logMetadataCacheSize = 10000
+
 type VaultLogMetadataManager struct {
 	client      *api.Client
 	engineMount string
 
 	keyManager model.KeyManager
+	cache      *lru.Cache
+
+	// backfillMut serializes the backfill of metadata within a replica.
+	backfillMut sync.Mutex
 }
 
 func (lmm *VaultLogMetadataManager) Get(ctx context.Context, id string) (*model.LogMetadata, error) {
-	logMetadataPath := path.Join(lmm.engineMount, "data", "logs", id)
+	seal, err := lmm.readSeal(ctx, id)
+	if err == ErrLogNotFound {
+		// Deleted, possibly by another replica.
+		lmm.cache.Remove(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lm, err := lmm.cachedMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-	dataPath := path.Join(logMetadataPath, "encryption_key")
+	r := *lm
+	if lm.Log != nil {
+		l := *lm.Log
+		r.Log = &l
+	}
+	r.Seal = seal
+
+	return &r, nil
+}
+
+// cachedMetadata returns the metadata of a log, including its key but not
+// its seal, reading it from Vault if it is not cached.
+func (lmm *VaultLogMetadataManager) cachedMetadata(ctx context.Context, id string) (*model.LogMetadata, error) {
+	if cached, found := lmm.cache.Get(id); found {
+		return cached.(*model.LogMetadata), nil
+	}
+
+	value, _, err := lmm.readKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
+	lm, err := lmm.readMetadata(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if lm == nil {
+		lm, err = lmm.backfillMetadata(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lm.Key = value
+
+	lmm.cache.Add(id, lm)
+
+	return lm, nil
+}
+
+// readKey reads the encryption key of a log, along with the time it was
+// written.
+func (lmm *VaultLogMetadataManager) readKey(ctx context.Context, id string) (string, time.Time, error) {
 	var key *api.Secret
 	err := retry.Wait(ctx, func(ctx context.Context) (bool, error) {
 		var verr error
-		key, verr = lmm.client.Logical().Read(dataPath)
+		key, verr = lmm.client.Logical().Read(dataPath(lmm.engineMount, "logs", id, "encryption_key"))
 		if verr != nil {
 			return false, verr
 		}
 
 		return true, nil
 	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if key == nil || key.Data == nil {
+		return "", time.Time{}, ErrLogNotFound
+	}
+
+	data, ok := key.Data["data"].(map[string]interface{})
+	if !ok {
+		return "", time.Time{}, ErrLogNotFound
+	}
+
+	value, found := data["value"].(string)
+	if !found {
+		return "", time.Time{}, ErrLogNotFound
+	}
+
+	var createdAt time.Time
+	if metadata, ok := key.Data["metadata"].(map[string]interface{}); ok {
+		createdAt, _ = timeValue(metadata, "created_time")
+	}
+
+	return value, createdAt, nil
+}
+
+// readMetadata reads the stored metadata of a log, or returns nil if it has
+// none. Metadata without a context marks a log that is in no context, so
+// that nobody can be authorized to use it.
+func (lmm *VaultLogMetadataManager) readMetadata(ctx context.Context, id string) (*model.LogMetadata, error) {
+	data, _, err := readSecretData(ctx, lmm.client, dataPath(lmm.engineMount, "logs", id, "metadata"))
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	createdAt, err := timeValue(data, "created_at")
 	if err != nil {
 		return nil, err
 	}
 
+	lm := &model.LogMetadata{
+		CreatedAt: createdAt,
+		Creator:   stringValue(data, "creator"),
+		LogID:     id,
+	}
+
+	if logContext := stringValue(data, "context"); logContext != "" {
+		lm.Log = &model.Log{
+			Context: logContext,
+			Name:    stringValue(data, "name"),
+		}
+	}
+
+	return lm, nil
+}
+
+func (lmm *VaultLogMetadataManager) writeMetadata(lm *model.LogMetadata) error {
+	var logContext, name string
+	if lm.Log != nil {
+		logContext, name = lm.Log.Context, lm.Log.Name
+	}
+
+	return writeSecretData(lmm.client, dataPath(lmm.engineMount, "logs", lm.LogID, "metadata"), map[string]interface{}{
+		"context":    logContext,
+		"name":       name,
+		"created_at": formatTime(lm.CreatedAt),
+		"creator":    lm.Creator,
+	}, 0)
+}
+
+// backfillMetadata stores the metadata of a log that predates stored
+// metadata. The context index only records logs the other way around, so the
+// first time such a log is found, the whole index is walked once to backfill
+// every log in it, and the walk is recorded in Vault so that no replica
+// repeats it. A log still without metadata after that is no longer indexed,
+// and is stored without a context.
+func (lmm *VaultLogMetadataManager) backfillMetadata(ctx context.Context, id string) (*model.LogMetadata, error) {
+	lmm.backfillMut.Lock()
+	defer lmm.backfillMut.Unlock()
+
+	data, _, err := readSecretData(ctx, lmm.client, dataPath(lmm.engineMount, metadataBackfillPath))
 	if err != nil {
 		return nil, err
 	}
 
-	if key == nil {
-		return nil, nil
+	if data == nil {
+		if err := lmm.backfillIndex(ctx); err != nil {
+			return nil, err
+		}
+
+		if err := writeSecretData(lmm.client, dataPath(lmm.engineMount, metadataBackfillPath), map[string]interface{}{
+			"completed_at": formatTime(time.Now()),
+		}, -1); err != nil {
+			return nil, err
+		}
 	}
 
-	if key.Data == nil {
-		return nil, nil
+	// Backfilled just now, or by another replica.
+	lm, err := lmm.readMetadata(ctx, id)
+	if err != nil || lm != nil {
+		return lm, err
 	}
 
-	data, ok := key.Data["data"].(map[string]interface{})
-	if !ok {
+	return lmm.backfillLog(ctx, id, nil)
+}
+
+// backfillIndex stores the metadata of every log in the context index that
+// has none.
+func (lmm *VaultLogMetadataManager) backfillIndex(ctx context.Context) error {
+	lms, err := lmm.List(ctx, nil, func(string) bool { return true })
+	if err != nil {
+		return err
+	}
+
+	for _, indexed := range lms {
+		lm, err := lmm.readMetadata(ctx, indexed.LogID)
+		if err != nil {
+			return err
+		} else if lm != nil {
+			continue
+		}
+
+		if _, err := lmm.backfillLog(ctx, indexed.LogID, indexed.Log); err == ErrLogNotFound {
+			// Deleted since it was listed.
+			continue
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backfillLog stores the metadata of a log that predates stored metadata. Its
+// key was written when it was created, which is the closest there is to a
+// creation time.
+func (lmm *VaultLogMetadataManager) backfillLog(ctx context.Context, id string, log *model.Log) (*model.LogMetadata, error) {
+	_, createdAt, err := lmm.readKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	lm := &model.LogMetadata{
+		CreatedAt: createdAt,
+		Log:       log,
+		LogID:     id,
+	}
+
+	if err := lmm.writeMetadata(lm); isCheckAndSetError(err) {
+		// Backfilled concurrently by another replica.
+		return lmm.readMetadata(ctx, id)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return lm, nil
+}
+
+// Create returns the log with the given context and name, creating it if it
+// does not exist. The log's metadata and encryption key are written before
+// its entry in the context index, so a log only becomes reachable once it is
+// complete. Concurrent creates of the same log all return the one that won
+// the index write.
+func (lmm *VaultLogMetadataManager) Create(ctx context.Context, log *model.Log, creator string) (*model.LogMetadata, error) {
+	if !validIndexContext(log.Context) || log.Name == "" {
+		return nil, ErrInvalidLog
+	}
+
+	indexPath := dataPath(lmm.engineMount, logIndexPath(log)...)
+
+	lm, err := lmm.lookup(ctx, indexPath, log, creator)
+	if err != nil || lm != nil {
+		return lm, err
+	}
+
+	key, err := lmm.keyManager.Create(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lm = &model.LogMetadata{
+		CreatedAt: time.Now(),
+		Creator:   creator,
+		Key:       key,
+		Log:       log,
+		LogID:     uuid.New().String(),
+	}
+
+	if err := lmm.writeMetadata(lm); err != nil {
+		return nil, err
+	}
+
+	if err := lmm.writeKey(lm.LogID, key); err != nil {
+		lmm.rollback(lm.LogID)
+		return nil, err
+	}
+
+	v, err := transfer.EncodeForTransfer([]byte(lm.LogID))
+	if err != nil {
+		lmm.rollback(lm.LogID)
+		return nil, err
+	}
+
+	if err := writeSecretData(lmm.client, indexPath, map[string]interface{}{"value": v}, 0); err != nil {
+		lmm.rollback(lm.LogID)
+
+		if !isCheckAndSetError(err) {
+			return nil, err
+		}
+
+		// Another create of the same log won the race.
+		lm, err := lmm.lookup(ctx, indexPath, log, creator)
+		if err != nil {
+			return nil, err
+		} else if lm == nil {
+			return nil, ErrLogNotFound
+		}
+
+		return lm, nil
+	}
+
+	return lm, nil
+}
+
+// lookup returns the log referenced by an entry in the context index, or nil
+// if there is no entry. Logs indexed without a key, which earlier versions
+// could leave behind on failure, are repaired by creating their key.
+func (lmm *VaultLogMetadataManager) lookup(ctx context.Context, indexPath string, log *model.Log, creator string) (*model.LogMetadata, error) {
+	data, _, err := readSecretData(ctx, lmm.client, indexPath)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	id, err := transfer.DecodeFromTransfer(stringValue(data, "value"))
+	if err != nil {
+		return nil, err
+	} else if len(id) == 0 {
 		return nil, nil
 	}
 
-	value, found := data["value"].(string)
-	if !found {
+	lm, err := lmm.Get(ctx, string(id))
+	if err != ErrLogNotFound {
+		return lm, err
+	}
+
+	if _, err := lmm.readSeal(ctx, string(id)); err == ErrLogNotFound {
+		// The log was deleted, but the delete did not get as far as its
+		// entry in the index.
+		if err := deleteSecret(lmm.client, metadataPath(lmm.engineMount, logIndexPath(log)...)); err != nil {
+			return nil, err
+		}
+
 		return nil, nil
+	} else if err != nil {
+		return nil, err
 	}
 
-	// FIXME Lookup context and name?
-	return &model.LogMetadata{
-		Key:   value,
-		LogID: id,
-	}, nil
+	key, err := lmm.keyManager.Create(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lmm.writeMetadata(&model.LogMetadata{
+		CreatedAt: time.Now(),
+		Creator:   creator,
+		Log:       log,
+		LogID:     string(id),
+	}); err != nil && !isCheckAndSetError(err) {
+		return nil, err
+	}
+
+	// If the key already exists, another create repaired the log first.
+	if err := lmm.writeKey(string(id), key); err != nil && !isCheckAndSetError(err) {
+		return nil, err
+	}
+
+	return lmm.Get(ctx, string(id))
 }
 
-func (lmm *VaultLogMetadataManager) Create(ctx context.Context, log *model.Log) (*model.LogMetadata, error) {
-	logContextPath := path.Join(lmm.engineMount, "data", "contexts", log.Context, "name", log.Name)
+func (lmm *VaultLogMetadataManager) writeKey(id, key string) error {
+	v, err := transfer.EncodeForTransfer([]byte(key))
+	if err != nil {
+		return err
+	}
+
+	return writeSecretData(lmm.client, dataPath(lmm.engineMount, "logs", id, "encryption_key"), map[string]interface{}{"value": v}, 0)
+}
 
-	dataPath := path.Join(logContextPath, "log_id")
+// rollback removes the metadata and key of a log that was never indexed. It
+// is best effort: a log that is not indexed can never be returned by Create
+// or List.
+func (lmm *VaultLogMetadataManager) rollback(id string) {
+	_ = deleteSecret(lmm.client, metadataPath(lmm.engineMount, "logs", id, "encryption_key"))
+	_ = deleteSecret(lmm.client, metadataPath(lmm.engineMount, "logs", id, "metadata"))
+}
 
-	var logID *api.Secret
-	err := retry.Wait(ctx, func(ctx context.Context) (bool, error) {
-		var verr error
-		logID, verr = lmm.client.Logical().Read(dataPath)
-		if verr != nil {
-			return false, verr
+// ReserveSequence and Seal share the sequence secret of a log, so that no
+// sequence can be reserved once the log is sealed. Both update it with
+// check-and-set, giving up with ErrConcurrentUpdate if other writers keep
+// updating it first.
+//
+// Sequences are reserved from the secret for each batch of messages rather
+// than in blocks held by each replica, as a block would let one replica
+// assign sequences below those another has already stored, and followers and
+// seals rely on sequences being assigned in order.
+func (lmm *VaultLogMetadataManager) ReserveSequence(ctx context.Context, id string, n int) (int64, error) {
+	var first int64
+	err := updateSecretData(ctx, lmm.client, dataPath(lmm.engineMount, "logs", id, "sequence"), func(data map[string]interface{}) (map[string]interface{}, error) {
+		next, seal, err := decodeSequence(data)
+		if err != nil {
+			return nil, err
+		} else if seal != nil {
+			return nil, ErrLogSealed
 		}
 
-		return true, nil
+		first = next
+
+		return map[string]interface{}{
+			"next": strconv.FormatInt(next+int64(n), 10),
+		}, nil
 	})
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	if logID != nil {
-		if logID.Data == nil {
-			return nil, nil
+	return first, nil
+}
+
+func (lmm *VaultLogMetadataManager) Seal(ctx context.Context, id string, seal *model.LogSeal) (*model.LogSeal, error) {
+	var sealed *model.LogSeal
+	err := updateSecretData(ctx, lmm.client, dataPath(lmm.engineMount, "logs", id, "sequence"), func(data map[string]interface{}) (map[string]interface{}, error) {
+		next, existing, err := decodeSequence(data)
+		if err != nil {
+			return nil, err
+		} else if existing != nil {
+			return nil, ErrLogSealed
 		}
 
-		data, ok := logID.Data["data"].(map[string]interface{})
-		if !ok {
-			return nil, nil
+		sealed = &model.LogSeal{
+			SealedAt:     seal.SealedAt,
+			ExitCode:     seal.ExitCode,
+			LastSequence: next - 1,
 		}
 
-		value, found := data["value"].(string)
-		if !found {
-			return nil, nil
+		update := map[string]interface{}{
+			"next":      strconv.FormatInt(next, 10),
+			"sealed_at": formatTime(seal.SealedAt),
+		}
+		if seal.ExitCode != nil {
+			update["exit_code"] = strconv.FormatInt(int64(*seal.ExitCode), 10)
 		}
 
-		// FIXME Add/lookup key or not?
-		return &model.LogMetadata{
-			Log:   log,
-			LogID: value,
-		}, nil
+		return update, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	id := uuid.New().String()
+	return sealed, nil
+}
 
-	v, err := transfer.EncodeForTransfer([]byte(id))
+// readSeal reads the seal of a log from its sequence secret, returning nil if
+// the log is not sealed.
+func (lmm *VaultLogMetadataManager) readSeal(ctx context.Context, id string) (*model.LogSeal, error) {
+	data, _, err := readSecretData(ctx, lmm.client, dataPath(lmm.engineMount, "logs", id, "sequence"))
 	if err != nil {
 		return nil, err
 	}
 
-	payload := map[string]interface{}{
-		"data": map[string]interface{}{
-			"value": v,
-		},
-		"options": map[string]interface{}{
-			"cas": 0,
-		},
+	_, seal, err := decodeSequence(data)
+	return seal, err
+}
+
+// decodeSequence decodes the data of a sequence secret. The secret is left
+// behind as a tombstone when a log is deleted, in which case ErrLogNotFound
+// is returned.
+func decodeSequence(data map[string]interface{}) (int64, *model.LogSeal, error) {
+	if stringValue(data, "deleted_at") != "" {
+		return 0, nil, ErrLogNotFound
 	}
 
-	if _, err := lmm.client.Logical().Write(dataPath, payload); err != nil {
-		return nil, err
+	next := int64(1)
+	if value := stringValue(data, "next"); value != "" {
+		var err error
+		if next, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return 0, nil, err
+		}
 	}
 
-	logMetadataPath := path.Join(lmm.engineMount, "data", "logs", id)
+	if stringValue(data, "sealed_at") == "" {
+		return next, nil, nil
+	}
 
-	key, err := lmm.keyManager.Create(ctx)
+	sealedAt, err := timeValue(data, "sealed_at")
 	if err != nil {
-		return nil, err
+		return 0, nil, err
+	}
+
+	seal := &model.LogSeal{
+		SealedAt:     sealedAt,
+		LastSequence: next - 1,
+	}
+
+	if value := stringValue(data, "exit_code"); value != "" {
+		exitCode, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		seal.ExitCode = new(int32)
+		*seal.ExitCode = int32(exitCode)
+	}
+
+	return next, seal, nil
+}
+
+func (lmm *VaultLogMetadataManager) Delete(ctx context.Context, id string) error {
+	// The log is recorded as deleted first, so that its messages are purged
+	// even if removing the metadata fails part way.
+	deleted := map[string]interface{}{
+		"deleted_at": formatTime(time.Now()),
+	}
+
+	if err := writeSecretData(lmm.client, dataPath(lmm.engineMount, "deleted_logs", id), deleted, -1); err != nil {
+		return err
+	}
+
+	// The sequence secret becomes a tombstone, so that other replicas,
+	// which may have cached the log, see that it is gone and no more
+	// sequences can be reserved for it.
+	if err := writeSecretData(lmm.client, dataPath(lmm.engineMount, "logs", id, "sequence"), deleted, -1); err != nil {
+		return err
+	}
+
+	lmm.cache.Remove(id)
+
+	// Deleting the metadata removes every version of the key, not just the
+	// current one.
+	if err := deleteSecret(lmm.client, metadataPath(lmm.engineMount, "logs", id, "encryption_key")); err != nil {
+		return err
 	}
 
-	v, err = transfer.EncodeForTransfer([]byte(key))
+	lm, err := lmm.readMetadata(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if lm != nil {
+		if err := deleteSecret(lmm.client, metadataPath(lmm.engineMount, logIndexPath(lm.Log)...)); err != nil {
+			return err
+		}
+	}
+
+	return deleteSecret(lmm.client, metadataPath(lmm.engineMount, "logs", id, "metadata"))
+}
+
+func (lmm *VaultLogMetadataManager) ListDeleted(ctx context.Context, limit int) ([]string, error) {
+	keys, err := listSecrets(ctx, lmm.client, metadataPath(lmm.engineMount, "deleted_logs"))
 	if err != nil {
 		return nil, err
 	}
 
-	keyPath := path.Join(logMetadataPath, "encryption_key")
+	now := time.Now()
 
-	payload = map[string]interface{}{
-		"data": map[string]interface{}{
-			"value": v,
-		},
-		"options": map[string]interface{}{
-			"cas": 0,
-		},
+	var ids []string
+	for _, key := range keys {
+		if len(ids) >= limit {
+			break
+		}
+
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+
+		data, _, err := readSecretData(ctx, lmm.client, dataPath(lmm.engineMount, "deleted_logs", key))
+		if err != nil {
+			return nil, err
+		} else if data == nil {
+			// Purged since it was listed.
+			continue
+		}
+
+		purgeAfter, err := timeValue(data, "purge_after")
+		if err != nil {
+			return nil, err
+		} else if now.Before(purgeAfter) {
+			continue
+		}
+
+		ids = append(ids, key)
 	}
 
-	if _, err := lmm.client.Logical().Write(keyPath, payload); err != nil {
+	return ids, nil
+}
+
+func (lmm *VaultLogMetadataManager) DeferPurge(ctx context.Context, id string, until time.Time) error {
+	return updateSecretData(ctx, lmm.client, dataPath(lmm.engineMount, "deleted_logs", id), func(data map[string]interface{}) (map[string]interface{}, error) {
+		if data == nil {
+			// Already purged.
+			return nil, nil
+		}
+
+		data["purge_after"] = formatTime(until)
+		return data, nil
+	})
+}
+
+func (lmm *VaultLogMetadataManager) Purged(ctx context.Context, id string) error {
+	return deleteSecret(lmm.client, metadataPath(lmm.engineMount, "deleted_logs", id))
+}
+
+// List walks the context index written by Create beneath each of the given
+// context prefixes, or the whole index if there are none, and returns the
+// logs in every context accepted by match. The returned metadata does not
+// include encryption keys.
+func (lmm *VaultLogMetadataManager) List(ctx context.Context, prefixes []string, match func(logContext string) bool) ([]*model.LogMetadata, error) {
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+
+	var lms []*model.LogMetadata
+	for _, prefix := range prefixes {
+		if err := lmm.listContext(ctx, prefix, match, &lms); err != nil {
+			return nil, err
+		}
+	}
+
+	return lms, nil
+}
+
+func (lmm *VaultLogMetadataManager) ListContext(ctx context.Context, logContext string) ([]*model.LogMetadata, error) {
+	var lms []*model.LogMetadata
+	if err := lmm.listNames(ctx, logContext, &lms); err != nil {
 		return nil, err
 	}
 
-	return &model.LogMetadata{
-		Key:   key,
-		Log:   log,
-		LogID: id,
-	}, nil
+	return lms, nil
+}
+
+func (lmm *VaultLogMetadataManager) listContext(ctx context.Context, logContext string, match func(logContext string) bool, lms *[]*model.LogMetadata) error {
+	keys, err := listSecrets(ctx, lmm.client, metadataPath(lmm.engineMount, contextIndexPath(logContext)...))
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if !strings.HasSuffix(key, "/") {
+			continue
+		}
+
+		key = strings.TrimSuffix(key, "/")
+
+		// Context levels are escaped so that none can be mistaken for the
+		// name folder, which holds the logs of the context above it.
+		if key == contextIndexNameFolder {
+			if logContext == "" || !match(logContext) {
+				continue
+			}
+
+			if err := lmm.listNames(ctx, logContext, lms); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		level, err := url.PathUnescape(key)
+		if err != nil {
+			return err
+		}
+
+		child := level
+		if logContext != "" {
+			child = logContext + contextSeparator + level
+		}
+
+		if err := lmm.listContext(ctx, child, match, lms); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (lmm *VaultLogMetadataManager) listNames(ctx context.Context, logContext string, lms *[]*model.LogMetadata) error {
+	namesPath := append(contextIndexPath(logContext), contextIndexNameFolder)
+
+	keys, err := listSecrets(ctx, lmm.client, metadataPath(lmm.engineMount, namesPath...))
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		key = strings.TrimSuffix(key, "/")
+
+		name, err := url.PathUnescape(key)
+		if err != nil {
+			return err
+		}
+
+		data, _, err := readSecretData(ctx, lmm.client, dataPath(lmm.engineMount, append(namesPath, key, "log_id")...))
+		if err != nil {
+			return err
+		}
+
+		value := stringValue(data, "value")
+		if value == "" {
+			continue
+		}
+
+		id, err := transfer.DecodeFromTransfer(value)
+		if err != nil {
+			return err
+		}
+
+		seal, err := lmm.readSeal(ctx, string(id))
+		if err == ErrLogNotFound {
+			// Deleted since it was listed.
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		*lms = append(*lms, &model.LogMetadata{
+			Log: &model.Log{
+				Context: logContext,
+				Name:    name,
+			},
+			LogID: string(id),
+			Seal:  seal,
+		})
+	}
+
+	return nil
+}
+
+const (
+	// metadataBackfillPath records that the metadata of every log in the
+	// context index has been backfilled.
+	metadataBackfillPath = "migrations/log_metadata_backfill"
+
+	// contextSeparator separates the levels of a hierarchical log context.
+	contextSeparator = "/"
+
+	// contextIndexNameFolder holds the logs of a context in the context
+	// index, beneath the folders of its levels.
+	contextIndexNameFolder = "name"
+
+	// escapedContextIndexNameFolder is a context level that reads the same
+	// as the name folder, with its first character percent-encoded.
+	escapedContextIndexNameFolder = "%6Eame"
+)
+
+// contextIndexPath returns the path of a log context in the context index
+// beneath the contexts folder, with a folder for each of its levels, as in
+// contexts/tenant/workflow. Percent signs, a level that would read as the
+// name folder and the dots of a "." or ".." level are percent-encoded, so
+// that the index can be walked without ambiguity and no level is collapsed
+// into its parent when the path is joined, while the paths of every other
+// context are left unchanged. Contexts with empty levels, which cannot be
+// told apart from their neighbours once joined, are refused by Create.
+func contextIndexPath(logContext string) []string {
+	p := []string{"contexts"}
+	if logContext == "" {
+		return p
+	}
+
+	for _, level := range strings.Split(logContext, contextSeparator) {
+		level = escapeIndexFolder(level)
+		if level == contextIndexNameFolder {
+			level = escapedContextIndexNameFolder
+		}
+
+		p = append(p, level)
+	}
+
+	return p
+}
+
+// logIndexPath returns the path of the entry for a log in the context index,
+// as in contexts/tenant/workflow/name/stdout/log_id. Separators in the name
+// are percent-encoded along with whatever escapeIndexFolder encodes, so that
+// it is a single folder.
+func logIndexPath(log *model.Log) []string {
+	name := strings.ReplaceAll(escapeIndexFolder(log.Name), "/", "%2F")
+	return append(contextIndexPath(log.Context), contextIndexNameFolder, name, "log_id")
+}
+
+// escapeIndexFolder percent-encodes the percent signs of a folder name in the
+// context index, and its dots if it is "." or "..".
+func escapeIndexFolder(folder string) string {
+	switch folder {
+	case ".", "..":
+		return strings.Repeat("%2E", len(folder))
+	}
+
+	return strings.NewReplacer("%", "%25").Replace(folder)
+}
+
+// validIndexContext determines whether a log context can be written to the
+// context index.
+func validIndexContext(logContext string) bool {
+	for _, level := range strings.Split(logContext, contextSeparator) {
+		if level == "" {
+			return false
+		}
+	}
+
+	return true
 }
 
 func NewVaultLogMetadataManager(cfg *opt.Config, vaultClient *api.Client) (model.LogMetadataManager, error) {
@@ -174,10 +826,16 @@ func NewVaultLogMetadataManager(cfg *opt.Config, vaultClient *api.Client) (model
 		return nil, err
 	}
 
+	cache, err := lru.New(logMetadataCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
 	return &VaultLogMetadataManager{
 		client:      vaultClient,
 		engineMount: vaultEngineMount,
 
 		keyManager: NewKeyManager(),
+		cache:      cache,
 	}, nil
 }
@@ -0,0 +1,526 @@
+package manager_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/puppetlabs/leg/encoding/transfer"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVaultLogMetadataManager(t *testing.T) (model.LogMetadataManager, *fakeVault, func() model.LogMetadataManager) {
+	fv, cfg, client := newFakeVault(t)
+
+	// Each manager has its own cache, like a replica.
+	replica := func() model.LogMetadataManager {
+		lmm, err := manager.NewVaultLogMetadataManager(cfg, client)
+		require.NoError(t, err)
+
+		return lmm
+	}
+
+	return replica(), fv, replica
+}
+
+func encodeForTransfer(t *testing.T, value string) string {
+	encoded, err := transfer.EncodeForTransfer([]byte(value))
+	require.NoError(t, err)
+
+	return encoded
+}
+
+func TestVaultLogMetadataManagerCreate(t *testing.T) {
+	ctx := context.Background()
+	lmm, fv, replica := newVaultLogMetadataManager(t)
+
+	log := &model.Log{Context: "tenant/workflow", Name: "stdout"}
+
+	lm, err := lmm.Create(ctx, log, "creator")
+	require.NoError(t, err)
+	assert.NotEmpty(t, lm.LogID)
+	assert.NotEmpty(t, lm.Key)
+	assert.Equal(t, "creator", lm.Creator)
+	assert.Equal(t, log, lm.Log)
+
+	assert.Equal(t,
+		encodeForTransfer(t, lm.LogID),
+		fv.get("contexts/tenant/workflow/name/stdout/log_id")["value"])
+	assert.Equal(t, "tenant/workflow", fv.get("logs/" + lm.LogID + "/metadata")["context"])
+
+	// Creating the log again, on any replica, returns the same log.
+	again, err := replica().Create(ctx, &model.Log{Context: "tenant/workflow", Name: "stdout"}, "other")
+	require.NoError(t, err)
+	assert.Equal(t, lm.LogID, again.LogID)
+	assert.Equal(t, lm.Key, again.Key)
+	assert.Equal(t, "creator", again.Creator)
+
+	got, err := replica().Get(ctx, lm.LogID)
+	require.NoError(t, err)
+	assert.Equal(t, lm.Key, got.Key)
+	assert.Equal(t, log, got.Log)
+	assert.True(t, lm.CreatedAt.Equal(got.CreatedAt))
+	assert.Nil(t, got.Seal)
+
+	_, err = lmm.Get(ctx, uuid.New().String())
+	assert.Equal(t, manager.ErrLogNotFound, err)
+}
+
+func TestVaultLogMetadataManagerCreateEscaping(t *testing.T) {
+	ctx := context.Background()
+	lmm, fv, _ := newVaultLogMetadataManager(t)
+
+	for _, test := range []struct {
+		Log       *model.Log
+		IndexPath string
+	}{
+		{
+			Log:       &model.Log{Context: "tenant/name", Name: "stdout"},
+			IndexPath: "contexts/tenant/%6Eame/name/stdout/log_id",
+		},
+		{
+			Log:       &model.Log{Context: "tenant/100%", Name: "std/out%"},
+			IndexPath: "contexts/tenant/100%25/name/std%2Fout%25/log_id",
+		},
+		{
+			Log:       &model.Log{Context: "tenant-a/../tenant-b", Name: "stdout"},
+			IndexPath: "contexts/tenant-a/%2E%2E/tenant-b/name/stdout/log_id",
+		},
+		{
+			Log:       &model.Log{Context: "tenant-a/./@system", Name: ".."},
+			IndexPath: "contexts/tenant-a/%2E/@system/name/%2E%2E/log_id",
+		},
+		{
+			Log:       &model.Log{Context: "tenant/...", Name: "."},
+			IndexPath: "contexts/tenant/.../name/%2E/log_id",
+		},
+	} {
+		lm, err := lmm.Create(ctx, test.Log, "")
+		require.NoError(t, err)
+		assert.Equal(t, encodeForTransfer(t, lm.LogID), fv.get(test.IndexPath)["value"], test.IndexPath)
+	}
+
+	// Nothing was indexed outside of the contexts created.
+	assert.Empty(t, fv.paths("contexts/tenant-b/"))
+	assert.Empty(t, fv.paths("contexts/@system/"))
+
+	// The escaped contexts and names are listed as they were created.
+	lms, err := lmm.List(ctx, []string{"tenant-a"}, func(string) bool { return true })
+	require.NoError(t, err)
+
+	var logs []*model.Log
+	for _, lm := range lms {
+		logs = append(logs, lm.Log)
+	}
+	assert.ElementsMatch(t, []*model.Log{
+		{Context: "tenant-a/../tenant-b", Name: "stdout"},
+		{Context: "tenant-a/./@system", Name: ".."},
+	}, logs)
+
+	for _, log := range []*model.Log{
+		{Context: "tenant-a//tenant-b", Name: "stdout"},
+		{Context: "tenant/", Name: "stdout"},
+		{Context: "", Name: "stdout"},
+		{Context: "tenant", Name: ""},
+	} {
+		_, err := lmm.Create(ctx, log, "")
+		assert.Equal(t, manager.ErrInvalidLog, err, "log %+v", log)
+	}
+}
+
+func TestVaultLogMetadataManagerCreateConflict(t *testing.T) {
+	ctx := context.Background()
+	lmm, fv, replica := newVaultLogMetadataManager(t)
+
+	log := &model.Log{Context: "tenant", Name: "stdout"}
+	indexPath := "contexts/tenant/name/stdout/log_id"
+
+	// Another replica creates the same log just before this one writes its
+	// index entry.
+	var raced int32
+	var winner *model.LogMetadata
+	fv.setBeforeWrite(func(p string) error {
+		if p != indexPath || !atomic.CompareAndSwapInt32(&raced, 0, 1) {
+			return nil
+		}
+
+		var err error
+		winner, err = replica().Create(ctx, log, "winner")
+		return err
+	})
+
+	lm, err := lmm.Create(ctx, log, "loser")
+	require.NoError(t, err)
+	require.NotNil(t, winner)
+	assert.Equal(t, winner.LogID, lm.LogID)
+	assert.Equal(t, winner.Key, lm.Key)
+	assert.Equal(t, "winner", lm.Creator)
+
+	// The log that lost the race was rolled back.
+	assert.Equal(t, []string{
+		"logs/" + winner.LogID + "/encryption_key",
+		"logs/" + winner.LogID + "/metadata",
+	}, fv.paths("logs/"))
+}
+
+func TestVaultLogMetadataManagerCreateRollback(t *testing.T) {
+	ctx := context.Background()
+	lmm, fv, _ := newVaultLogMetadataManager(t)
+
+	fv.setBeforeWrite(func(p string) error {
+		if strings.HasSuffix(p, "/encryption_key") {
+			return errors.New("permission denied")
+		}
+
+		return nil
+	})
+
+	_, err := lmm.Create(ctx, &model.Log{Context: "tenant", Name: "stdout"}, "")
+	assert.Error(t, err)
+	assert.Empty(t, fv.paths("logs/"))
+	assert.Empty(t, fv.paths("contexts/"))
+
+	fv.setBeforeWrite(nil)
+
+	lm, err := lmm.Create(ctx, &model.Log{Context: "tenant", Name: "stdout"}, "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, lm.Key)
+}
+
+func TestVaultLogMetadataManagerCreateRepair(t *testing.T) {
+	ctx := context.Background()
+	lmm, fv, _ := newVaultLogMetadataManager(t)
+
+	// Earlier versions could index a log without writing its key.
+	id := uuid.New().String()
+	fv.put("contexts/tenant/name/stdout/log_id", map[string]interface{}{"value": encodeForTransfer(t, id)})
+
+	lm, err := lmm.Create(ctx, &model.Log{Context: "tenant", Name: "stdout"}, "repairer")
+	require.NoError(t, err)
+	assert.Equal(t, id, lm.LogID)
+	assert.NotEmpty(t, lm.Key)
+	assert.Equal(t, &model.Log{Context: "tenant", Name: "stdout"}, lm.Log)
+	assert.NotNil(t, fv.get("logs/"+id+"/encryption_key"))
+	assert.Equal(t, "tenant", fv.get("logs/" + id + "/metadata")["context"])
+}
+
+func TestVaultLogMetadataManagerBackfill(t *testing.T) {
+	ctx := context.Background()
+	_, fv, replica := newVaultLogMetadataManager(t)
+
+	key, err := manager.NewKeyManager().Create(ctx)
+	require.NoError(t, err)
+
+	// Logs created before their metadata was stored only have a key and an
+	// entry in the index, if any.
+	stdout, stderr, unindexed := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	for _, id := range []string{stdout, stderr, unindexed} {
+		fv.put("logs/"+id+"/encryption_key", map[string]interface{}{"value": encodeForTransfer(t, key)})
+	}
+	fv.put("contexts/tenant/name/stdout/log_id", map[string]interface{}{"value": encodeForTransfer(t, stdout)})
+	fv.put("contexts/tenant/workflow/name/stderr/log_id", map[string]interface{}{"value": encodeForTransfer(t, stderr)})
+
+	lm, err := replica().Get(ctx, stdout)
+	require.NoError(t, err)
+	assert.Equal(t, &model.Log{Context: "tenant", Name: "stdout"}, lm.Log)
+	assert.Equal(t, key, lm.Key)
+	assert.False(t, lm.CreatedAt.IsZero())
+	assert.Equal(t, "tenant", fv.get("logs/" + stdout + "/metadata")["context"])
+
+	// Every other indexed log was backfilled by the same walk of the index.
+	assert.Equal(t, "tenant/workflow", fv.get("logs/" + stderr + "/metadata")["context"])
+	assert.Nil(t, fv.get("logs/"+unindexed+"/metadata"))
+
+	// The index is not walked again, by any replica.
+	lists := fv.listCount()
+
+	lm, err = replica().Get(ctx, stderr)
+	require.NoError(t, err)
+	assert.Equal(t, &model.Log{Context: "tenant/workflow", Name: "stderr"}, lm.Log)
+
+	// A log that is not indexed is recorded as being in no context.
+	lm, err = replica().Get(ctx, unindexed)
+	require.NoError(t, err)
+	assert.Nil(t, lm.Log)
+	assert.False(t, lm.CreatedAt.IsZero())
+	assert.Equal(t, "", fv.get("logs/" + unindexed + "/metadata")["context"])
+
+	for _, id := range []string{stdout, stderr, unindexed} {
+		_, err := replica().Get(ctx, id)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, lists, fv.listCount())
+}
+
+func TestVaultLogMetadataManagerSequence(t *testing.T) {
+	ctx := context.Background()
+	lmm, fv, replica := newVaultLogMetadataManager(t)
+
+	lm, err := lmm.Create(ctx, &model.Log{Context: "tenant", Name: "stdout"}, "")
+	require.NoError(t, err)
+
+	first, err := lmm.ReserveSequence(ctx, lm.LogID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), first)
+
+	first, err = replica().ReserveSequence(ctx, lm.LogID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), first)
+
+	t.Run("conflict", func(t *testing.T) {
+		sequencePath := "logs/" + lm.LogID + "/sequence"
+
+		// A single concurrent reservation is retried after.
+		var once sync.Once
+		fv.setBeforeWrite(func(p string) error {
+			if p == sequencePath {
+				once.Do(func() {
+					fv.put(p, map[string]interface{}{"next": "10"})
+				})
+			}
+
+			return nil
+		})
+
+		first, err := lmm.ReserveSequence(ctx, lm.LogID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), first)
+
+		// Writers that always update the sequence first exhaust the
+		// attempts.
+		fv.setBeforeWrite(func(p string) error {
+			if p == sequencePath {
+				fv.put(p, fv.get(p))
+			}
+
+			return nil
+		})
+
+		_, err = lmm.ReserveSequence(ctx, lm.LogID, 1)
+		assert.Equal(t, manager.ErrConcurrentUpdate, err)
+
+		fv.setBeforeWrite(nil)
+	})
+
+	exitCode := int32(2)
+	sealedAt := time.Now().Add(-time.Minute).UTC()
+
+	seal, err := lmm.Seal(ctx, lm.LogID, &model.LogSeal{SealedAt: sealedAt, ExitCode: &exitCode})
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), seal.LastSequence)
+	assert.True(t, sealedAt.Equal(seal.SealedAt))
+
+	_, err = lmm.ReserveSequence(ctx, lm.LogID, 1)
+	assert.Equal(t, manager.ErrLogSealed, err)
+
+	_, err = replica().Seal(ctx, lm.LogID, &model.LogSeal{SealedAt: time.Now()})
+	assert.Equal(t, manager.ErrLogSealed, err)
+
+	// The seal is read again even when the rest of the metadata is cached.
+	got, err := lmm.Get(ctx, lm.LogID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Seal)
+	assert.Equal(t, int64(10), got.Seal.LastSequence)
+	assert.True(t, sealedAt.Equal(got.Seal.SealedAt))
+	require.NotNil(t, got.Seal.ExitCode)
+	assert.Equal(t, exitCode, *got.Seal.ExitCode)
+}
+
+func TestVaultLogMetadataManagerList(t *testing.T) {
+	ctx := context.Background()
+	lmm, _, _ := newVaultLogMetadataManager(t)
+
+	ids := make(map[model.Log]string)
+	for _, log := range []model.Log{
+		{Context: "tenant", Name: "stdout"},
+		{Context: "tenant", Name: "std/err"},
+		{Context: "tenant/workflow", Name: "stdout"},
+		{Context: "tenant/name", Name: "stdout"},
+		{Context: "tenant%", Name: "stdout"},
+		{Context: "other", Name: "stdout"},
+	} {
+		log := log
+
+		lm, err := lmm.Create(ctx, &log, "")
+		require.NoError(t, err)
+
+		ids[log] = lm.LogID
+	}
+
+	sealed := ids[model.Log{Context: "tenant/workflow", Name: "stdout"}]
+	_, err := lmm.Seal(ctx, sealed, &model.LogSeal{SealedAt: time.Now()})
+	require.NoError(t, err)
+
+	list := func(lms []*model.LogMetadata, err error) []model.Log {
+		require.NoError(t, err)
+
+		var logs []model.Log
+		for _, lm := range lms {
+			assert.Equal(t, ids[*lm.Log], lm.LogID)
+			assert.Empty(t, lm.Key)
+			assert.Equal(t, lm.LogID == sealed, lm.Seal != nil)
+
+			logs = append(logs, *lm.Log)
+		}
+
+		sort.Slice(logs, func(i, j int) bool {
+			if logs[i].Context != logs[j].Context {
+				return logs[i].Context < logs[j].Context
+			}
+
+			return logs[i].Name < logs[j].Name
+		})
+
+		return logs
+	}
+
+	all := func(string) bool { return true }
+
+	assert.Equal(t, []model.Log{
+		{Context: "other", Name: "stdout"},
+		{Context: "tenant", Name: "std/err"},
+		{Context: "tenant", Name: "stdout"},
+		{Context: "tenant%", Name: "stdout"},
+		{Context: "tenant/name", Name: "stdout"},
+		{Context: "tenant/workflow", Name: "stdout"},
+	}, list(lmm.List(ctx, nil, all)))
+
+	assert.Equal(t, []model.Log{
+		{Context: "tenant", Name: "std/err"},
+		{Context: "tenant", Name: "stdout"},
+		{Context: "tenant/name", Name: "stdout"},
+		{Context: "tenant/workflow", Name: "stdout"},
+	}, list(lmm.List(ctx, []string{"tenant"}, all)))
+
+	assert.Equal(t, []model.Log{
+		{Context: "other", Name: "stdout"},
+		{Context: "tenant/name", Name: "stdout"},
+	}, list(lmm.List(ctx, []string{"tenant/name", "other"}, all)))
+
+	assert.Equal(t, []model.Log{
+		{Context: "tenant/workflow", Name: "stdout"},
+	}, list(lmm.List(ctx, nil, func(logContext string) bool { return logContext == "tenant/workflow" })))
+
+	assert.Empty(t, list(lmm.List(ctx, []string{"missing"}, all)))
+
+	assert.Equal(t, []model.Log{
+		{Context: "tenant", Name: "std/err"},
+		{Context: "tenant", Name: "stdout"},
+	}, list(lmm.ListContext(ctx, "tenant")))
+
+	assert.Equal(t, []model.Log{
+		{Context: "tenant/name", Name: "stdout"},
+	}, list(lmm.ListContext(ctx, "tenant/name")))
+
+	assert.Empty(t, list(lmm.ListContext(ctx, "missing")))
+}
+
+func TestVaultLogMetadataManagerDelete(t *testing.T) {
+	ctx := context.Background()
+	lmm, fv, replica := newVaultLogMetadataManager(t)
+
+	log := &model.Log{Context: "tenant", Name: "stdout"}
+
+	lm, err := lmm.Create(ctx, log, "")
+	require.NoError(t, err)
+
+	// Another replica caches the log before it is deleted.
+	other := replica()
+	_, err = other.Get(ctx, lm.LogID)
+	require.NoError(t, err)
+
+	require.NoError(t, lmm.Delete(ctx, lm.LogID))
+
+	for _, m := range []model.LogMetadataManager{lmm, other} {
+		_, err = m.Get(ctx, lm.LogID)
+		assert.Equal(t, manager.ErrLogNotFound, err)
+
+		_, err = m.ReserveSequence(ctx, lm.LogID, 1)
+		assert.Equal(t, manager.ErrLogNotFound, err)
+	}
+
+	lms, err := lmm.List(ctx, nil, func(string) bool { return true })
+	require.NoError(t, err)
+	assert.Empty(t, lms)
+	assert.Nil(t, fv.get("logs/"+lm.LogID+"/encryption_key"))
+	assert.Nil(t, fv.get("logs/"+lm.LogID+"/metadata"))
+
+	deleted, err := lmm.ListDeleted(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{lm.LogID}, deleted)
+
+	require.NoError(t, lmm.Purged(ctx, lm.LogID))
+
+	deleted, err = lmm.ListDeleted(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	// The log may be created again as a new log.
+	recreated, err := other.Create(ctx, log, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, lm.LogID, recreated.LogID)
+
+	t.Run("interrupted", func(t *testing.T) {
+		// A delete that did not get as far as the index leaves behind an
+		// entry for a log whose sequence is a tombstone.
+		fv.put("logs/"+recreated.LogID+"/sequence", map[string]interface{}{"deleted_at": time.Now().Format(time.RFC3339Nano)})
+
+		lms, err := replica().List(ctx, nil, func(string) bool { return true })
+		require.NoError(t, err)
+		assert.Empty(t, lms)
+
+		lm, err := replica().Create(ctx, log, "")
+		require.NoError(t, err)
+		assert.NotEqual(t, recreated.LogID, lm.LogID)
+		assert.Equal(t, encodeForTransfer(t, lm.LogID), fv.get("contexts/tenant/name/stdout/log_id")["value"])
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			lm, err := lmm.Create(ctx, &model.Log{Context: "limit", Name: uuid.New().String()}, "")
+			require.NoError(t, err)
+			require.NoError(t, lmm.Delete(ctx, lm.LogID))
+		}
+
+		deleted, err := lmm.ListDeleted(ctx, 2)
+		require.NoError(t, err)
+		assert.Len(t, deleted, 2)
+	})
+
+	t.Run("deferred", func(t *testing.T) {
+		all, err := lmm.ListDeleted(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+
+		// Deferring the first logs lets a pass reach the ones after them.
+		for _, id := range all[:2] {
+			require.NoError(t, lmm.DeferPurge(ctx, id, time.Now().Add(time.Hour)))
+		}
+
+		deleted, err := replica().ListDeleted(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, all[2:], deleted)
+
+		// Once the deferral has passed, the log is listed again.
+		require.NoError(t, lmm.DeferPurge(ctx, all[0], time.Now().Add(-time.Second)))
+
+		deleted, err = lmm.ListDeleted(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{all[0], all[2]}, deleted)
+
+		// Deferring a log that has since been purged does not record it as
+		// deleted again.
+		require.NoError(t, lmm.Purged(ctx, all[1]))
+		require.NoError(t, lmm.DeferPurge(ctx, all[1], time.Now().Add(time.Hour)))
+		assert.Nil(t, fv.get("deleted_logs/"+all[1]))
+	})
+}
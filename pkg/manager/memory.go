@@ -0,0 +1,211 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/google/wire"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+)
+
+var InMemoryCredentialProviderSet = wire.NewSet(
+	NewInMemoryCredentialManager,
+)
+
+type InMemoryCredentialManager struct {
+	mu       sync.Mutex
+	records  map[string]*credentialRecord
+	children map[string]map[string]struct{}
+}
+
+func (cm *InMemoryCredentialManager) Authenticate(ctx context.Context, token string) (*model.Credential, error) {
+	id, secret, err := parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	record, found := cm.records[id]
+	if !found || !checkTokenSecret(record.tokenHash, secret) {
+		return nil, ErrInvalidToken
+	}
+
+	now := time.Now()
+	if credentialExpired(record.credential, now) {
+		return nil, ErrCredentialExpired
+	}
+
+	for parentID := record.credential.ParentID; parentID != ""; {
+		parent, found := cm.records[parentID]
+		if !found {
+			return nil, ErrInvalidToken
+		}
+
+		if credentialExpired(parent.credential, now) {
+			return nil, ErrCredentialExpired
+		}
+
+		parentID = parent.credential.ParentID
+	}
+
+	return copyCredential(record.credential), nil
+}
+
+func (cm *InMemoryCredentialManager) Children(ctx context.Context, id string) ([]string, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if _, found := cm.records[id]; !found {
+		return nil, ErrCredentialNotFound
+	}
+
+	children := make([]string, 0, len(cm.children[id]))
+	for child := range cm.children[id] {
+		children = append(children, child)
+	}
+
+	return children, nil
+}
+
+func (cm *InMemoryCredentialManager) Create(ctx context.Context, credential *model.Credential) (*model.CredentialMetadata, error) {
+	c := copyCredential(credential)
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+
+	token, hash, err := newToken(c.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if _, found := cm.records[c.ID]; found {
+		return nil, ErrCredentialExists
+	}
+
+	if c.ParentID != "" {
+		parent, found := cm.records[c.ParentID]
+		if !found {
+			return nil, ErrCredentialNotFound
+		}
+
+		if credentialExpired(parent.credential, time.Now()) {
+			return nil, ErrCredentialExpired
+		}
+
+		if cm.children[c.ParentID] == nil {
+			cm.children[c.ParentID] = make(map[string]struct{})
+		}
+		cm.children[c.ParentID][c.ID] = struct{}{}
+	}
+
+	cm.records[c.ID] = &credentialRecord{
+		credential: c,
+		tokenHash:  hash,
+	}
+
+	return &model.CredentialMetadata{
+		Credential: copyCredential(c),
+		Token:      token,
+	}, nil
+}
+
+func (cm *InMemoryCredentialManager) Get(ctx context.Context, id string) (*model.Credential, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	record, found := cm.records[id]
+	if !found {
+		return nil, ErrCredentialNotFound
+	}
+
+	return copyCredential(record.credential), nil
+}
+
+func (cm *InMemoryCredentialManager) ListExpired(ctx context.Context, now time.Time, limit int) ([]*model.Credential, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	var expired []*model.Credential
+	for _, record := range cm.records {
+		if len(expired) >= limit {
+			break
+		}
+
+		if credentialExpired(record.credential, now) {
+			expired = append(expired, copyCredential(record.credential))
+		}
+	}
+
+	return expired, nil
+}
+
+func (cm *InMemoryCredentialManager) Refresh(ctx context.Context, id string, expiresAt time.Time) (*model.CredentialMetadata, error) {
+	token, hash, err := newToken(id)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	record, found := cm.records[id]
+	if !found {
+		return nil, ErrCredentialNotFound
+	}
+
+	record.credential.ExpiresAt = expiresAt
+	record.tokenHash = hash
+
+	return &model.CredentialMetadata{
+		Credential: copyCredential(record.credential),
+		Token:      token,
+	}, nil
+}
+
+func (cm *InMemoryCredentialManager) Revoke(ctx context.Context, id string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	record, found := cm.records[id]
+	if !found {
+		return ErrCredentialNotFound
+	}
+
+	cm.revokeTree(id)
+
+	if record.credential.ParentID != "" {
+		delete(cm.children[record.credential.ParentID], id)
+	}
+
+	return nil
+}
+
+func (cm *InMemoryCredentialManager) revokeTree(id string) {
+	for child := range cm.children[id] {
+		cm.revokeTree(child)
+	}
+
+	delete(cm.children, id)
+	delete(cm.records, id)
+}
+
+func copyCredential(credential *model.Credential) *model.Credential {
+	c := *credential
+	c.Contexts = append([]string(nil), credential.Contexts...)
+	c.Scopes = append([]string(nil), credential.Scopes...)
+	return &c
+}
+
+func NewInMemoryCredentialManager() model.CredentialManager {
+	return &InMemoryCredentialManager{
+		records:  make(map[string]*credentialRecord),
+		children: make(map[string]map[string]struct{}),
+	}
+}
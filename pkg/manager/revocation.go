@@ -0,0 +1,273 @@
+package manager
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/hashicorp/vault/api"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/util/vaultutil"
+)
+
+var VaultRevocationProviderSet = wire.NewSet(
+	NewVaultRevocationManager,
+	NewRevocationList,
+)
+
+var InMemoryRevocationProviderSet = wire.NewSet(
+	NewInMemoryRevocationManager,
+	NewRevocationList,
+)
+
+// VaultRevocationManager keeps every revocation in a single versioned secret,
+// so that replicas synchronizing their revocation lists read one secret
+// rather than one per revocation.
+type VaultRevocationManager struct {
+	client      *api.Client
+	engineMount string
+}
+
+// Add records a revocation, removing any that have expired at the same time.
+func (rm *VaultRevocationManager) Add(ctx context.Context, revocation *model.Revocation) error {
+	return updateSecretData(ctx, rm.client, rm.path(), func(data map[string]interface{}) (map[string]interface{}, error) {
+		revocations, err := decodeRevocations(data, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		revocations = append(revocations, revocation)
+
+		update := make(map[string]interface{}, len(revocations))
+		for _, r := range revocations {
+			entry := map[string]interface{}{
+				"not_before": formatTime(r.NotBefore),
+				"expires_at": formatTime(r.ExpiresAt),
+			}
+			if r.Descendants {
+				entry["descendants"] = true
+			}
+
+			update[r.CredentialID] = entry
+		}
+
+		return update, nil
+	})
+}
+
+// List returns the revocations that are still in effect. Expired revocations
+// are removed from the store by the next Add.
+func (rm *VaultRevocationManager) List(ctx context.Context) ([]*model.Revocation, error) {
+	data, _, err := readSecretData(ctx, rm.client, rm.path())
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeRevocations(data, time.Now())
+}
+
+func (rm *VaultRevocationManager) path() string {
+	return dataPath(rm.engineMount, "revocation_list")
+}
+
+// decodeRevocations decodes the revocations stored in the revocation list
+// secret, leaving out those expired by now.
+func decodeRevocations(data map[string]interface{}, now time.Time) ([]*model.Revocation, error) {
+	revocations := make([]*model.Revocation, 0, len(data))
+	for id, value := range data {
+		entry, _ := value.(map[string]interface{})
+
+		notBefore, err := timeValue(entry, "not_before")
+		if err != nil {
+			return nil, err
+		}
+
+		expiresAt, err := timeValue(entry, "expires_at")
+		if err != nil {
+			return nil, err
+		}
+
+		if !expiresAt.IsZero() && !now.Before(expiresAt) {
+			continue
+		}
+
+		descendants, _ := entry["descendants"].(bool)
+
+		revocations = append(revocations, &model.Revocation{
+			CredentialID: id,
+			NotBefore:    notBefore,
+			ExpiresAt:    expiresAt,
+			Descendants:  descendants,
+		})
+	}
+
+	return revocations, nil
+}
+
+func NewVaultRevocationManager(cfg *opt.Config, vaultClient *api.Client) (model.RevocationManager, error) {
+	vaultEngineMount, err := vaultutil.CheckNormalizeEngineMount(vaultClient, cfg.VaultEngineMount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VaultRevocationManager{
+		client:      vaultClient,
+		engineMount: vaultEngineMount,
+	}, nil
+}
+
+type InMemoryRevocationManager struct {
+	mu          sync.Mutex
+	revocations map[string]model.Revocation
+}
+
+func (rm *InMemoryRevocationManager) Add(ctx context.Context, revocation *model.Revocation) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.revocations[revocation.CredentialID] = *revocation
+
+	return nil
+}
+
+func (rm *InMemoryRevocationManager) List(ctx context.Context) ([]*model.Revocation, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	now := time.Now()
+
+	revocations := make([]*model.Revocation, 0, len(rm.revocations))
+	for id, revocation := range rm.revocations {
+		if !revocation.ExpiresAt.IsZero() && !now.Before(revocation.ExpiresAt) {
+			delete(rm.revocations, id)
+			continue
+		}
+
+		r := revocation
+		revocations = append(revocations, &r)
+	}
+
+	return revocations, nil
+}
+
+func NewInMemoryRevocationManager() model.RevocationManager {
+	return &InMemoryRevocationManager{
+		revocations: make(map[string]model.Revocation),
+	}
+}
+
+// RevocationList is a local copy of the revocations in a
+// model.RevocationManager. It is kept in sync in the background so that
+// tokens can be checked against it without a lookup in the store.
+type RevocationList struct {
+	revocationManager model.RevocationManager
+
+	mu          sync.RWMutex
+	revocations map[string]*model.Revocation
+}
+
+// Add records a revocation in the store and applies it to the local copy
+// immediately.
+func (rl *RevocationList) Add(ctx context.Context, revocation *model.Revocation) error {
+	if err := rl.revocationManager.Add(ctx, revocation); err != nil {
+		return err
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.revocations[revocation.CredentialID] = revocation
+
+	return nil
+}
+
+// Revoked determines whether a token for the given credential issued at the
+// given time has been revoked.
+func (rl *RevocationList) Revoked(id string, issuedAt time.Time) bool {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	revocation, found := rl.revocations[id]
+	if !found {
+		return false
+	}
+
+	return revocation.NotBefore.IsZero() || issuedAt.Before(revocation.NotBefore)
+}
+
+// RevokedDescendants determines whether the tokens of the given credential's
+// children issued at the given time have been revoked along with the
+// credential. Revocations without a NotBefore time, which earlier versions
+// recorded for every revoked credential, revoke all of them.
+func (rl *RevocationList) RevokedDescendants(id string, issuedAt time.Time) bool {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	revocation, found := rl.revocations[id]
+	if !found {
+		return false
+	}
+
+	return revocation.NotBefore.IsZero() || revocation.Descendants && issuedAt.Before(revocation.NotBefore)
+}
+
+// Sync replaces the local copy of the revocations with those in the store.
+func (rl *RevocationList) Sync(ctx context.Context) error {
+	list, err := rl.revocationManager.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	revocations := make(map[string]*model.Revocation, len(list))
+	for _, revocation := range list {
+		revocations[revocation.CredentialID] = revocation
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.revocations = revocations
+
+	return nil
+}
+
+func (rl *RevocationList) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// On failure the previous copy is kept until the next attempt.
+			if err := rl.Sync(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to synchronize credential revocations: %v", err)
+			}
+		}
+	}
+}
+
+func NewRevocationList(ctx context.Context, cfg *opt.Config, revocationManager model.RevocationManager) (*RevocationList, func(), error) {
+	rl := &RevocationList{
+		revocationManager: revocationManager,
+		revocations:       make(map[string]*model.Revocation),
+	}
+
+	if err := rl.Sync(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	interval := cfg.CredentialRevocationSyncInterval
+	if interval <= 0 {
+		interval = opt.DefaultCredentialRevocationSyncInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go rl.run(ctx, interval)
+
+	return rl, cancel, nil
+}
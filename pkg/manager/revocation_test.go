@@ -0,0 +1,119 @@
+package manager_test
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func revokedIDs(t *testing.T, rm model.RevocationManager) []string {
+	revocations, err := rm.List(context.Background())
+	require.NoError(t, err)
+
+	var ids []string
+	for _, revocation := range revocations {
+		ids = append(ids, revocation.CredentialID)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+func TestVaultRevocationManager(t *testing.T) {
+	ctx := context.Background()
+	fv, cfg, client := newFakeVault(t)
+
+	rm, err := manager.NewVaultRevocationManager(cfg, client)
+	require.NoError(t, err)
+
+	assert.Empty(t, revokedIDs(t, rm))
+
+	now := time.Now()
+
+	require.NoError(t, rm.Add(ctx, &model.Revocation{CredentialID: "revoked"}))
+	require.NoError(t, rm.Add(ctx, &model.Revocation{
+		CredentialID: "refreshed",
+		NotBefore:    now,
+		ExpiresAt:    now.Add(time.Hour),
+	}))
+	require.NoError(t, rm.Add(ctx, &model.Revocation{
+		CredentialID: "expired",
+		ExpiresAt:    now.Add(-time.Hour),
+	}))
+
+	assert.Equal(t, []string{"refreshed", "revoked"}, revokedIDs(t, rm))
+
+	// The expired revocation is still stored until the next addition.
+	assert.Contains(t, fv.get("revocation_list"), "expired")
+
+	require.NoError(t, rm.Add(ctx, &model.Revocation{CredentialID: "another"}))
+	assert.NotContains(t, fv.get("revocation_list"), "expired")
+	assert.Equal(t, []string{"another", "refreshed", "revoked"}, revokedIDs(t, rm))
+}
+
+func TestVaultRevocationManagerConflict(t *testing.T) {
+	ctx := context.Background()
+	fv, cfg, client := newFakeVault(t)
+
+	rm, err := manager.NewVaultRevocationManager(cfg, client)
+	require.NoError(t, err)
+
+	replica, err := manager.NewVaultRevocationManager(cfg, client)
+	require.NoError(t, err)
+
+	// A revocation added by another replica between reading and writing the
+	// list is kept.
+	var raced int32
+	fv.setBeforeWrite(func(p string) error {
+		if p == "revocation_list" && atomic.CompareAndSwapInt32(&raced, 0, 1) {
+			return replica.Add(ctx, &model.Revocation{CredentialID: "concurrent"})
+		}
+
+		return nil
+	})
+
+	require.NoError(t, rm.Add(ctx, &model.Revocation{CredentialID: "revoked"}))
+	assert.Equal(t, []string{"concurrent", "revoked"}, revokedIDs(t, rm))
+}
+
+func TestRevocationList(t *testing.T) {
+	ctx := context.Background()
+	_, cfg, client := newFakeVault(t)
+
+	rm, err := manager.NewVaultRevocationManager(cfg, client)
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, rm.Add(ctx, &model.Revocation{CredentialID: "revoked"}))
+
+	rl, cancel, err := manager.NewRevocationList(ctx, cfg, rm)
+	require.NoError(t, err)
+	defer cancel()
+
+	assert.True(t, rl.Revoked("revoked", now))
+	assert.True(t, rl.RevokedDescendants("revoked", now))
+
+	require.NoError(t, rl.Add(ctx, &model.Revocation{CredentialID: "refreshed", NotBefore: now}))
+	assert.True(t, rl.Revoked("refreshed", now.Add(-time.Second)))
+	assert.False(t, rl.Revoked("refreshed", now.Add(time.Second)))
+	assert.False(t, rl.RevokedDescendants("refreshed", now.Add(-time.Second)))
+
+	require.NoError(t, rl.Add(ctx, &model.Revocation{CredentialID: "removed", NotBefore: now, Descendants: true}))
+	assert.True(t, rl.RevokedDescendants("removed", now.Add(-time.Second)))
+	assert.False(t, rl.RevokedDescendants("removed", now.Add(time.Second)))
+
+	// Revocations added by another replica are picked up by a sync.
+	require.NoError(t, rm.Add(ctx, &model.Revocation{CredentialID: "elsewhere"}))
+	assert.False(t, rl.Revoked("elsewhere", now))
+
+	require.NoError(t, rl.Sync(ctx))
+	assert.True(t, rl.Revoked("elsewhere", now))
+}
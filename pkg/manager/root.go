@@ -0,0 +1,135 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/hashicorp/vault/api"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/util/vaultutil"
+)
+
+// RootCredentialID is the identifier of the credential from which all other
+// credentials are issued.
+const RootCredentialID = "root"
+
+var VaultRootCredentialProviderSet = wire.NewSet(
+	NewVaultRootCredentialManager,
+)
+
+var InMemoryRootCredentialProviderSet = wire.NewSet(
+	NewInMemoryRootCredentialManager,
+)
+
+type rootTokenWriter func(ctx context.Context, token string) error
+
+// RootCredentialManager creates and rotates the root credential. Its token
+// is only ever handed to the configured destination.
+type RootCredentialManager struct {
+	credentialManager model.CredentialManager
+	write             rootTokenWriter
+}
+
+// Bootstrap creates the root credential if it does not exist yet and writes
+// its token. A destination for the token is only required to create it; if
+// none is configured and there is no root credential yet, nobody could ever
+// obtain a token, so Bootstrap fails.
+func (rcm *RootCredentialManager) Bootstrap(ctx context.Context) (bool, error) {
+	if rcm.write == nil {
+		_, err := rcm.credentialManager.Get(ctx, RootCredentialID)
+		if err == ErrCredentialNotFound {
+			return false, ErrRootTokenDestinationRequired
+		}
+
+		return false, err
+	}
+
+	cm, err := rcm.credentialManager.Create(ctx, &model.Credential{
+		ID:       RootCredentialID,
+		Contexts: []string{model.WildcardContext},
+		Scopes:   model.Scopes,
+	})
+	if err == ErrCredentialExists {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := rcm.write(ctx, cm.Token); err != nil {
+		// Nobody can ever use a root credential whose token was not written,
+		// so remove it to try again on the next start.
+		if rerr := rcm.credentialManager.Revoke(ctx, RootCredentialID); rerr != nil {
+			return false, rerr
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Rotate replaces the token of the root credential, invalidating the
+// previous one, and writes the new token.
+func (rcm *RootCredentialManager) Rotate(ctx context.Context) error {
+	if rcm.write == nil {
+		return ErrRootTokenDestinationRequired
+	}
+
+	cm, err := rcm.credentialManager.Refresh(ctx, RootCredentialID, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	return rcm.write(ctx, cm.Token)
+}
+
+func fileRootTokenWriter(name string) rootTokenWriter {
+	return func(ctx context.Context, token string) error {
+		return os.WriteFile(name, []byte(token+"\n"), 0600)
+	}
+}
+
+func vaultRootTokenWriter(client *api.Client, dataPath string) rootTokenWriter {
+	return func(ctx context.Context, token string) error {
+		return writeSecretData(client, dataPath, map[string]interface{}{"token": token}, -1)
+	}
+}
+
+// NewVaultRootCredentialManager creates a root credential manager that writes
+// the root token to a path in the Vault engine mount if configured, falling
+// back to a file.
+func NewVaultRootCredentialManager(cfg *opt.Config, vaultClient *api.Client, credentialManager model.CredentialManager) (*RootCredentialManager, error) {
+	rcm := &RootCredentialManager{
+		credentialManager: credentialManager,
+	}
+
+	if cfg.RootTokenVaultPath != "" {
+		vaultEngineMount, err := vaultutil.CheckNormalizeEngineMount(vaultClient, cfg.VaultEngineMount)
+		if err != nil {
+			return nil, err
+		}
+
+		rcm.write = vaultRootTokenWriter(vaultClient, dataPath(vaultEngineMount, cfg.RootTokenVaultPath))
+	} else if cfg.RootTokenFile != "" {
+		rcm.write = fileRootTokenWriter(cfg.RootTokenFile)
+	}
+
+	return rcm, nil
+}
+
+// NewInMemoryRootCredentialManager creates a root credential manager that
+// writes the root token to a file if configured.
+func NewInMemoryRootCredentialManager(cfg *opt.Config, credentialManager model.CredentialManager) *RootCredentialManager {
+	rcm := &RootCredentialManager{
+		credentialManager: credentialManager,
+	}
+
+	if cfg.RootTokenFile != "" {
+		rcm.write = fileRootTokenWriter(cfg.RootTokenFile)
+	}
+
+	return rcm
+}
@@ -0,0 +1,373 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/tink/go/insecurecleartextkeyset"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/signature"
+	"github.com/google/tink/go/tink"
+	"github.com/google/wire"
+	"github.com/hashicorp/vault/api"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/util/vaultutil"
+)
+
+const (
+	// signedTokenPrefix distinguishes signed tokens from opaque tokens, whose
+	// first segment is always a credential identifier.
+	signedTokenPrefix = "s1" + tokenSeparator
+)
+
+var VaultSignedCredentialProviderSet = wire.NewSet(
+	VaultRevocationProviderSet,
+	NewVaultSigningKeyset,
+	NewSignedCredentialManager,
+)
+
+var InMemorySignedCredentialProviderSet = wire.NewSet(
+	InMemoryRevocationProviderSet,
+	NewInMemorySigningKeyset,
+	NewSignedCredentialManager,
+)
+
+type signedTokenClaims struct {
+	ID        string   `json:"id"`
+	ParentID  string   `json:"parent_id,omitempty"`
+	Contexts  []string `json:"contexts"`
+	Scopes    []string `json:"scopes,omitempty"`
+	ExpiresAt int64    `json:"expires_at,omitempty"`
+	IssuedAt  int64    `json:"issued_at"`
+}
+
+// SignedCredentialManager issues tokens that carry their credential and are
+// signed, so that they can be verified without a lookup in the credential
+// store. Revocations are checked against a RevocationList instead.
+//
+// All other operations, as well as the authentication of opaque tokens, are
+// delegated to the underlying credential manager.
+type SignedCredentialManager struct {
+	delegate       model.CredentialManager
+	revocationList *RevocationList
+	signer         tink.Signer
+	verifier       tink.Verifier
+	maxTTL         time.Duration
+}
+
+func (cm *SignedCredentialManager) Authenticate(ctx context.Context, token string) (*model.Credential, error) {
+	if !strings.HasPrefix(token, signedTokenPrefix) {
+		return cm.delegate.Authenticate(ctx, token)
+	}
+
+	claims, err := cm.verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	credential := &model.Credential{
+		ID:       claims.ID,
+		ParentID: claims.ParentID,
+		Contexts: claims.Contexts,
+		Scopes:   claims.Scopes,
+	}
+	if claims.ExpiresAt != 0 {
+		credential.ExpiresAt = time.Unix(0, claims.ExpiresAt).UTC()
+	}
+
+	if credentialExpired(credential, time.Now()) {
+		return nil, ErrCredentialExpired
+	}
+
+	// Revoking a credential also revokes each of its descendants, so only the
+	// credential and its parent have to be checked here.
+	issuedAt := time.Unix(0, claims.IssuedAt)
+	if cm.revocationList.Revoked(credential.ID, issuedAt) ||
+		(credential.ParentID != "" && cm.revocationList.RevokedDescendants(credential.ParentID, issuedAt)) {
+		return nil, ErrInvalidToken
+	}
+
+	return credential, nil
+}
+
+func (cm *SignedCredentialManager) Children(ctx context.Context, id string) ([]string, error) {
+	return cm.delegate.Children(ctx, id)
+}
+
+func (cm *SignedCredentialManager) Create(ctx context.Context, credential *model.Credential) (*model.CredentialMetadata, error) {
+	created, err := cm.delegate.Create(ctx, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := cm.sign(created.Credential, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CredentialMetadata{
+		Credential: created.Credential,
+		Token:      token,
+	}, nil
+}
+
+func (cm *SignedCredentialManager) Get(ctx context.Context, id string) (*model.Credential, error) {
+	return cm.delegate.Get(ctx, id)
+}
+
+func (cm *SignedCredentialManager) ListExpired(ctx context.Context, now time.Time, limit int) ([]*model.Credential, error) {
+	return cm.delegate.ListExpired(ctx, now, limit)
+}
+
+// Refresh issues a new token for the credential and revokes all tokens issued
+// for it before now.
+func (cm *SignedCredentialManager) Refresh(ctx context.Context, id string, expiresAt time.Time) (*model.CredentialMetadata, error) {
+	credential, err := cm.delegate.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	refreshed, err := cm.delegate.Refresh(ctx, id, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	revocation := &model.Revocation{
+		CredentialID: id,
+		NotBefore:    now,
+		ExpiresAt:    cm.revocationExpiry(credential, now),
+	}
+
+	if err := cm.revocationList.Add(ctx, revocation); err != nil {
+		return nil, err
+	}
+
+	token, err := cm.sign(refreshed.Credential, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CredentialMetadata{
+		Credential: refreshed.Credential,
+		Token:      token,
+	}, nil
+}
+
+// Revoke revokes the tokens of a credential and all of its descendants before
+// removing them from the credential store.
+func (cm *SignedCredentialManager) Revoke(ctx context.Context, id string) error {
+	if err := cm.revokeTree(ctx, id, time.Now()); err != nil {
+		return err
+	}
+
+	return cm.delegate.Revoke(ctx, id)
+}
+
+func (cm *SignedCredentialManager) revokeTree(ctx context.Context, id string, now time.Time) error {
+	credential, err := cm.delegate.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// The tokens of an expired credential are already rejected, so revoking
+	// them would only grow the revocation list. This is the common case for
+	// the credential sweeper. Descendants may outlive it, so they are still
+	// checked.
+	//
+	// Only tokens issued up to now are revoked. A credential created again
+	// with the same identifier, as the root credential is, issues valid
+	// tokens once more.
+	if !credentialExpired(credential, now) {
+		revocation := &model.Revocation{
+			CredentialID: id,
+			NotBefore:    now,
+			ExpiresAt:    cm.revocationExpiry(credential, now),
+			Descendants:  true,
+		}
+
+		if err := cm.revocationList.Add(ctx, revocation); err != nil {
+			return err
+		}
+	}
+
+	children, err := cm.delegate.Children(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if err := cm.revokeTree(ctx, child, now); err != nil && err != ErrCredentialNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// revocationExpiry determines how long a revocation must be kept so that it
+// outlives every token issued for the credential up to now.
+func (cm *SignedCredentialManager) revocationExpiry(credential *model.Credential, now time.Time) time.Time {
+	if credential.ExpiresAt.IsZero() {
+		return time.Time{}
+	}
+
+	expiresAt := now.Add(cm.maxTTL)
+	if credential.ExpiresAt.After(expiresAt) {
+		expiresAt = credential.ExpiresAt
+	}
+
+	return expiresAt
+}
+
+func (cm *SignedCredentialManager) sign(credential *model.Credential, issuedAt time.Time) (string, error) {
+	claims := &signedTokenClaims{
+		ID:       credential.ID,
+		ParentID: credential.ParentID,
+		Contexts: credential.Contexts,
+		Scopes:   credential.Scopes,
+		IssuedAt: issuedAt.UnixNano(),
+	}
+	if !credential.ExpiresAt.IsZero() {
+		claims.ExpiresAt = credential.ExpiresAt.UnixNano()
+	}
+
+	b, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	payload := signedTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	sig, err := cm.signer.Sign([]byte(payload))
+	if err != nil {
+		return "", err
+	}
+
+	return payload + tokenSeparator + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func (cm *SignedCredentialManager) verify(token string) (*signedTokenClaims, error) {
+	i := strings.LastIndex(token, tokenSeparator)
+	if i < len(signedTokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	payload := token[:i]
+
+	sig, err := base64.RawURLEncoding.DecodeString(token[i+len(tokenSeparator):])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := cm.verifier.Verify(sig, []byte(payload)); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(payload, signedTokenPrefix))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims := &signedTokenClaims{}
+	if err := json.Unmarshal(b, claims); err != nil || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func NewSignedCredentialManager(cfg *opt.Config, credentialManager model.CredentialManager, revocationList *RevocationList, kh *keyset.Handle) (*SignedCredentialManager, error) {
+	signer, err := signature.NewSigner(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := kh.Public()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := signature.NewVerifier(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedCredentialManager{
+		delegate:       credentialManager,
+		revocationList: revocationList,
+		signer:         signer,
+		verifier:       verifier,
+		maxTTL:         cfg.CredentialMaxTTL,
+	}, nil
+}
+
+// NewVaultSigningKeyset loads the keyset used to sign tokens from Vault. The
+// keyset is generated if it does not exist yet, in which case every replica
+// converges on whichever keyset was written first.
+func NewVaultSigningKeyset(ctx context.Context, cfg *opt.Config, vaultClient *api.Client) (*keyset.Handle, error) {
+	vaultEngineMount, err := vaultutil.CheckNormalizeEngineMount(vaultClient, cfg.VaultEngineMount)
+	if err != nil {
+		return nil, err
+	}
+
+	keysetPath := dataPath(vaultEngineMount, "tokens", "keyset")
+
+	for {
+		data, _, err := readSecretData(ctx, vaultClient, keysetPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if data != nil {
+			return readSigningKeyset(stringValue(data, "keyset"))
+		}
+
+		kh, err := keyset.NewHandle(signature.ECDSAP256KeyTemplate())
+		if err != nil {
+			return nil, err
+		}
+
+		encoded, err := writeSigningKeyset(kh)
+		if err != nil {
+			return nil, err
+		}
+
+		err = writeSecretData(vaultClient, keysetPath, map[string]interface{}{"keyset": encoded}, 0)
+		if err == nil {
+			return kh, nil
+		} else if !isCheckAndSetError(err) {
+			return nil, err
+		}
+	}
+}
+
+// NewInMemorySigningKeyset generates a keyset used to sign tokens that is
+// only valid for the lifetime of the process.
+func NewInMemorySigningKeyset() (*keyset.Handle, error) {
+	return keyset.NewHandle(signature.ECDSAP256KeyTemplate())
+}
+
+func readSigningKeyset(encoded string) (*keyset.Handle, error) {
+	b, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return insecurecleartextkeyset.Read(keyset.NewBinaryReader(bytes.NewBuffer(b)))
+}
+
+func writeSigningKeyset(kh *keyset.Handle) (string, error) {
+	var buf bytes.Buffer
+	if err := insecurecleartextkeyset.Write(kh, keyset.NewBinaryWriter(&buf)); err != nil {
+		return "", err
+	}
+
+	return base64.RawStdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,137 @@
+package manager
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const credentialSweepLease = "credential-sweep"
+
+var VaultCredentialSweeperProviderSet = wire.NewSet(
+	NewVaultLeaseManager,
+	NewCredentialSweeper,
+)
+
+var InMemoryCredentialSweeperProviderSet = wire.NewSet(
+	NewInMemoryLeaseManager,
+	NewCredentialSweeper,
+)
+
+// CredentialSweeper periodically deletes expired credentials along with their
+// descendants. Only the replica holding the sweep lease deletes credentials
+// in any given pass.
+type CredentialSweeper struct {
+	credentialManager model.CredentialManager
+	leaseManager      model.LeaseManager
+	meter             *metric.Meter
+	interval          time.Duration
+	batchSize         int
+}
+
+// Sweep deletes up to the configured batch size of expired credentials,
+// returning the number deleted.
+func (cs *CredentialSweeper) Sweep(ctx context.Context) (int, error) {
+	// The lease outlives the interval so that a slow pass is not interrupted
+	// by another replica.
+	held, err := cs.leaseManager.Acquire(ctx, credentialSweepLease, 2*cs.interval)
+	if err != nil || !held {
+		return 0, err
+	}
+
+	expired, err := cs.credentialManager.ListExpired(ctx, time.Now(), cs.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	swept := 0
+	for _, credential := range expired {
+		// Descendants are revoked with their ancestors, so they may already
+		// be gone.
+		err := cs.credentialManager.Revoke(ctx, credential.ID)
+		if err == ErrCredentialNotFound {
+			continue
+		} else if err != nil {
+			return swept, err
+		}
+
+		swept++
+	}
+
+	return swept, nil
+}
+
+func (cs *CredentialSweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(cs.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			swept, err := cs.Sweep(ctx)
+			cs.countOutcomeMetric(ctx, model.MetricCredentialSweep, err)
+			cs.countMetric(ctx, model.MetricCredentialSweepExpired, int64(swept))
+			if err != nil && ctx.Err() == nil {
+				log.Printf("failed to sweep expired credentials: %v", err)
+			}
+		}
+	}
+}
+
+func (cs *CredentialSweeper) countOutcomeMetric(ctx context.Context, name string, err error) {
+	attrs := []attribute.KeyValue{
+		attribute.String(model.MetricLabelOutcome, model.MetricValueSuccess),
+	}
+	if err != nil {
+		attrs = []attribute.KeyValue{
+			attribute.String(model.MetricLabelOutcome, model.MetricValueFailed),
+		}
+	}
+
+	cs.countMetric(ctx, name, 1, attrs...)
+}
+
+func (cs *CredentialSweeper) countMetric(ctx context.Context, name string, n int64, additionalAttrs ...attribute.KeyValue) {
+	if cs.meter == nil {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String(model.MetricLabelModule, "credential-sweeper"),
+	}
+	attrs = append(attrs, additionalAttrs...)
+
+	counter := metric.Must(*cs.meter).NewInt64Counter(name)
+	counter.Add(ctx, n, attrs...)
+}
+
+func NewCredentialSweeper(ctx context.Context, cfg *opt.Config, credentialManager model.CredentialManager, leaseManager model.LeaseManager, meter *metric.Meter) (*CredentialSweeper, func(), error) {
+	cs := &CredentialSweeper{
+		credentialManager: credentialManager,
+		leaseManager:      leaseManager,
+		meter:             meter,
+		interval:          cfg.CredentialSweepInterval,
+		batchSize:         cfg.CredentialSweepBatchSize,
+	}
+
+	if cs.interval <= 0 {
+		cs.interval = opt.DefaultCredentialSweepInterval
+	}
+
+	if cs.batchSize <= 0 {
+		cs.batchSize = opt.DefaultCredentialSweepBatchSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go cs.run(ctx)
+
+	return cs, cancel, nil
+}
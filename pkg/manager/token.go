@@ -0,0 +1,45 @@
+package manager
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+)
+
+const (
+	tokenSecretSize = 32
+	tokenSeparator  = "."
+)
+
+// newToken generates an opaque token for the given credential. Only the hash
+// of the token secret should ever be persisted.
+func newToken(id string) (token string, hash string, err error) {
+	b := make([]byte, tokenSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	return id + tokenSeparator + secret, hashTokenSecret(secret), nil
+}
+
+func parseToken(token string) (id string, secret string, err error) {
+	parts := strings.SplitN(token, tokenSeparator, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", ErrInvalidToken
+	}
+
+	return parts[0], parts[1], nil
+}
+
+func hashTokenSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+func checkTokenSecret(hash, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(hashTokenSecret(secret))) == 1
+}
@@ -0,0 +1,206 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/puppetlabs/leg/timeutil/pkg/retry"
+)
+
+// readSecretData reads the current version of a KV v2 secret, returning its
+// data and version. A secret that does not exist (or whose current version
+// has been deleted) is reported as nil data with no error.
+func readSecretData(ctx context.Context, client *api.Client, dataPath string) (map[string]interface{}, int, error) {
+	var secret *api.Secret
+	err := retry.Wait(ctx, func(ctx context.Context) (bool, error) {
+		var verr error
+		secret, verr = client.Logical().Read(dataPath)
+		if verr != nil {
+			return false, verr
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if secret == nil || secret.Data == nil {
+		return nil, 0, nil
+	}
+
+	data, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		return nil, 0, nil
+	}
+
+	version := 0
+	if metadata, ok := secret.Data["metadata"].(map[string]interface{}); ok {
+		if v, ok := metadata["version"].(json.Number); ok {
+			n, _ := v.Int64()
+			version = int(n)
+		}
+	}
+
+	return data, version, nil
+}
+
+// listSecrets lists the keys under a KV v2 metadata path. Keys ending in a
+// slash are folders.
+func listSecrets(ctx context.Context, client *api.Client, metadataPath string) ([]string, error) {
+	var secret *api.Secret
+	err := retry.Wait(ctx, func(ctx context.Context) (bool, error) {
+		var verr error
+		secret, verr = client.Logical().List(metadataPath)
+		if verr != nil {
+			return false, verr
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == nil || secret.Data == nil {
+		return nil, nil
+	}
+
+	return stringsValue(secret.Data, "keys"), nil
+}
+
+// writeSecretData writes a new version of a KV v2 secret. If cas is
+// non-negative, the write only succeeds if the current version of the secret
+// matches it (0 meaning the secret must not exist).
+func writeSecretData(client *api.Client, dataPath string, data map[string]interface{}, cas int) error {
+	payload := map[string]interface{}{
+		"data": data,
+	}
+
+	if cas >= 0 {
+		payload["options"] = map[string]interface{}{
+			"cas": cas,
+		}
+	}
+
+	_, err := client.Logical().Write(dataPath, payload)
+	return err
+}
+
+const (
+	// casMaxAttempts bounds the attempts of updateSecretData to write a
+	// secret that other writers keep updating first.
+	casMaxAttempts = 8
+
+	// casBaseBackoff is the longest wait before the first retry of
+	// updateSecretData. It doubles with each retry, and the actual wait is
+	// chosen at random below it so that contending writers spread out.
+	casBaseBackoff = 10 * time.Millisecond
+)
+
+// updateSecretData reads the current data of a KV v2 secret, nil if it does
+// not exist, and writes the data returned by fn with check-and-set. If
+// another writer updates the secret first, it retries with jittered backoff,
+// giving up with ErrConcurrentUpdate. Nothing is written if fn returns nil
+// data.
+func updateSecretData(ctx context.Context, client *api.Client, dataPath string, fn func(data map[string]interface{}) (map[string]interface{}, error)) error {
+	backoff := casBaseBackoff
+
+	for attempt := 1; ; attempt++ {
+		data, version, err := readSecretData(ctx, client, dataPath)
+		if err != nil {
+			return err
+		}
+
+		update, err := fn(data)
+		if err != nil || update == nil {
+			return err
+		}
+
+		err = writeSecretData(client, dataPath, update, version)
+		if !isCheckAndSetError(err) {
+			return err
+		} else if attempt == casMaxAttempts {
+			return ErrConcurrentUpdate
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(rand.Int63n(int64(backoff))) + 1):
+		}
+
+		backoff *= 2
+	}
+}
+
+// deleteSecret permanently removes all versions of a KV v2 secret.
+func deleteSecret(client *api.Client, metadataPath string) error {
+	_, err := client.Logical().Delete(metadataPath)
+	return err
+}
+
+func isCheckAndSetError(err error) bool {
+	var rerr *api.ResponseError
+	if !errors.As(err, &rerr) || rerr.StatusCode != http.StatusBadRequest {
+		return false
+	}
+
+	for _, e := range rerr.Errors {
+		if strings.Contains(e, "check-and-set") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func dataPath(engineMount string, elem ...string) string {
+	return path.Join(append([]string{engineMount, "data"}, elem...)...)
+}
+
+func metadataPath(engineMount string, elem ...string) string {
+	return path.Join(append([]string{engineMount, "metadata"}, elem...)...)
+}
+
+func stringValue(data map[string]interface{}, key string) string {
+	s, _ := data[key].(string)
+	return s
+}
+
+func stringsValue(data map[string]interface{}, key string) []string {
+	values, _ := data[key].([]interface{})
+
+	r := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			r = append(r, s)
+		}
+	}
+
+	return r
+}
+
+func timeValue(data map[string]interface{}, key string) (time.Time, error) {
+	s := stringValue(data, key)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,309 @@
+package manager_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/util/vaultutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSecret struct {
+	data      map[string]interface{}
+	version   int
+	createdAt time.Time
+}
+
+// fakeVault serves the parts of the Vault API used by the managers: the list
+// of secret engine mounts and a single KV version 2 engine mounted at pls/,
+// with check-and-set writes, listing and deletion through the metadata
+// endpoints.
+type fakeVault struct {
+	mut         sync.Mutex
+	secrets     map[string]*fakeSecret
+	lists       int
+	beforeWrite func(p string) error
+}
+
+// setBeforeWrite sets a function called with the path of every secret about
+// to be written, relative to the mount. It may write to the fake itself to
+// simulate a concurrent writer, or fail the write by returning an error.
+func (fv *fakeVault) setBeforeWrite(fn func(p string) error) {
+	fv.mut.Lock()
+	defer fv.mut.Unlock()
+
+	fv.beforeWrite = fn
+}
+
+func (fv *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := strings.TrimPrefix(r.URL.Path, "/v1/")
+
+	if p == "sys/mounts" {
+		writeFakeVaultResponse(w, http.StatusOK, map[string]interface{}{
+			"data": map[string]interface{}{
+				"pls/": map[string]interface{}{
+					"type":    "kv",
+					"options": map[string]interface{}{"version": "2"},
+				},
+			},
+		})
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(p, "pls/data/"):
+		p = strings.TrimPrefix(p, "pls/data/")
+
+		switch r.Method {
+		case http.MethodGet:
+			fv.serveRead(w, p)
+		case http.MethodPut, http.MethodPost:
+			fv.serveWrite(w, r, p)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	case strings.HasPrefix(p, "pls/metadata/"):
+		p = strings.TrimPrefix(p, "pls/metadata/")
+
+		switch {
+		case r.Method == "LIST" || (r.Method == http.MethodGet && r.URL.Query().Get("list") == "true"):
+			fv.serveList(w, p)
+		case r.Method == http.MethodDelete:
+			fv.delete(p)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	default:
+		writeFakeVaultResponse(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+	}
+}
+
+func (fv *fakeVault) serveRead(w http.ResponseWriter, p string) {
+	fv.mut.Lock()
+	defer fv.mut.Unlock()
+
+	secret, found := fv.secrets[p]
+	if !found {
+		writeFakeVaultResponse(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+		return
+	}
+
+	writeFakeVaultResponse(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"data": secret.data,
+			"metadata": map[string]interface{}{
+				"created_time": secret.createdAt.Format(time.RFC3339Nano),
+				"version":      secret.version,
+			},
+		},
+	})
+}
+
+func (fv *fakeVault) serveWrite(w http.ResponseWriter, r *http.Request, p string) {
+	var body struct {
+		Data    map[string]interface{} `json:"data"`
+		Options struct {
+			CAS *int `json:"cas"`
+		} `json:"options"`
+	}
+
+	d := json.NewDecoder(r.Body)
+	d.UseNumber()
+	if err := d.Decode(&body); err != nil {
+		writeFakeVaultResponse(w, http.StatusBadRequest, map[string]interface{}{"errors": []string{err.Error()}})
+		return
+	}
+
+	fv.mut.Lock()
+	beforeWrite := fv.beforeWrite
+	fv.mut.Unlock()
+
+	if beforeWrite != nil {
+		if err := beforeWrite(p); err != nil {
+			writeFakeVaultResponse(w, http.StatusForbidden, map[string]interface{}{"errors": []string{err.Error()}})
+			return
+		}
+	}
+
+	fv.mut.Lock()
+	defer fv.mut.Unlock()
+
+	current := 0
+	if secret, found := fv.secrets[p]; found {
+		current = secret.version
+	}
+
+	if body.Options.CAS != nil && *body.Options.CAS != current {
+		writeFakeVaultResponse(w, http.StatusBadRequest, map[string]interface{}{
+			"errors": []string{"check-and-set parameter did not match the current version"},
+		})
+		return
+	}
+
+	version := fv.putLocked(p, body.Data)
+
+	writeFakeVaultResponse(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{"version": version},
+	})
+}
+
+func (fv *fakeVault) serveList(w http.ResponseWriter, p string) {
+	fv.mut.Lock()
+	defer fv.mut.Unlock()
+
+	fv.lists++
+
+	prefix := strings.TrimSuffix(p, "/") + "/"
+
+	seen := make(map[string]struct{})
+	var keys []string
+	for candidate := range fv.secrets {
+		if !strings.HasPrefix(candidate, prefix) {
+			continue
+		}
+
+		key := strings.TrimPrefix(candidate, prefix)
+		if i := strings.Index(key, "/"); i >= 0 {
+			key = key[:i+1]
+		}
+
+		if _, found := seen[key]; !found {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		writeFakeVaultResponse(w, http.StatusNotFound, map[string]interface{}{"errors": []string{}})
+		return
+	}
+
+	sort.Strings(keys)
+
+	writeFakeVaultResponse(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{"keys": keys},
+	})
+}
+
+// get returns the current data of a secret, or nil if it does not exist.
+func (fv *fakeVault) get(p string) map[string]interface{} {
+	fv.mut.Lock()
+	defer fv.mut.Unlock()
+
+	secret, found := fv.secrets[p]
+	if !found {
+		return nil
+	}
+
+	return secret.data
+}
+
+// put writes a new version of a secret, bypassing check-and-set.
+func (fv *fakeVault) put(p string, data map[string]interface{}) {
+	fv.mut.Lock()
+	defer fv.mut.Unlock()
+
+	fv.putLocked(p, data)
+}
+
+func (fv *fakeVault) putLocked(p string, data map[string]interface{}) int {
+	secret, found := fv.secrets[p]
+	if !found {
+		secret = &fakeSecret{createdAt: time.Now()}
+		fv.secrets[p] = secret
+	}
+
+	secret.data = data
+	secret.version++
+
+	return secret.version
+}
+
+func (fv *fakeVault) delete(p string) {
+	fv.mut.Lock()
+	defer fv.mut.Unlock()
+
+	delete(fv.secrets, p)
+}
+
+// paths returns the paths of the secrets beneath a prefix.
+func (fv *fakeVault) paths(prefix string) []string {
+	fv.mut.Lock()
+	defer fv.mut.Unlock()
+
+	var r []string
+	for p := range fv.secrets {
+		if strings.HasPrefix(p, prefix) {
+			r = append(r, p)
+		}
+	}
+
+	sort.Strings(r)
+
+	return r
+}
+
+// listCount returns the number of list requests served.
+func (fv *fakeVault) listCount() int {
+	fv.mut.Lock()
+	defer fv.mut.Unlock()
+
+	return fv.lists
+}
+
+func writeFakeVaultResponse(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *opt.Config, *api.Client) {
+	fv := &fakeVault{
+		secrets: make(map[string]*fakeSecret),
+	}
+
+	srv := httptest.NewServer(fv)
+	t.Cleanup(srv.Close)
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	require.NoError(t, err)
+	client.SetToken("test")
+
+	cfg, err := opt.NewConfig()
+	require.NoError(t, err)
+	cfg.VaultEngineMount = "pls"
+
+	return fv, cfg, client
+}
+
+func TestVaultEngineMount(t *testing.T) {
+	_, cfg, client := newFakeVault(t)
+
+	cfg.VaultEngineMount = "pls/"
+	_, err := manager.NewVaultLogMetadataManager(cfg, client)
+	assert.NoError(t, err)
+
+	cfg.VaultEngineMount = "missing"
+	_, err = manager.NewVaultLogMetadataManager(cfg, client)
+	assert.Equal(t, vaultutil.ErrNoSuchEngineMount, err)
+
+	_, err = manager.NewVaultCredentialManager(cfg, client)
+	assert.Equal(t, vaultutil.ErrNoSuchEngineMount, err)
+
+	_, err = manager.NewVaultRevocationManager(cfg, client)
+	assert.Equal(t, vaultutil.ErrNoSuchEngineMount, err)
+
+	_, err = manager.NewVaultLeaseManager(cfg, client)
+	assert.Equal(t, vaultutil.ErrNoSuchEngineMount, err)
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// WildcardContext grants access to every log context. It is granted to the
+// root credential and may be passed on to the credentials issued from it.
+const WildcardContext = "*"
+
+// Scopes restrict the operations a credential may perform.
+const (
+	ScopeCredentialIssue = "credential:issue"
+	ScopeLogAppend       = "log:append"
+	ScopeLogCreate       = "log:create"
+	ScopeLogDelete       = "log:delete"
+	ScopeLogRead         = "log:read"
+)
+
+// Scopes are all of the known scopes.
+var Scopes = []string{
+	ScopeCredentialIssue,
+	ScopeLogAppend,
+	ScopeLogCreate,
+	ScopeLogDelete,
+	ScopeLogRead,
+}
+
+type Credential struct {
+	ID       string
+	ParentID string
+	Contexts []string
+	// Scopes are the operations permitted to the credential. Credentials
+	// created before scopes were introduced have none and are permitted every
+	// operation.
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+type CredentialMetadata struct {
+	Credential *Credential
+	Token      string
+}
+
+// Revocation invalidates tokens for a credential. If NotBefore is set, only
+// tokens issued before that time are invalid; otherwise every token for the
+// credential is. If Descendants is set, the same tokens of the credential's
+// children are invalid too, as when the credential is revoked rather than
+// refreshed. The revocation can be discarded after ExpiresAt.
+type Revocation struct {
+	CredentialID string
+	NotBefore    time.Time
+	ExpiresAt    time.Time
+	Descendants  bool
+}
+
+type CredentialManager interface {
+	Authenticate(ctx context.Context, token string) (*Credential, error)
+	Children(ctx context.Context, id string) ([]string, error)
+	Create(ctx context.Context, credential *Credential) (*CredentialMetadata, error)
+	Get(ctx context.Context, id string) (*Credential, error)
+	// ListExpired returns up to limit credentials that expired before now.
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*Credential, error)
+	Refresh(ctx context.Context, id string, expiresAt time.Time) (*CredentialMetadata, error)
+	Revoke(ctx context.Context, id string) error
+}
+
+type RevocationManager interface {
+	Add(ctx context.Context, revocation *Revocation) error
+	List(ctx context.Context) ([]*Revocation, error)
+}
@@ -0,0 +1,12 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type LeaseManager interface {
+	// Acquire takes or renews the named lease for the given duration. It
+	// reports whether the lease is held by the caller.
+	Acquire(ctx context.Context, name string, ttl time.Duration) (bool, error)
+}
@@ -2,26 +2,108 @@ package model
 
 import (
 	"context"
+	"time"
 )
 
+// SystemContext holds the logs kept by the service itself, such as the audit
+// trail. It is not granted through the wildcard, only by a context within it,
+// and then only for reading: clients can neither create, append to, seal nor
+// delete its logs.
+const SystemContext = "@system"
+
 type Log struct {
 	Context string
 	Name    string
 }
 
 type LogMetadata struct {
-	Key   string
-	Log   *Log
-	LogID string
+	CreatedAt time.Time
+	// Creator is the ID of the credential that created the log, if known.
+	Creator string
+	Key     string
+	Log     *Log
+	LogID   string
+	// Seal is set once the log is complete.
+	Seal *LogSeal
+}
+
+// LogSeal records that a log is complete and no more messages may be
+// appended to it.
+type LogSeal struct {
+	SealedAt time.Time
+	// ExitCode is the exit code of the process that produced the log, if it
+	// reported one.
+	ExitCode *int32
+	// LastSequence is the highest sequence reserved before the log was
+	// sealed.
+	LastSequence int64
+}
+
+// SystemLogMessage is a message written by the service itself to a log in
+// the system context.
+type SystemLogMessage struct {
+	Timestamp time.Time
+	MediaType string
+	Payload   []byte
+}
+
+// SystemLogWriter appends messages to logs in the system context, creating
+// them as needed. Messages are written directly to storage, without the
+// authorization, rate limits and payload checks applied to clients.
+type SystemLogWriter interface {
+	WriteSystemLog(ctx context.Context, name string, messages []*SystemLogMessage) error
+}
+
+// Cipher encrypts and decrypts data with a single key that has already been
+// parsed, for use when handling many messages of the same log.
+type Cipher interface {
+	Decrypt(data []byte) ([]byte, error)
+	Encrypt(data []byte) ([]byte, error)
 }
 
 type KeyManager interface {
+	Cipher(ctx context.Context, key string) (Cipher, error)
 	Create(ctx context.Context) (string, error)
 	Decrypt(ctx context.Context, key string, data []byte) ([]byte, error)
 	Encrypt(ctx context.Context, key string, data []byte) ([]byte, error)
 }
 
 type LogMetadataManager interface {
-	Create(ctx context.Context, log *Log) (*LogMetadata, error)
+	Create(ctx context.Context, log *Log, creator string) (*LogMetadata, error)
+	// Delete removes a log and destroys its encryption key, so that any
+	// messages stored for it can no longer be read. The log is recorded as
+	// deleted until Purged is called for it.
+	Delete(ctx context.Context, id string) error
+	// ListDeleted returns the IDs of up to limit deleted logs whose stored
+	// messages have not yet been purged, skipping any whose purge has been
+	// deferred to a later time.
+	ListDeleted(ctx context.Context, limit int) ([]string, error)
+	// DeferPurge excludes a deleted log from ListDeleted until the given
+	// time, so that a log whose messages cannot be purged yet does not hold
+	// back the others.
+	DeferPurge(ctx context.Context, id string, until time.Time) error
+	// Purged records that every stored message of a deleted log has been
+	// removed.
+	Purged(ctx context.Context, id string) error
+	// Get returns the metadata of a log, or an error if the log does not
+	// exist.
 	Get(ctx context.Context, id string) (*LogMetadata, error)
+	// ReserveSequence reserves n consecutive message sequence numbers for a
+	// log and returns the first. Sequences start at 1 and are never reused.
+	// It fails once the log is sealed; the check and the reservation are a
+	// single atomic update, so every sequence reserved is at most the last
+	// sequence of the seal.
+	ReserveSequence(ctx context.Context, id string, n int) (int64, error)
+	// Seal marks a log complete, so that no more sequences can be reserved
+	// for it, and returns the seal with its last sequence. Sealing a log
+	// again fails.
+	Seal(ctx context.Context, id string, seal *LogSeal) (*LogSeal, error)
+	// List returns the metadata of every log whose context is accepted by
+	// match. Only contexts at or beneath the given prefixes are searched, or
+	// every context if there are none. Encryption keys are not included.
+	List(ctx context.Context, prefixes []string, match func(logContext string) bool) ([]*LogMetadata, error)
+	// ListContext returns the metadata of every log directly in a context,
+	// without searching the contexts beneath it. Encryption keys are not
+	// included.
+	ListContext(ctx context.Context, logContext string) ([]*LogMetadata, error)
 }
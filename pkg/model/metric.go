@@ -1,12 +1,24 @@
 package model
 
 const (
-	MetricLogCreateMetadata = "log_create_metadata"
-	MetricLogEncryptMessage = "log_encrypt_message"
-	MetricLogGetMetadata    = "log_get_metadata"
-	MetricLogInsertMessage  = "log_insert_message"
-	MetricLogServiceStartup = "log_service_startup"
-	MetricLogStreamMessage  = "log_stream_message"
+	MetricAuditEventDropped = "audit_event_dropped"
+
+	MetricCredentialSweep        = "credential_sweep"
+	MetricCredentialSweepExpired = "credential_sweep_expired"
+
+	MetricLogCreateMetadata  = "log_create_metadata"
+	MetricLogDeleteMessages  = "log_delete_messages"
+	MetricLogDeleteMetadata  = "log_delete_metadata"
+	MetricLogEncryptMessage  = "log_encrypt_message"
+	MetricLogGetMetadata     = "log_get_metadata"
+	MetricLogInsertMessage   = "log_insert_message"
+	MetricLogListMetadata    = "log_list_metadata"
+	MetricLogPollMessages    = "log_poll_messages"
+	MetricLogRateLimit       = "log_rate_limit"
+	MetricLogReserveSequence = "log_reserve_sequence"
+	MetricLogSeal            = "log_seal"
+	MetricLogServiceStartup  = "log_service_startup"
+	MetricLogStreamMessage   = "log_stream_message"
 
 	MetricLabelModule  = "module"
 	MetricLabelOutcome = "outcome"
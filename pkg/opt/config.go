@@ -1,17 +1,54 @@
 package opt
 
 import (
+	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 const (
+	DefaultCredentialTTL    = 24 * time.Hour
+	DefaultCredentialMaxTTL = 30 * 24 * time.Hour
+
+	DefaultCredentialRevocationSyncInterval = 10 * time.Second
+	DefaultCredentialSweepInterval          = 5 * time.Minute
+	DefaultCredentialSweepBatchSize         = 100
+
+	DefaultMessageMaxPayloadSize    = 2 * 1024 * 1024
+	DefaultMessageIdempotencyWindow = 10 * time.Minute
+	DefaultMessagePurgeInterval     = 10 * time.Minute
+	DefaultMessagePurgeBatchSize    = 100
+
+	DefaultRateLimitLogMessages        = 100
+	DefaultRateLimitLogBytes           = 1024 * 1024
+	DefaultRateLimitContextMessages    = 500
+	DefaultRateLimitContextBytes       = 5 * 1024 * 1024
+	DefaultRateLimitCredentialMessages = 1000
+	DefaultRateLimitCredentialBytes    = 10 * 1024 * 1024
+
 	DefaultMetricsURL       = "http://localhost:3050"
 	DefaultVaultEngineMount = "pls"
 	DefaultVaultURL         = "http://localhost:8200"
 )
 
+// RateLimit is a limit on the messages and bytes appended per second. A zero
+// value means that dimension is unlimited.
+type RateLimit struct {
+	Messages float64
+	Bytes    float64
+}
+
+// Rate limit scopes, used as prefixes for overrides.
+const (
+	RateLimitScopeContext    = "context"
+	RateLimitScopeCredential = "credential"
+	RateLimitScopeLog        = "log"
+)
+
 type Config struct {
 	Debug bool
 
@@ -20,6 +57,59 @@ type Config struct {
 
 	ListenPort int
 
+	CredentialTTL    time.Duration
+	CredentialMaxTTL time.Duration
+
+	// CredentialSignedTokens causes issued tokens to be signed so that they
+	// can be verified without a lookup in the credential store.
+	CredentialSignedTokens           bool
+	CredentialRevocationSyncInterval time.Duration
+
+	// CredentialSweepInterval is how often expired credentials are deleted,
+	// and CredentialSweepBatchSize is the most deleted in a single pass.
+	CredentialSweepInterval  time.Duration
+	CredentialSweepBatchSize int
+
+	// RootTokenFile and RootTokenVaultPath are the destinations for the token
+	// of the root credential. The Vault path is relative to the engine mount.
+	RootTokenFile      string
+	RootTokenVaultPath string
+
+	// AuditFile is a file to append audit events to as JSON lines.
+	// AuditSystemLog additionally writes them to a system log kept by the
+	// service itself. AuditKey is the secret that authenticates the chain of
+	// events, and is required if either is set.
+	AuditFile      string
+	AuditSystemLog bool
+	AuditKey       string
+
+	// MessageMaxPayloadSize is the largest payload, in bytes, that may be
+	// appended to a log. MessageMediaTypes are the media types accepted for
+	// payloads.
+	MessageMaxPayloadSize int
+	MessageMediaTypes     []string
+
+	// MessageIdempotencyWindow is how long the idempotency key of an append
+	// is remembered, so that retries within it are not appended again. Zero
+	// disables the window, leaving only the deduplication of BigQuery
+	// streaming inserts.
+	MessageIdempotencyWindow time.Duration
+
+	// MessagePurgeInterval is how often the messages of deleted logs are
+	// removed from storage, and MessagePurgeBatchSize is the most logs
+	// purged in a single pass.
+	MessagePurgeInterval  time.Duration
+	MessagePurgeBatchSize int
+
+	// RateLimitLog, RateLimitContext and RateLimitCredential are the default
+	// limits applied to each log, context and credential respectively.
+	// RateLimitOverrides replaces the default for specific ones, keyed by
+	// scope and identifier, for example "context:runs/1".
+	RateLimitLog        RateLimit
+	RateLimitContext    RateLimit
+	RateLimitCredential RateLimit
+	RateLimitOverrides  map[string]RateLimit
+
 	Dataset string
 	Project string
 	Table   string
@@ -37,6 +127,22 @@ func NewConfig() (*Config, error) {
 	viper.SetDefault("metrics_enabled", false)
 	viper.SetDefault("metrics_server_addr", DefaultMetricsURL)
 	viper.SetDefault("vault_engine_mount", DefaultVaultEngineMount)
+	viper.SetDefault("credential_ttl", DefaultCredentialTTL)
+	viper.SetDefault("credential_max_ttl", DefaultCredentialMaxTTL)
+	viper.SetDefault("credential_revocation_sync_interval", DefaultCredentialRevocationSyncInterval)
+	viper.SetDefault("credential_sweep_interval", DefaultCredentialSweepInterval)
+	viper.SetDefault("credential_sweep_batch_size", DefaultCredentialSweepBatchSize)
+	viper.SetDefault("message_max_payload_size", DefaultMessageMaxPayloadSize)
+	viper.SetDefault("message_media_types", []string{"application/octet-stream"})
+	viper.SetDefault("message_idempotency_window", DefaultMessageIdempotencyWindow)
+	viper.SetDefault("message_purge_interval", DefaultMessagePurgeInterval)
+	viper.SetDefault("message_purge_batch_size", DefaultMessagePurgeBatchSize)
+	viper.SetDefault("rate_limit_log_messages", DefaultRateLimitLogMessages)
+	viper.SetDefault("rate_limit_log_bytes", DefaultRateLimitLogBytes)
+	viper.SetDefault("rate_limit_context_messages", DefaultRateLimitContextMessages)
+	viper.SetDefault("rate_limit_context_bytes", DefaultRateLimitContextBytes)
+	viper.SetDefault("rate_limit_credential_messages", DefaultRateLimitCredentialMessages)
+	viper.SetDefault("rate_limit_credential_bytes", DefaultRateLimitCredentialBytes)
 
 	config := &Config{
 		Debug: viper.GetBool("debug"),
@@ -46,6 +152,43 @@ func NewConfig() (*Config, error) {
 
 		ListenPort: viper.GetInt("listen_port"),
 
+		CredentialTTL:    viper.GetDuration("credential_ttl"),
+		CredentialMaxTTL: viper.GetDuration("credential_max_ttl"),
+
+		CredentialSignedTokens:           viper.GetBool("credential_signed_tokens"),
+		CredentialRevocationSyncInterval: viper.GetDuration("credential_revocation_sync_interval"),
+
+		CredentialSweepInterval:  viper.GetDuration("credential_sweep_interval"),
+		CredentialSweepBatchSize: viper.GetInt("credential_sweep_batch_size"),
+
+		RootTokenFile:      viper.GetString("root_token_file"),
+		RootTokenVaultPath: viper.GetString("root_token_vault_path"),
+
+		AuditFile:      viper.GetString("audit_file"),
+		AuditSystemLog: viper.GetBool("audit_system_log"),
+		AuditKey:       viper.GetString("audit_key"),
+
+		MessageMaxPayloadSize: viper.GetInt("message_max_payload_size"),
+		MessageMediaTypes:     viper.GetStringSlice("message_media_types"),
+
+		MessageIdempotencyWindow: viper.GetDuration("message_idempotency_window"),
+
+		MessagePurgeInterval:  viper.GetDuration("message_purge_interval"),
+		MessagePurgeBatchSize: viper.GetInt("message_purge_batch_size"),
+
+		RateLimitLog: RateLimit{
+			Messages: viper.GetFloat64("rate_limit_log_messages"),
+			Bytes:    viper.GetFloat64("rate_limit_log_bytes"),
+		},
+		RateLimitContext: RateLimit{
+			Messages: viper.GetFloat64("rate_limit_context_messages"),
+			Bytes:    viper.GetFloat64("rate_limit_context_bytes"),
+		},
+		RateLimitCredential: RateLimit{
+			Messages: viper.GetFloat64("rate_limit_credential_messages"),
+			Bytes:    viper.GetFloat64("rate_limit_credential_bytes"),
+		},
+
 		Dataset: viper.GetString("dataset"),
 		Project: viper.GetString("project"),
 		Table:   viper.GetString("table"),
@@ -53,6 +196,13 @@ func NewConfig() (*Config, error) {
 		VaultEngineMount: viper.GetString("vault_engine_mount"),
 	}
 
+	overrides, err := ParseRateLimitOverrides(viper.GetStringSlice("rate_limit_overrides"))
+	if err != nil {
+		return nil, err
+	}
+
+	config.RateLimitOverrides = overrides
+
 	if viper.IsSet("vault_addr") {
 		vaultURL, err := url.Parse(viper.GetString("vault_addr"))
 		if err != nil {
@@ -64,3 +214,56 @@ func NewConfig() (*Config, error) {
 
 	return config, nil
 }
+
+// ParseRateLimitOverrides parses rate limit overrides of the form
+// <scope>:<id>=<messages>/<bytes>, for example
+// "context:runs/1=50/524288".
+func ParseRateLimitOverrides(values []string) (map[string]RateLimit, error) {
+	overrides := make(map[string]RateLimit, len(values))
+
+	for _, value := range values {
+		key, limit, ok := cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit override %q: expected <scope>:<id>=<messages>/<bytes>", value)
+		}
+
+		scope, id, ok := cut(key, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid rate limit override %q: expected <scope>:<id>", value)
+		}
+
+		switch scope {
+		case RateLimitScopeContext, RateLimitScopeCredential, RateLimitScopeLog:
+		default:
+			return nil, fmt.Errorf("invalid rate limit override %q: unknown scope %q", value, scope)
+		}
+
+		messages, bytes, ok := cut(limit, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit override %q: expected <messages>/<bytes>", value)
+		}
+
+		var rl RateLimit
+		var err error
+
+		if rl.Messages, err = strconv.ParseFloat(messages, 64); err != nil || rl.Messages < 0 {
+			return nil, fmt.Errorf("invalid rate limit override %q: invalid messages per second", value)
+		}
+
+		if rl.Bytes, err = strconv.ParseFloat(bytes, 64); err != nil || rl.Bytes < 0 {
+			return nil, fmt.Errorf("invalid rate limit override %q: invalid bytes per second", value)
+		}
+
+		overrides[key] = rl
+	}
+
+	return overrides, nil
+}
+
+func cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+
+	return s, "", false
+}
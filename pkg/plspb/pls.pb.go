@@ -21,21 +21,277 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type LogMessageListRequest_Order int32
+
+const (
+	// ORDER_ASCENDING returns the oldest messages first.
+	LogMessageListRequest_ORDER_ASCENDING LogMessageListRequest_Order = 0
+	// ORDER_DESCENDING returns the newest messages first.
+	LogMessageListRequest_ORDER_DESCENDING LogMessageListRequest_Order = 1
+)
+
+// Enum value maps for LogMessageListRequest_Order.
+var (
+	LogMessageListRequest_Order_name = map[int32]string{
+		0: "ORDER_ASCENDING",
+		1: "ORDER_DESCENDING",
+	}
+	LogMessageListRequest_Order_value = map[string]int32{
+		"ORDER_ASCENDING":  0,
+		"ORDER_DESCENDING": 1,
+	}
+)
+
+func (x LogMessageListRequest_Order) Enum() *LogMessageListRequest_Order {
+	p := new(LogMessageListRequest_Order)
+	*p = x
+	return p
+}
+
+func (x LogMessageListRequest_Order) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogMessageListRequest_Order) Descriptor() protoreflect.EnumDescriptor {
+	return file_pls_proto_enumTypes[0].Descriptor()
+}
+
+func (LogMessageListRequest_Order) Type() protoreflect.EnumType {
+	return &file_pls_proto_enumTypes[0]
+}
+
+func (x LogMessageListRequest_Order) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogMessageListRequest_Order.Descriptor instead.
+func (LogMessageListRequest_Order) EnumDescriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{21, 0}
+}
+
+type LogMessageFilter_Mode int32
+
+const (
+	// MODE_SUBSTRING matches payloads containing the pattern.
+	LogMessageFilter_MODE_SUBSTRING LogMessageFilter_Mode = 0
+	// MODE_CASE_INSENSITIVE matches payloads containing the pattern,
+	// ignoring case.
+	LogMessageFilter_MODE_CASE_INSENSITIVE LogMessageFilter_Mode = 1
+	// MODE_REGEX matches payloads containing a match of the pattern as an RE2
+	// regular expression.
+	LogMessageFilter_MODE_REGEX LogMessageFilter_Mode = 2
+)
+
+// Enum value maps for LogMessageFilter_Mode.
+var (
+	LogMessageFilter_Mode_name = map[int32]string{
+		0: "MODE_SUBSTRING",
+		1: "MODE_CASE_INSENSITIVE",
+		2: "MODE_REGEX",
+	}
+	LogMessageFilter_Mode_value = map[string]int32{
+		"MODE_SUBSTRING":        0,
+		"MODE_CASE_INSENSITIVE": 1,
+		"MODE_REGEX":            2,
+	}
+)
+
+func (x LogMessageFilter_Mode) Enum() *LogMessageFilter_Mode {
+	p := new(LogMessageFilter_Mode)
+	*p = x
+	return p
+}
+
+func (x LogMessageFilter_Mode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogMessageFilter_Mode) Descriptor() protoreflect.EnumDescriptor {
+	return file_pls_proto_enumTypes[1].Descriptor()
+}
+
+func (LogMessageFilter_Mode) Type() protoreflect.EnumType {
+	return &file_pls_proto_enumTypes[1]
+}
+
+func (x LogMessageFilter_Mode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogMessageFilter_Mode.Descriptor instead.
+func (LogMessageFilter_Mode) EnumDescriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{22, 0}
+}
+
+type CredentialDescribeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// credential_id is the public identifier for the credential to describe. If
+	// not provided, the credential authenticating this request will be
+	// described. The credential must be that of the authenticated token or one
+	// of its children.
+	CredentialId string `protobuf:"bytes,1,opt,name=credential_id,json=credentialId,proto3" json:"credential_id,omitempty"`
+}
+
+func (x *CredentialDescribeRequest) Reset() {
+	*x = CredentialDescribeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pls_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CredentialDescribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CredentialDescribeRequest) ProtoMessage() {}
+
+func (x *CredentialDescribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pls_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CredentialDescribeRequest.ProtoReflect.Descriptor instead.
+func (*CredentialDescribeRequest) Descriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CredentialDescribeRequest) GetCredentialId() string {
+	if x != nil {
+		return x.CredentialId
+	}
+	return ""
+}
+
+type CredentialDescribeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// credential_id is the unique public identifier for the credential.
+	CredentialId string `protobuf:"bytes,1,opt,name=credential_id,json=credentialId,proto3" json:"credential_id,omitempty"`
+	// parent_id is the identifier of the credential that issued this
+	// credential. It is empty for a root credential.
+	ParentId string `protobuf:"bytes,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	// contexts is the list of contexts granted to this credential.
+	Contexts []string `protobuf:"bytes,3,rep,name=contexts,proto3" json:"contexts,omitempty"`
+	// expires_at indicates when this credential expires. It is not set if the
+	// credential does not expire.
+	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// children is the number of unexpired credentials issued directly by this
+	// credential.
+	Children int32 `protobuf:"varint,5,opt,name=children,proto3" json:"children,omitempty"`
+	// scopes is the list of operations permitted to this credential.
+	Scopes []string `protobuf:"bytes,6,rep,name=scopes,proto3" json:"scopes,omitempty"`
+}
+
+func (x *CredentialDescribeResponse) Reset() {
+	*x = CredentialDescribeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pls_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CredentialDescribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CredentialDescribeResponse) ProtoMessage() {}
+
+func (x *CredentialDescribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pls_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CredentialDescribeResponse.ProtoReflect.Descriptor instead.
+func (*CredentialDescribeResponse) Descriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CredentialDescribeResponse) GetCredentialId() string {
+	if x != nil {
+		return x.CredentialId
+	}
+	return ""
+}
+
+func (x *CredentialDescribeResponse) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *CredentialDescribeResponse) GetContexts() []string {
+	if x != nil {
+		return x.Contexts
+	}
+	return nil
+}
+
+func (x *CredentialDescribeResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *CredentialDescribeResponse) GetChildren() int32 {
+	if x != nil {
+		return x.Children
+	}
+	return 0
+}
+
+func (x *CredentialDescribeResponse) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
 type CredentialIssueRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	// contexts is the list of allowed log storage contexts for this credential.
+	// A context ending in /* grants every context beneath it, and * grants all
+	// contexts. Each must be covered by a context of the parent credential.
 	Contexts []string `protobuf:"bytes,1,rep,name=contexts,proto3" json:"contexts,omitempty"`
 	// expires_at indicates when this credential should expire.
 	ExpiresAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// scopes is the list of operations permitted to this credential: any of
+	// log:create, log:append, log:read, log:delete and credential:issue. Each
+	// must be permitted to the parent credential. If not specified, the scopes
+	// of the parent credential are used.
+	Scopes []string `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
 }
 
 func (x *CredentialIssueRequest) Reset() {
 	*x = CredentialIssueRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[0]
+		mi := &file_pls_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -48,7 +304,7 @@ func (x *CredentialIssueRequest) String() string {
 func (*CredentialIssueRequest) ProtoMessage() {}
 
 func (x *CredentialIssueRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[0]
+	mi := &file_pls_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -61,7 +317,7 @@ func (x *CredentialIssueRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CredentialIssueRequest.ProtoReflect.Descriptor instead.
 func (*CredentialIssueRequest) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{0}
+	return file_pls_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *CredentialIssueRequest) GetContexts() []string {
@@ -78,6 +334,13 @@ func (x *CredentialIssueRequest) GetExpiresAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *CredentialIssueRequest) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
 type CredentialIssueResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -94,12 +357,14 @@ type CredentialIssueResponse struct {
 	// token is the opaque authentication token for this credential to be passed
 	// to other RPC calls.
 	Token string `protobuf:"bytes,4,opt,name=token,proto3" json:"token,omitempty"`
+	// scopes is the list of operations actually permitted to this credential.
+	Scopes []string `protobuf:"bytes,5,rep,name=scopes,proto3" json:"scopes,omitempty"`
 }
 
 func (x *CredentialIssueResponse) Reset() {
 	*x = CredentialIssueResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[1]
+		mi := &file_pls_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -112,7 +377,7 @@ func (x *CredentialIssueResponse) String() string {
 func (*CredentialIssueResponse) ProtoMessage() {}
 
 func (x *CredentialIssueResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[1]
+	mi := &file_pls_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -125,7 +390,7 @@ func (x *CredentialIssueResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CredentialIssueResponse.ProtoReflect.Descriptor instead.
 func (*CredentialIssueResponse) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{1}
+	return file_pls_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CredentialIssueResponse) GetCredentialId() string {
@@ -156,6 +421,13 @@ func (x *CredentialIssueResponse) GetToken() string {
 	return ""
 }
 
+func (x *CredentialIssueResponse) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
 type CredentialRefreshRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -173,7 +445,7 @@ type CredentialRefreshRequest struct {
 func (x *CredentialRefreshRequest) Reset() {
 	*x = CredentialRefreshRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[2]
+		mi := &file_pls_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -186,7 +458,7 @@ func (x *CredentialRefreshRequest) String() string {
 func (*CredentialRefreshRequest) ProtoMessage() {}
 
 func (x *CredentialRefreshRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[2]
+	mi := &file_pls_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -199,7 +471,7 @@ func (x *CredentialRefreshRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CredentialRefreshRequest.ProtoReflect.Descriptor instead.
 func (*CredentialRefreshRequest) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{2}
+	return file_pls_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CredentialRefreshRequest) GetCredentialId() string {
@@ -234,7 +506,7 @@ type CredentialRefreshResponse struct {
 func (x *CredentialRefreshResponse) Reset() {
 	*x = CredentialRefreshResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[3]
+		mi := &file_pls_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -247,7 +519,7 @@ func (x *CredentialRefreshResponse) String() string {
 func (*CredentialRefreshResponse) ProtoMessage() {}
 
 func (x *CredentialRefreshResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[3]
+	mi := &file_pls_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -260,7 +532,7 @@ func (x *CredentialRefreshResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CredentialRefreshResponse.ProtoReflect.Descriptor instead.
 func (*CredentialRefreshResponse) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{3}
+	return file_pls_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *CredentialRefreshResponse) GetCredentialId() string {
@@ -299,7 +571,7 @@ type CredentialRevokeRequest struct {
 func (x *CredentialRevokeRequest) Reset() {
 	*x = CredentialRevokeRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[4]
+		mi := &file_pls_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -312,7 +584,7 @@ func (x *CredentialRevokeRequest) String() string {
 func (*CredentialRevokeRequest) ProtoMessage() {}
 
 func (x *CredentialRevokeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[4]
+	mi := &file_pls_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -325,7 +597,7 @@ func (x *CredentialRevokeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CredentialRevokeRequest.ProtoReflect.Descriptor instead.
 func (*CredentialRevokeRequest) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{4}
+	return file_pls_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *CredentialRevokeRequest) GetCredentialId() string {
@@ -347,7 +619,7 @@ type CredentialRevokeResponse struct {
 func (x *CredentialRevokeResponse) Reset() {
 	*x = CredentialRevokeResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[5]
+		mi := &file_pls_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -360,7 +632,7 @@ func (x *CredentialRevokeResponse) String() string {
 func (*CredentialRevokeResponse) ProtoMessage() {}
 
 func (x *CredentialRevokeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[5]
+	mi := &file_pls_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -373,7 +645,7 @@ func (x *CredentialRevokeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CredentialRevokeResponse.ProtoReflect.Descriptor instead.
 func (*CredentialRevokeResponse) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{5}
+	return file_pls_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CredentialRevokeResponse) GetCredentialId() string {
@@ -400,7 +672,7 @@ type LogCreateRequest struct {
 func (x *LogCreateRequest) Reset() {
 	*x = LogCreateRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[6]
+		mi := &file_pls_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -413,7 +685,7 @@ func (x *LogCreateRequest) String() string {
 func (*LogCreateRequest) ProtoMessage() {}
 
 func (x *LogCreateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[6]
+	mi := &file_pls_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -426,7 +698,7 @@ func (x *LogCreateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogCreateRequest.ProtoReflect.Descriptor instead.
 func (*LogCreateRequest) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{6}
+	return file_pls_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *LogCreateRequest) GetContext() string {
@@ -455,7 +727,7 @@ type LogCreateResponse struct {
 func (x *LogCreateResponse) Reset() {
 	*x = LogCreateResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[7]
+		mi := &file_pls_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -468,7 +740,7 @@ func (x *LogCreateResponse) String() string {
 func (*LogCreateResponse) ProtoMessage() {}
 
 func (x *LogCreateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[7]
+	mi := &file_pls_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -481,7 +753,7 @@ func (x *LogCreateResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogCreateResponse.ProtoReflect.Descriptor instead.
 func (*LogCreateResponse) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{7}
+	return file_pls_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *LogCreateResponse) GetLogId() string {
@@ -503,7 +775,7 @@ type LogDeleteRequest struct {
 func (x *LogDeleteRequest) Reset() {
 	*x = LogDeleteRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[8]
+		mi := &file_pls_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -516,7 +788,7 @@ func (x *LogDeleteRequest) String() string {
 func (*LogDeleteRequest) ProtoMessage() {}
 
 func (x *LogDeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[8]
+	mi := &file_pls_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -529,7 +801,7 @@ func (x *LogDeleteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogDeleteRequest.ProtoReflect.Descriptor instead.
 func (*LogDeleteRequest) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{8}
+	return file_pls_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *LogDeleteRequest) GetLogId() string {
@@ -548,7 +820,7 @@ type LogDeleteResponse struct {
 func (x *LogDeleteResponse) Reset() {
 	*x = LogDeleteResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[9]
+		mi := &file_pls_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -561,7 +833,7 @@ func (x *LogDeleteResponse) String() string {
 func (*LogDeleteResponse) ProtoMessage() {}
 
 func (x *LogDeleteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[9]
+	mi := &file_pls_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -574,37 +846,41 @@ func (x *LogDeleteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogDeleteResponse.ProtoReflect.Descriptor instead.
 func (*LogDeleteResponse) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{9}
+	return file_pls_proto_rawDescGZIP(), []int{11}
 }
 
-type LogListRequest struct {
+type LogSealRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// contexts is an optional list of contexts to limit the response to. If not
-	// specified, the response includes all contexts the authenticating
-	// credential has access to.
-	Contexts []string `protobuf:"bytes,1,rep,name=contexts,proto3" json:"contexts,omitempty"`
+	// log_id is the identifier for the log stream to seal.
+	LogId string `protobuf:"bytes,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// exit_code is the exit code of the process that produced the log stream,
+	// if there is one.
+	ExitCode *int32 `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+	// sealed_at is the time the log stream was completed. If not specified,
+	// the time the request is received is used.
+	SealedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=sealed_at,json=sealedAt,proto3" json:"sealed_at,omitempty"`
 }
 
-func (x *LogListRequest) Reset() {
-	*x = LogListRequest{}
+func (x *LogSealRequest) Reset() {
+	*x = LogSealRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[10]
+		mi := &file_pls_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *LogListRequest) String() string {
+func (x *LogSealRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogListRequest) ProtoMessage() {}
+func (*LogSealRequest) ProtoMessage() {}
 
-func (x *LogListRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[10]
+func (x *LogSealRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pls_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -615,48 +891,63 @@ func (x *LogListRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogListRequest.ProtoReflect.Descriptor instead.
-func (*LogListRequest) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use LogSealRequest.ProtoReflect.Descriptor instead.
+func (*LogSealRequest) Descriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *LogListRequest) GetContexts() []string {
+func (x *LogSealRequest) GetLogId() string {
 	if x != nil {
-		return x.Contexts
+		return x.LogId
+	}
+	return ""
+}
+
+func (x *LogSealRequest) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *LogSealRequest) GetSealedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SealedAt
 	}
 	return nil
 }
 
-type LogListResponse struct {
+type LogSealResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// log_id is the unique identifier for the log stream.
+	// log_id is the identifier for the sealed log stream.
 	LogId string `protobuf:"bytes,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
-	// context for this log stream.
-	Context string `protobuf:"bytes,2,opt,name=context,proto3" json:"context,omitempty"`
-	// name is the human-readable identifier for this log stream.
-	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// sealed_at is the time the log stream was completed.
+	SealedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=sealed_at,json=sealedAt,proto3" json:"sealed_at,omitempty"`
+	// last_sequence is the highest sequence that may have been assigned to a
+	// message before the log stream was sealed.
+	LastSequence int64 `protobuf:"varint,3,opt,name=last_sequence,json=lastSequence,proto3" json:"last_sequence,omitempty"`
 }
 
-func (x *LogListResponse) Reset() {
-	*x = LogListResponse{}
+func (x *LogSealResponse) Reset() {
+	*x = LogSealResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[11]
+		mi := &file_pls_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *LogListResponse) String() string {
+func (x *LogSealResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogListResponse) ProtoMessage() {}
+func (*LogSealResponse) ProtoMessage() {}
 
-func (x *LogListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[11]
+func (x *LogSealResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pls_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -667,9 +958,133 @@ func (x *LogListResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogListResponse.ProtoReflect.Descriptor instead.
-func (*LogListResponse) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use LogSealResponse.ProtoReflect.Descriptor instead.
+func (*LogSealResponse) Descriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LogSealResponse) GetLogId() string {
+	if x != nil {
+		return x.LogId
+	}
+	return ""
+}
+
+func (x *LogSealResponse) GetSealedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SealedAt
+	}
+	return nil
+}
+
+func (x *LogSealResponse) GetLastSequence() int64 {
+	if x != nil {
+		return x.LastSequence
+	}
+	return 0
+}
+
+type LogListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// contexts is an optional list of contexts to limit the response to. A
+	// context ending in /* includes every context beneath it. If not specified,
+	// the response includes all contexts the authenticating credential has
+	// access to.
+	Contexts []string `protobuf:"bytes,1,rep,name=contexts,proto3" json:"contexts,omitempty"`
+}
+
+func (x *LogListRequest) Reset() {
+	*x = LogListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pls_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogListRequest) ProtoMessage() {}
+
+func (x *LogListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pls_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogListRequest.ProtoReflect.Descriptor instead.
+func (*LogListRequest) Descriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *LogListRequest) GetContexts() []string {
+	if x != nil {
+		return x.Contexts
+	}
+	return nil
+}
+
+type LogListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// log_id is the unique identifier for the log stream.
+	LogId string `protobuf:"bytes,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// context for this log stream.
+	Context string `protobuf:"bytes,2,opt,name=context,proto3" json:"context,omitempty"`
+	// name is the human-readable identifier for this log stream.
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// sealed indicates whether the log stream is complete.
+	Sealed bool `protobuf:"varint,4,opt,name=sealed,proto3" json:"sealed,omitempty"`
+	// sealed_at is the time the log stream was completed, if it is sealed.
+	SealedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=sealed_at,json=sealedAt,proto3" json:"sealed_at,omitempty"`
+	// exit_code is the exit code reported when the log stream was sealed, if
+	// any.
+	ExitCode *int32 `protobuf:"varint,6,opt,name=exit_code,json=exitCode,proto3,oneof" json:"exit_code,omitempty"`
+}
+
+func (x *LogListResponse) Reset() {
+	*x = LogListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pls_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogListResponse) ProtoMessage() {}
+
+func (x *LogListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pls_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogListResponse.ProtoReflect.Descriptor instead.
+func (*LogListResponse) Descriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *LogListResponse) GetLogId() string {
@@ -693,6 +1108,27 @@ func (x *LogListResponse) GetName() string {
 	return ""
 }
 
+func (x *LogListResponse) GetSealed() bool {
+	if x != nil {
+		return x.Sealed
+	}
+	return false
+}
+
+func (x *LogListResponse) GetSealedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SealedAt
+	}
+	return nil
+}
+
+func (x *LogListResponse) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
 type LogMessageAppendRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -700,19 +1136,30 @@ type LogMessageAppendRequest struct {
 
 	// log_id is the identifier for the log stream to append to.
 	LogId string `protobuf:"bytes,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
-	// media_type is the IANA media type for the payload. Initially, the only
-	// supported media type is "application/octet-stream".
+	// media_type is the IANA media type for the payload. If not specified,
+	// "application/octet-stream" is used. The supported media types are
+	// configured by the service; "text/plain" and "application/json" payloads
+	// must be valid UTF-8 and JSON respectively.
 	MediaType string `protobuf:"bytes,2,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"`
 	// payload is the actual log data to append to the stream.
 	Payload []byte `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
 	// timestamp is the time the message was originally received
 	Timestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// idempotency_key, if set, identifies this append so that it may be safely
+	// retried. A retry with the same key for the same log stream returns the
+	// original log_message_id instead of appending the message again, and is
+	// rejected with ABORTED while the original append is still in progress.
+	// Keys are remembered for a period configured by the service and may be
+	// at most 128 bytes. A retry after that period, or one handled by another
+	// replica, is not appended again but may be answered with a sequence that
+	// is never used, leaving a gap in the log.
+	IdempotencyKey string `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
 }
 
 func (x *LogMessageAppendRequest) Reset() {
 	*x = LogMessageAppendRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[12]
+		mi := &file_pls_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -725,7 +1172,7 @@ func (x *LogMessageAppendRequest) String() string {
 func (*LogMessageAppendRequest) ProtoMessage() {}
 
 func (x *LogMessageAppendRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[12]
+	mi := &file_pls_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -738,7 +1185,7 @@ func (x *LogMessageAppendRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogMessageAppendRequest.ProtoReflect.Descriptor instead.
 func (*LogMessageAppendRequest) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{12}
+	return file_pls_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *LogMessageAppendRequest) GetLogId() string {
@@ -769,6 +1216,13 @@ func (x *LogMessageAppendRequest) GetTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *LogMessageAppendRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
 type LogMessageAppendResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -779,12 +1233,16 @@ type LogMessageAppendResponse struct {
 	// log_message_id is an opaque identifier for the message, unique to this log
 	// stream.
 	LogMessageId string `protobuf:"bytes,2,opt,name=log_message_id,json=logMessageId,proto3" json:"log_message_id,omitempty"`
+	// sequence is the position of the message in the log stream. It is
+	// assigned by the service and strictly increases with each message
+	// appended to the log stream.
+	Sequence int64 `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
 }
 
 func (x *LogMessageAppendResponse) Reset() {
 	*x = LogMessageAppendResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[13]
+		mi := &file_pls_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -797,7 +1255,7 @@ func (x *LogMessageAppendResponse) String() string {
 func (*LogMessageAppendResponse) ProtoMessage() {}
 
 func (x *LogMessageAppendResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[13]
+	mi := &file_pls_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -810,7 +1268,7 @@ func (x *LogMessageAppendResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogMessageAppendResponse.ProtoReflect.Descriptor instead.
 func (*LogMessageAppendResponse) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{13}
+	return file_pls_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *LogMessageAppendResponse) GetLogId() string {
@@ -827,6 +1285,196 @@ func (x *LogMessageAppendResponse) GetLogMessageId() string {
 	return ""
 }
 
+func (x *LogMessageAppendResponse) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+type LogMessageAppendBatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// messages are the messages to append.
+	Messages []*LogMessageAppendRequest `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+}
+
+func (x *LogMessageAppendBatchRequest) Reset() {
+	*x = LogMessageAppendBatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pls_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogMessageAppendBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogMessageAppendBatchRequest) ProtoMessage() {}
+
+func (x *LogMessageAppendBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pls_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogMessageAppendBatchRequest.ProtoReflect.Descriptor instead.
+func (*LogMessageAppendBatchRequest) Descriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *LogMessageAppendBatchRequest) GetMessages() []*LogMessageAppendRequest {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type LogMessageAppendResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// log_id is the identifier for the log stream the message was sent to.
+	LogId string `protobuf:"bytes,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// log_message_id is the stream-unique identifier for the appended message.
+	// It is empty if the message could not be appended.
+	LogMessageId string `protobuf:"bytes,2,opt,name=log_message_id,json=logMessageId,proto3" json:"log_message_id,omitempty"`
+	// error_code is the google.rpc.Code describing why the message could not
+	// be appended, or 0 (OK) if it was appended.
+	ErrorCode int32 `protobuf:"varint,3,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	// error_message describes why the message could not be appended.
+	ErrorMessage string `protobuf:"bytes,4,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	// sequence is the position of the appended message in the log stream. It
+	// is 0 if the message could not be appended.
+	Sequence int64 `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`
+}
+
+func (x *LogMessageAppendResult) Reset() {
+	*x = LogMessageAppendResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pls_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogMessageAppendResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogMessageAppendResult) ProtoMessage() {}
+
+func (x *LogMessageAppendResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pls_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogMessageAppendResult.ProtoReflect.Descriptor instead.
+func (*LogMessageAppendResult) Descriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *LogMessageAppendResult) GetLogId() string {
+	if x != nil {
+		return x.LogId
+	}
+	return ""
+}
+
+func (x *LogMessageAppendResult) GetLogMessageId() string {
+	if x != nil {
+		return x.LogMessageId
+	}
+	return ""
+}
+
+func (x *LogMessageAppendResult) GetErrorCode() int32 {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return 0
+}
+
+func (x *LogMessageAppendResult) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *LogMessageAppendResult) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+type LogMessageAppendBatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// results has an entry for each message, in the order they were sent.
+	Results []*LogMessageAppendResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *LogMessageAppendBatchResponse) Reset() {
+	*x = LogMessageAppendBatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pls_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogMessageAppendBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogMessageAppendBatchResponse) ProtoMessage() {}
+
+func (x *LogMessageAppendBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pls_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogMessageAppendBatchResponse.ProtoReflect.Descriptor instead.
+func (*LogMessageAppendBatchResponse) Descriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *LogMessageAppendBatchResponse) GetResults() []*LogMessageAppendResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
 type LogMessageListRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -837,18 +1485,54 @@ type LogMessageListRequest struct {
 	// follow indicates whether this request should stay open while new messages
 	// are added to the stream. This method is opportunistic and the server may
 	// cancel streaming at any time. The client may retry by issuing another list
-	// request.
+	// request, resuming with after_sequence. Messages appended concurrently
+	// through different replicas of the service may be sent out of sequence
+	// order, and a client that does not keep up is disconnected with ABORTED.
 	Follow bool `protobuf:"varint,2,opt,name=follow,proto3" json:"follow,omitempty"`
 	// start_at is the offset to begin reading messages, inclusive.
 	StartAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_at,json=startAt,proto3" json:"start_at,omitempty"`
 	// end_at is the offset to stop reading messages, exclusive.
 	EndAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_at,json=endAt,proto3" json:"end_at,omitempty"`
+	// after_sequence resumes reading after the message with this sequence,
+	// exclusive. Messages are returned in sequence order, so no message is
+	// skipped or repeated.
+	AfterSequence int64 `protobuf:"varint,5,opt,name=after_sequence,json=afterSequence,proto3" json:"after_sequence,omitempty"`
+	// after_message_id resumes reading after the message with this identifier.
+	// It may not be combined with after_sequence.
+	AfterMessageId string `protobuf:"bytes,6,opt,name=after_message_id,json=afterMessageId,proto3" json:"after_message_id,omitempty"`
+	// log_ids lists further log streams to retrieve messages from, merged with
+	// the log stream identified by log_id, if any. At most 100 log streams may
+	// be requested, and resuming with after_sequence or after_message_id is
+	// only possible when exactly one is. While following, new messages of
+	// different log streams are held briefly and sent ordered by timestamp,
+	// then by sequence; a message stored later than that is sent as soon as it
+	// arrives, and so may be out of order.
+	LogIds []string `protobuf:"bytes,7,rep,name=log_ids,json=logIds,proto3" json:"log_ids,omitempty"`
+	// context retrieves messages from every log stream in this exact context,
+	// as of the time of the request. It may not be combined with log_id or
+	// log_ids. Log streams created in the context while following are not
+	// included.
+	Context string `protobuf:"bytes,8,opt,name=context,proto3" json:"context,omitempty"`
+	// limit is the most messages to return, or 0 for no limit. It may not be
+	// combined with follow or tail.
+	Limit int32 `protobuf:"varint,9,opt,name=limit,proto3" json:"limit,omitempty"`
+	// order is the order to return messages in. Descending order may not be
+	// combined with follow or tail.
+	Order LogMessageListRequest_Order `protobuf:"varint,10,opt,name=order,proto3,enum=plspb.LogMessageListRequest_Order" json:"order,omitempty"`
+	// tail returns only this many of the newest messages, in ascending order,
+	// or all messages if 0. It may be at most 10000. Combined with follow, the
+	// newest messages are sent before new messages as they are added.
+	Tail int32 `protobuf:"varint,11,opt,name=tail,proto3" json:"tail,omitempty"`
+	// filter, if set, returns only the messages whose payload matches it, and
+	// optionally the messages around them. It is applied before limit and
+	// tail.
+	Filter *LogMessageFilter `protobuf:"bytes,12,opt,name=filter,proto3" json:"filter,omitempty"`
 }
 
 func (x *LogMessageListRequest) Reset() {
 	*x = LogMessageListRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[14]
+		mi := &file_pls_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -861,7 +1545,7 @@ func (x *LogMessageListRequest) String() string {
 func (*LogMessageListRequest) ProtoMessage() {}
 
 func (x *LogMessageListRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[14]
+	mi := &file_pls_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -874,7 +1558,7 @@ func (x *LogMessageListRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogMessageListRequest.ProtoReflect.Descriptor instead.
 func (*LogMessageListRequest) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{14}
+	return file_pls_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *LogMessageListRequest) GetLogId() string {
@@ -905,6 +1589,132 @@ func (x *LogMessageListRequest) GetEndAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *LogMessageListRequest) GetAfterSequence() int64 {
+	if x != nil {
+		return x.AfterSequence
+	}
+	return 0
+}
+
+func (x *LogMessageListRequest) GetAfterMessageId() string {
+	if x != nil {
+		return x.AfterMessageId
+	}
+	return ""
+}
+
+func (x *LogMessageListRequest) GetLogIds() []string {
+	if x != nil {
+		return x.LogIds
+	}
+	return nil
+}
+
+func (x *LogMessageListRequest) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *LogMessageListRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *LogMessageListRequest) GetOrder() LogMessageListRequest_Order {
+	if x != nil {
+		return x.Order
+	}
+	return LogMessageListRequest_ORDER_ASCENDING
+}
+
+func (x *LogMessageListRequest) GetTail() int32 {
+	if x != nil {
+		return x.Tail
+	}
+	return 0
+}
+
+func (x *LogMessageListRequest) GetFilter() *LogMessageFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type LogMessageFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// pattern is the text or regular expression to search for. Payloads that
+	// are not valid UTF-8 never match.
+	Pattern string `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// mode is how the pattern is matched.
+	Mode LogMessageFilter_Mode `protobuf:"varint,2,opt,name=mode,proto3,enum=plspb.LogMessageFilter_Mode" json:"mode,omitempty"`
+	// context_lines is the number of messages before and after each match in
+	// the same log stream to also return, at most 100. Messages outside
+	// start_at and end_at are never returned. It may not be combined with
+	// follow.
+	ContextLines int32 `protobuf:"varint,3,opt,name=context_lines,json=contextLines,proto3" json:"context_lines,omitempty"`
+}
+
+func (x *LogMessageFilter) Reset() {
+	*x = LogMessageFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pls_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogMessageFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogMessageFilter) ProtoMessage() {}
+
+func (x *LogMessageFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_pls_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogMessageFilter.ProtoReflect.Descriptor instead.
+func (*LogMessageFilter) Descriptor() ([]byte, []int) {
+	return file_pls_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *LogMessageFilter) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *LogMessageFilter) GetMode() LogMessageFilter_Mode {
+	if x != nil {
+		return x.Mode
+	}
+	return LogMessageFilter_MODE_SUBSTRING
+}
+
+func (x *LogMessageFilter) GetContextLines() int32 {
+	if x != nil {
+		return x.ContextLines
+	}
+	return 0
+}
+
 type LogMessageListResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -918,12 +1728,17 @@ type LogMessageListResponse struct {
 	Payload []byte `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
 	// timestamp is the time the message was originally received
 	Timestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// sequence is the position of the message in the log stream. Messages
+	// appended before sequences were assigned have a sequence of 0.
+	Sequence int64 `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// log_id is the identifier for the log stream this message belongs to.
+	LogId string `protobuf:"bytes,6,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
 }
 
 func (x *LogMessageListResponse) Reset() {
 	*x = LogMessageListResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pls_proto_msgTypes[15]
+		mi := &file_pls_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -936,7 +1751,7 @@ func (x *LogMessageListResponse) String() string {
 func (*LogMessageListResponse) ProtoMessage() {}
 
 func (x *LogMessageListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pls_proto_msgTypes[15]
+	mi := &file_pls_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -949,7 +1764,7 @@ func (x *LogMessageListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LogMessageListResponse.ProtoReflect.Descriptor instead.
 func (*LogMessageListResponse) Descriptor() ([]byte, []int) {
-	return file_pls_proto_rawDescGZIP(), []int{15}
+	return file_pls_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *LogMessageListResponse) GetLogMessageId() string {
@@ -980,91 +1795,180 @@ func (x *LogMessageListResponse) GetTimestamp() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *LogMessageListResponse) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *LogMessageListResponse) GetLogId() string {
+	if x != nil {
+		return x.LogId
+	}
+	return ""
+}
+
 var File_pls_proto protoreflect.FileDescriptor
 
 var file_pls_proto_rawDesc = []byte{
 	0x0a, 0x09, 0x70, 0x6c, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x70, 0x6c, 0x73,
 	0x70, 0x62, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
 	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x22, 0x6f, 0x0a, 0x16, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61,
-	0x6c, 0x49, 0x73, 0x73, 0x75, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a,
-	0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52,
-	0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70,
-	0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72,
-	0x65, 0x73, 0x41, 0x74, 0x22, 0xab, 0x01, 0x0a, 0x17, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74,
-	0x69, 0x61, 0x6c, 0x49, 0x73, 0x73, 0x75, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x6f, 0x74, 0x6f, 0x22, 0x40, 0x0a, 0x19, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61,
+	0x6c, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
 	0x12, 0x23, 0x0a, 0x0d, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x69,
 	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74,
-	0x69, 0x61, 0x6c, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74,
-	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74,
-	0x73, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
-	0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x14, 0x0a, 0x05,
-	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b,
-	0x65, 0x6e, 0x22, 0x7a, 0x0a, 0x18, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c,
-	0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23,
-	0x0a, 0x0d, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61,
-	0x6c, 0x49, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61,
-	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
-	0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x22, 0x91,
-	0x01, 0x0a, 0x19, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52, 0x65, 0x66,
-	0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x0d,
+	0x69, 0x61, 0x6c, 0x49, 0x64, 0x22, 0xe9, 0x01, 0x0a, 0x1a, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e,
+	0x74, 0x69, 0x61, 0x6c, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69,
+	0x61, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x65,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x72,
+	0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61,
+	0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x1a, 0x0a,
+	0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x6f,
+	0x70, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65,
+	0x73, 0x22, 0x87, 0x01, 0x0a, 0x16, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c,
+	0x49, 0x73, 0x73, 0x75, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65,
+	0x73, 0x41, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x22, 0xc3, 0x01, 0x0a, 0x17,
+	0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x73, 0x73, 0x75, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x72, 0x65, 0x64, 0x65,
+	0x6e, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65,
+	0x73, 0x41, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x6f,
+	0x70, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65,
+	0x73, 0x22, 0x7a, 0x0a, 0x18, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52,
+	0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a,
+	0x0d, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c,
+	0x49, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x22, 0x91, 0x01,
+	0x0a, 0x19, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52, 0x65, 0x66, 0x72,
+	0x65, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x63,
+	0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x64,
+	0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x22, 0x3e, 0x0a, 0x17, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52,
+	0x65, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x0d,
 	0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
 	0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x49,
-	0x64, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
-	0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x14, 0x0a, 0x05,
-	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b,
-	0x65, 0x6e, 0x22, 0x3e, 0x0a, 0x17, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c,
-	0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a,
+	0x64, 0x22, 0x3f, 0x0a, 0x18, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52,
+	0x65, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a,
 	0x0d, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01,
 	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c,
-	0x49, 0x64, 0x22, 0x3f, 0x0a, 0x18, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c,
-	0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23,
-	0x0a, 0x0d, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61,
-	0x6c, 0x49, 0x64, 0x22, 0x40, 0x0a, 0x10, 0x4c, 0x6f, 0x67, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65,
-	0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78,
-	0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x2a, 0x0a, 0x11, 0x4c, 0x6f, 0x67, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f,
-	0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49,
-	0x64, 0x22, 0x29, 0x0a, 0x10, 0x4c, 0x6f, 0x67, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x22, 0x13, 0x0a, 0x11,
-	0x4c, 0x6f, 0x67, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x22, 0x2c, 0x0a, 0x0e, 0x4c, 0x6f, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x22,
-	0x56, 0x0a, 0x0f, 0x4c, 0x6f, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e,
-	0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74,
-	0x65, 0x78, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0xa3, 0x01, 0x0a, 0x17, 0x4c, 0x6f, 0x67, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65,
-	0x64, 0x69, 0x61, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
-	0x6d, 0x65, 0x64, 0x69, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79,
-	0x6c, 0x6f, 0x61, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c,
-	0x6f, 0x61, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
-	0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x57, 0x0a,
-	0x18, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e,
-	0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67,
+	0x49, 0x64, 0x22, 0x40, 0x0a, 0x10, 0x4c, 0x6f, 0x67, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x22, 0x2a, 0x0a, 0x11, 0x4c, 0x6f, 0x67, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67,
 	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64,
-	0x12, 0x24, 0x0a, 0x0e, 0x6c, 0x6f, 0x67, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f,
-	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x6f, 0x67, 0x4d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x22, 0xb0, 0x01, 0x0a, 0x15, 0x4c, 0x6f, 0x67, 0x4d, 0x65,
+	0x22, 0x29, 0x0a, 0x10, 0x4c, 0x6f, 0x67, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x22, 0x13, 0x0a, 0x11, 0x4c,
+	0x6f, 0x67, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x90, 0x01, 0x0a, 0x0e, 0x4c, 0x6f, 0x67, 0x53, 0x65, 0x61, 0x6c, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x12, 0x20, 0x0a, 0x09, 0x65, 0x78,
+	0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52,
+	0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x88, 0x01, 0x01, 0x12, 0x37, 0x0a, 0x09,
+	0x73, 0x65, 0x61, 0x6c, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x73, 0x65, 0x61,
+	0x6c, 0x65, 0x64, 0x41, 0x74, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63,
+	0x6f, 0x64, 0x65, 0x22, 0x86, 0x01, 0x0a, 0x0f, 0x4c, 0x6f, 0x67, 0x53, 0x65, 0x61, 0x6c, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x12, 0x37,
+	0x0a, 0x09, 0x73, 0x65, 0x61, 0x6c, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x73,
+	0x65, 0x61, 0x6c, 0x65, 0x64, 0x41, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x61, 0x73, 0x74, 0x5f,
+	0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c,
+	0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x22, 0x2c, 0x0a, 0x0e,
+	0x4c, 0x6f, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x22, 0xd7, 0x01, 0x0a, 0x0f, 0x4c,
+	0x6f, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x15,
+	0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x6c, 0x6f, 0x67, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x61, 0x6c, 0x65, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x65, 0x61, 0x6c, 0x65, 0x64, 0x12, 0x37, 0x0a, 0x09, 0x73,
+	0x65, 0x61, 0x6c, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x73, 0x65, 0x61, 0x6c,
+	0x65, 0x64, 0x41, 0x74, 0x12, 0x20, 0x0a, 0x09, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43,
+	0x6f, 0x64, 0x65, 0x88, 0x01, 0x01, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x65, 0x78, 0x69, 0x74, 0x5f,
+	0x63, 0x6f, 0x64, 0x65, 0x22, 0xcc, 0x01, 0x0a, 0x17, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64, 0x69, 0x61,
+	0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
+	0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x64,
+	0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0e, 0x69, 0x64, 0x65, 0x6d, 0x70, 0x6f, 0x74, 0x65, 0x6e, 0x63, 0x79,
+	0x4b, 0x65, 0x79, 0x22, 0x73, 0x0a, 0x18, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x6c, 0x6f, 0x67, 0x5f, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x6c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08,
+	0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x22, 0x5a, 0x0a, 0x1c, 0x4c, 0x6f, 0x67, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3a, 0x0a, 0x08, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x70, 0x6c, 0x73,
+	0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70,
+	0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x73, 0x22, 0xb5, 0x01, 0x0a, 0x16, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
+	0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x12, 0x24, 0x0a, 0x0e, 0x6c, 0x6f, 0x67, 0x5f, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x6c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x09, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x22, 0x58, 0x0a, 0x1d,
+	0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a,
+	0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d,
+	0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0xfd, 0x03, 0x0a, 0x15, 0x4c, 0x6f, 0x67, 0x4d, 0x65,
 	0x73, 0x73, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
 	0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6f, 0x6c, 0x6c, 0x6f,
@@ -1075,58 +1979,116 @@ var file_pls_proto_rawDesc = []byte{
 	0x74, 0x61, 0x72, 0x74, 0x41, 0x74, 0x12, 0x31, 0x0a, 0x06, 0x65, 0x6e, 0x64, 0x5f, 0x61, 0x74,
 	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
-	0x6d, 0x70, 0x52, 0x05, 0x65, 0x6e, 0x64, 0x41, 0x74, 0x22, 0xb1, 0x01, 0x0a, 0x16, 0x4c, 0x6f,
-	0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x0e, 0x6c, 0x6f, 0x67, 0x5f, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x6f,
-	0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65,
-	0x64, 0x69, 0x61, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
-	0x6d, 0x65, 0x64, 0x69, 0x61, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79,
-	0x6c, 0x6f, 0x61, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c,
-	0x6f, 0x61, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
-	0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x32, 0xed, 0x01,
-	0x0a, 0x0a, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x46, 0x0a, 0x05,
-	0x49, 0x73, 0x73, 0x75, 0x65, 0x12, 0x1d, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72,
-	0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x73, 0x73, 0x75, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65,
-	0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x73, 0x73, 0x75, 0x65, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x07, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x12,
-	0x1f, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69,
-	0x61, 0x6c, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x20, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74,
-	0x69, 0x61, 0x6c, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x49, 0x0a, 0x06, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x12, 0x1e, 0x2e, 0x70,
-	0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52,
-	0x65, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x70,
-	0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52,
-	0x65, 0x76, 0x6f, 0x6b, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xd8, 0x02,
-	0x0a, 0x03, 0x4c, 0x6f, 0x67, 0x12, 0x3b, 0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12,
-	0x17, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62,
-	0x2e, 0x4c, 0x6f, 0x67, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x70,
-	0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f,
-	0x67, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x37, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x15, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e,
-	0x4c, 0x6f, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
-	0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x50, 0x0a, 0x0d, 0x4d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x12, 0x1e, 0x2e, 0x70, 0x6c, 0x73, 0x70,
-	0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65,
-	0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x70, 0x6c, 0x73, 0x70,
-	0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65,
-	0x6e, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x0b, 0x4d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x1c, 0x2e, 0x70, 0x6c, 0x73, 0x70,
-	0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e,
-	0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x2b, 0x5a, 0x29, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x75, 0x70, 0x70, 0x65, 0x74, 0x6c, 0x61, 0x62,
-	0x73, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x79, 0x2d, 0x70, 0x6c, 0x73, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
-	0x70, 0x6c, 0x73, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6d, 0x70, 0x52, 0x05, 0x65, 0x6e, 0x64, 0x41, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x66, 0x74,
+	0x65, 0x72, 0x5f, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0d, 0x61, 0x66, 0x74, 0x65, 0x72, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65,
+	0x12, 0x28, 0x0a, 0x10, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x66, 0x74, 0x65,
+	0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x6c, 0x6f,
+	0x67, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x67,
+	0x49, 0x64, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x12, 0x38, 0x0a, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x22, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x52, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x61, 0x69, 0x6c, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x74, 0x61, 0x69,
+	0x6c, 0x12, 0x2f, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x22, 0x32, 0x0a, 0x05, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x13, 0x0a, 0x0f, 0x4f,
+	0x52, 0x44, 0x45, 0x52, 0x5f, 0x41, 0x53, 0x43, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x00,
+	0x12, 0x14, 0x0a, 0x10, 0x4f, 0x52, 0x44, 0x45, 0x52, 0x5f, 0x44, 0x45, 0x53, 0x43, 0x45, 0x4e,
+	0x44, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x22, 0xca, 0x01, 0x0a, 0x10, 0x4c, 0x6f, 0x67, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61,
+	0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x30, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x4d, 0x6f, 0x64,
+	0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x78, 0x74, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x4c, 0x69, 0x6e, 0x65, 0x73, 0x22, 0x45, 0x0a, 0x04,
+	0x4d, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x0e, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x53, 0x55, 0x42,
+	0x53, 0x54, 0x52, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x4d, 0x4f, 0x44, 0x45,
+	0x5f, 0x43, 0x41, 0x53, 0x45, 0x5f, 0x49, 0x4e, 0x53, 0x45, 0x4e, 0x53, 0x49, 0x54, 0x49, 0x56,
+	0x45, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x52, 0x45, 0x47, 0x45,
+	0x58, 0x10, 0x02, 0x22, 0xe4, 0x01, 0x0a, 0x16, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24,
+	0x0a, 0x0e, 0x6c, 0x6f, 0x67, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x38, 0x0a,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x63, 0x65, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x32, 0xbe, 0x02, 0x0a, 0x0a, 0x43,
+	0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x4f, 0x0a, 0x08, 0x44, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x12, 0x20, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72,
+	0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e,
+	0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x05, 0x49, 0x73,
+	0x73, 0x75, 0x65, 0x12, 0x1d, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x64,
+	0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x73, 0x73, 0x75, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65,
+	0x6e, 0x74, 0x69, 0x61, 0x6c, 0x49, 0x73, 0x73, 0x75, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x4c, 0x0a, 0x07, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x12, 0x1f, 0x2e,
+	0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c,
+	0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20,
+	0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61,
+	0x6c, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x49, 0x0a, 0x06, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x12, 0x1e, 0x2e, 0x70, 0x6c, 0x73,
+	0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52, 0x65, 0x76,
+	0x6f, 0x6b, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x70, 0x6c, 0x73,
+	0x70, 0x62, 0x2e, 0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52, 0x65, 0x76,
+	0x6f, 0x6b, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xcf, 0x04, 0x0a, 0x03,
+	0x4c, 0x6f, 0x67, 0x12, 0x3b, 0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x17, 0x2e,
+	0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c,
+	0x6f, 0x67, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x3b, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x70, 0x6c, 0x73,
+	0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a,
+	0x04, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x15, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f,
+	0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70,
+	0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x50, 0x0a, 0x0d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x12, 0x1e, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e,
+	0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e,
+	0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5f, 0x0a, 0x12, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x23,
+	0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5d, 0x0a, 0x13, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x12, 0x1e, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x24, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x12, 0x35, 0x0a, 0x04, 0x53, 0x65, 0x61, 0x6c,
+	0x12, 0x15, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x53, 0x65, 0x61, 0x6c,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e,
+	0x4c, 0x6f, 0x67, 0x53, 0x65, 0x61, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x4c, 0x0a, 0x0b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x1c,
+	0x2e, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x70,
+	0x6c, 0x73, 0x70, 0x62, 0x2e, 0x4c, 0x6f, 0x67, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x2b, 0x5a,
+	0x29, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x75, 0x70, 0x70,
+	0x65, 0x74, 0x6c, 0x61, 0x62, 0x73, 0x2f, 0x72, 0x65, 0x6c, 0x61, 0x79, 0x2d, 0x70, 0x6c, 0x73,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x6c, 0x73, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -1141,56 +2103,84 @@ func file_pls_proto_rawDescGZIP() []byte {
 	return file_pls_proto_rawDescData
 }
 
-var file_pls_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_pls_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_pls_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_pls_proto_goTypes = []interface{}{
-	(*CredentialIssueRequest)(nil),    // 0: plspb.CredentialIssueRequest
-	(*CredentialIssueResponse)(nil),   // 1: plspb.CredentialIssueResponse
-	(*CredentialRefreshRequest)(nil),  // 2: plspb.CredentialRefreshRequest
-	(*CredentialRefreshResponse)(nil), // 3: plspb.CredentialRefreshResponse
-	(*CredentialRevokeRequest)(nil),   // 4: plspb.CredentialRevokeRequest
-	(*CredentialRevokeResponse)(nil),  // 5: plspb.CredentialRevokeResponse
-	(*LogCreateRequest)(nil),          // 6: plspb.LogCreateRequest
-	(*LogCreateResponse)(nil),         // 7: plspb.LogCreateResponse
-	(*LogDeleteRequest)(nil),          // 8: plspb.LogDeleteRequest
-	(*LogDeleteResponse)(nil),         // 9: plspb.LogDeleteResponse
-	(*LogListRequest)(nil),            // 10: plspb.LogListRequest
-	(*LogListResponse)(nil),           // 11: plspb.LogListResponse
-	(*LogMessageAppendRequest)(nil),   // 12: plspb.LogMessageAppendRequest
-	(*LogMessageAppendResponse)(nil),  // 13: plspb.LogMessageAppendResponse
-	(*LogMessageListRequest)(nil),     // 14: plspb.LogMessageListRequest
-	(*LogMessageListResponse)(nil),    // 15: plspb.LogMessageListResponse
-	(*timestamppb.Timestamp)(nil),     // 16: google.protobuf.Timestamp
+	(LogMessageListRequest_Order)(0),      // 0: plspb.LogMessageListRequest.Order
+	(LogMessageFilter_Mode)(0),            // 1: plspb.LogMessageFilter.Mode
+	(*CredentialDescribeRequest)(nil),     // 2: plspb.CredentialDescribeRequest
+	(*CredentialDescribeResponse)(nil),    // 3: plspb.CredentialDescribeResponse
+	(*CredentialIssueRequest)(nil),        // 4: plspb.CredentialIssueRequest
+	(*CredentialIssueResponse)(nil),       // 5: plspb.CredentialIssueResponse
+	(*CredentialRefreshRequest)(nil),      // 6: plspb.CredentialRefreshRequest
+	(*CredentialRefreshResponse)(nil),     // 7: plspb.CredentialRefreshResponse
+	(*CredentialRevokeRequest)(nil),       // 8: plspb.CredentialRevokeRequest
+	(*CredentialRevokeResponse)(nil),      // 9: plspb.CredentialRevokeResponse
+	(*LogCreateRequest)(nil),              // 10: plspb.LogCreateRequest
+	(*LogCreateResponse)(nil),             // 11: plspb.LogCreateResponse
+	(*LogDeleteRequest)(nil),              // 12: plspb.LogDeleteRequest
+	(*LogDeleteResponse)(nil),             // 13: plspb.LogDeleteResponse
+	(*LogSealRequest)(nil),                // 14: plspb.LogSealRequest
+	(*LogSealResponse)(nil),               // 15: plspb.LogSealResponse
+	(*LogListRequest)(nil),                // 16: plspb.LogListRequest
+	(*LogListResponse)(nil),               // 17: plspb.LogListResponse
+	(*LogMessageAppendRequest)(nil),       // 18: plspb.LogMessageAppendRequest
+	(*LogMessageAppendResponse)(nil),      // 19: plspb.LogMessageAppendResponse
+	(*LogMessageAppendBatchRequest)(nil),  // 20: plspb.LogMessageAppendBatchRequest
+	(*LogMessageAppendResult)(nil),        // 21: plspb.LogMessageAppendResult
+	(*LogMessageAppendBatchResponse)(nil), // 22: plspb.LogMessageAppendBatchResponse
+	(*LogMessageListRequest)(nil),         // 23: plspb.LogMessageListRequest
+	(*LogMessageFilter)(nil),              // 24: plspb.LogMessageFilter
+	(*LogMessageListResponse)(nil),        // 25: plspb.LogMessageListResponse
+	(*timestamppb.Timestamp)(nil),         // 26: google.protobuf.Timestamp
 }
 var file_pls_proto_depIdxs = []int32{
-	16, // 0: plspb.CredentialIssueRequest.expires_at:type_name -> google.protobuf.Timestamp
-	16, // 1: plspb.CredentialIssueResponse.expires_at:type_name -> google.protobuf.Timestamp
-	16, // 2: plspb.CredentialRefreshRequest.expires_at:type_name -> google.protobuf.Timestamp
-	16, // 3: plspb.CredentialRefreshResponse.expires_at:type_name -> google.protobuf.Timestamp
-	16, // 4: plspb.LogMessageAppendRequest.timestamp:type_name -> google.protobuf.Timestamp
-	16, // 5: plspb.LogMessageListRequest.start_at:type_name -> google.protobuf.Timestamp
-	16, // 6: plspb.LogMessageListRequest.end_at:type_name -> google.protobuf.Timestamp
-	16, // 7: plspb.LogMessageListResponse.timestamp:type_name -> google.protobuf.Timestamp
-	0,  // 8: plspb.Credential.Issue:input_type -> plspb.CredentialIssueRequest
-	2,  // 9: plspb.Credential.Refresh:input_type -> plspb.CredentialRefreshRequest
-	4,  // 10: plspb.Credential.Revoke:input_type -> plspb.CredentialRevokeRequest
-	6,  // 11: plspb.Log.Create:input_type -> plspb.LogCreateRequest
-	8,  // 12: plspb.Log.Delete:input_type -> plspb.LogDeleteRequest
-	10, // 13: plspb.Log.List:input_type -> plspb.LogListRequest
-	12, // 14: plspb.Log.MessageAppend:input_type -> plspb.LogMessageAppendRequest
-	14, // 15: plspb.Log.MessageList:input_type -> plspb.LogMessageListRequest
-	1,  // 16: plspb.Credential.Issue:output_type -> plspb.CredentialIssueResponse
-	3,  // 17: plspb.Credential.Refresh:output_type -> plspb.CredentialRefreshResponse
-	5,  // 18: plspb.Credential.Revoke:output_type -> plspb.CredentialRevokeResponse
-	7,  // 19: plspb.Log.Create:output_type -> plspb.LogCreateResponse
-	9,  // 20: plspb.Log.Delete:output_type -> plspb.LogDeleteResponse
-	11, // 21: plspb.Log.List:output_type -> plspb.LogListResponse
-	13, // 22: plspb.Log.MessageAppend:output_type -> plspb.LogMessageAppendResponse
-	15, // 23: plspb.Log.MessageList:output_type -> plspb.LogMessageListResponse
-	16, // [16:24] is the sub-list for method output_type
-	8,  // [8:16] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	26, // 0: plspb.CredentialDescribeResponse.expires_at:type_name -> google.protobuf.Timestamp
+	26, // 1: plspb.CredentialIssueRequest.expires_at:type_name -> google.protobuf.Timestamp
+	26, // 2: plspb.CredentialIssueResponse.expires_at:type_name -> google.protobuf.Timestamp
+	26, // 3: plspb.CredentialRefreshRequest.expires_at:type_name -> google.protobuf.Timestamp
+	26, // 4: plspb.CredentialRefreshResponse.expires_at:type_name -> google.protobuf.Timestamp
+	26, // 5: plspb.LogSealRequest.sealed_at:type_name -> google.protobuf.Timestamp
+	26, // 6: plspb.LogSealResponse.sealed_at:type_name -> google.protobuf.Timestamp
+	26, // 7: plspb.LogListResponse.sealed_at:type_name -> google.protobuf.Timestamp
+	26, // 8: plspb.LogMessageAppendRequest.timestamp:type_name -> google.protobuf.Timestamp
+	18, // 9: plspb.LogMessageAppendBatchRequest.messages:type_name -> plspb.LogMessageAppendRequest
+	21, // 10: plspb.LogMessageAppendBatchResponse.results:type_name -> plspb.LogMessageAppendResult
+	26, // 11: plspb.LogMessageListRequest.start_at:type_name -> google.protobuf.Timestamp
+	26, // 12: plspb.LogMessageListRequest.end_at:type_name -> google.protobuf.Timestamp
+	0,  // 13: plspb.LogMessageListRequest.order:type_name -> plspb.LogMessageListRequest.Order
+	24, // 14: plspb.LogMessageListRequest.filter:type_name -> plspb.LogMessageFilter
+	1,  // 15: plspb.LogMessageFilter.mode:type_name -> plspb.LogMessageFilter.Mode
+	26, // 16: plspb.LogMessageListResponse.timestamp:type_name -> google.protobuf.Timestamp
+	2,  // 17: plspb.Credential.Describe:input_type -> plspb.CredentialDescribeRequest
+	4,  // 18: plspb.Credential.Issue:input_type -> plspb.CredentialIssueRequest
+	6,  // 19: plspb.Credential.Refresh:input_type -> plspb.CredentialRefreshRequest
+	8,  // 20: plspb.Credential.Revoke:input_type -> plspb.CredentialRevokeRequest
+	10, // 21: plspb.Log.Create:input_type -> plspb.LogCreateRequest
+	12, // 22: plspb.Log.Delete:input_type -> plspb.LogDeleteRequest
+	16, // 23: plspb.Log.List:input_type -> plspb.LogListRequest
+	18, // 24: plspb.Log.MessageAppend:input_type -> plspb.LogMessageAppendRequest
+	20, // 25: plspb.Log.MessageAppendBatch:input_type -> plspb.LogMessageAppendBatchRequest
+	18, // 26: plspb.Log.MessageAppendStream:input_type -> plspb.LogMessageAppendRequest
+	14, // 27: plspb.Log.Seal:input_type -> plspb.LogSealRequest
+	23, // 28: plspb.Log.MessageList:input_type -> plspb.LogMessageListRequest
+	3,  // 29: plspb.Credential.Describe:output_type -> plspb.CredentialDescribeResponse
+	5,  // 30: plspb.Credential.Issue:output_type -> plspb.CredentialIssueResponse
+	7,  // 31: plspb.Credential.Refresh:output_type -> plspb.CredentialRefreshResponse
+	9,  // 32: plspb.Credential.Revoke:output_type -> plspb.CredentialRevokeResponse
+	11, // 33: plspb.Log.Create:output_type -> plspb.LogCreateResponse
+	13, // 34: plspb.Log.Delete:output_type -> plspb.LogDeleteResponse
+	17, // 35: plspb.Log.List:output_type -> plspb.LogListResponse
+	19, // 36: plspb.Log.MessageAppend:output_type -> plspb.LogMessageAppendResponse
+	22, // 37: plspb.Log.MessageAppendBatch:output_type -> plspb.LogMessageAppendBatchResponse
+	22, // 38: plspb.Log.MessageAppendStream:output_type -> plspb.LogMessageAppendBatchResponse
+	15, // 39: plspb.Log.Seal:output_type -> plspb.LogSealResponse
+	25, // 40: plspb.Log.MessageList:output_type -> plspb.LogMessageListResponse
+	29, // [29:41] is the sub-list for method output_type
+	17, // [17:29] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_pls_proto_init() }
@@ -1200,7 +2190,7 @@ func file_pls_proto_init() {
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_pls_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CredentialIssueRequest); i {
+			switch v := v.(*CredentialDescribeRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1212,7 +2202,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CredentialIssueResponse); i {
+			switch v := v.(*CredentialDescribeResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1224,7 +2214,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CredentialRefreshRequest); i {
+			switch v := v.(*CredentialIssueRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1236,7 +2226,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CredentialRefreshResponse); i {
+			switch v := v.(*CredentialIssueResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1248,7 +2238,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CredentialRevokeRequest); i {
+			switch v := v.(*CredentialRefreshRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1260,7 +2250,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CredentialRevokeResponse); i {
+			switch v := v.(*CredentialRefreshResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1272,7 +2262,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogCreateRequest); i {
+			switch v := v.(*CredentialRevokeRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1284,7 +2274,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogCreateResponse); i {
+			switch v := v.(*CredentialRevokeResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1296,7 +2286,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogDeleteRequest); i {
+			switch v := v.(*LogCreateRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1308,7 +2298,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogDeleteResponse); i {
+			switch v := v.(*LogCreateResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1320,7 +2310,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogListRequest); i {
+			switch v := v.(*LogDeleteRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1332,7 +2322,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogListResponse); i {
+			switch v := v.(*LogDeleteResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1344,7 +2334,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogMessageAppendRequest); i {
+			switch v := v.(*LogSealRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1356,7 +2346,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogMessageAppendResponse); i {
+			switch v := v.(*LogSealResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1368,7 +2358,7 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogMessageListRequest); i {
+			switch v := v.(*LogListRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1380,6 +2370,102 @@ func file_pls_proto_init() {
 			}
 		}
 		file_pls_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogListResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pls_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogMessageAppendRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pls_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogMessageAppendResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pls_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogMessageAppendBatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pls_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogMessageAppendResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pls_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogMessageAppendBatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pls_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogMessageListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pls_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogMessageFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pls_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*LogMessageListResponse); i {
 			case 0:
 				return &v.state
@@ -1392,18 +2478,21 @@ func file_pls_proto_init() {
 			}
 		}
 	}
+	file_pls_proto_msgTypes[12].OneofWrappers = []interface{}{}
+	file_pls_proto_msgTypes[15].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pls_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   16,
+			NumEnums:      2,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
 		GoTypes:           file_pls_proto_goTypes,
 		DependencyIndexes: file_pls_proto_depIdxs,
+		EnumInfos:         file_pls_proto_enumTypes,
 		MessageInfos:      file_pls_proto_msgTypes,
 	}.Build()
 	File_pls_proto = out.File
@@ -22,6 +22,9 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CredentialClient interface {
+	// Describe returns information about a credential. If no credential is
+	// specified, the credential authenticating this request is described.
+	Describe(ctx context.Context, in *CredentialDescribeRequest, opts ...grpc.CallOption) (*CredentialDescribeResponse, error)
 	// Issue creates a new token.
 	//
 	// If this request is authorized, a child token is created. The child token's
@@ -45,6 +48,15 @@ func NewCredentialClient(cc grpc.ClientConnInterface) CredentialClient {
 	return &credentialClient{cc}
 }
 
+func (c *credentialClient) Describe(ctx context.Context, in *CredentialDescribeRequest, opts ...grpc.CallOption) (*CredentialDescribeResponse, error) {
+	out := new(CredentialDescribeResponse)
+	err := c.cc.Invoke(ctx, "/plspb.Credential/Describe", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *credentialClient) Issue(ctx context.Context, in *CredentialIssueRequest, opts ...grpc.CallOption) (*CredentialIssueResponse, error) {
 	out := new(CredentialIssueResponse)
 	err := c.cc.Invoke(ctx, "/plspb.Credential/Issue", in, out, opts...)
@@ -76,6 +88,9 @@ func (c *credentialClient) Revoke(ctx context.Context, in *CredentialRevokeReque
 // All implementations must embed UnimplementedCredentialServer
 // for forward compatibility
 type CredentialServer interface {
+	// Describe returns information about a credential. If no credential is
+	// specified, the credential authenticating this request is described.
+	Describe(context.Context, *CredentialDescribeRequest) (*CredentialDescribeResponse, error)
 	// Issue creates a new token.
 	//
 	// If this request is authorized, a child token is created. The child token's
@@ -96,6 +111,9 @@ type CredentialServer interface {
 type UnimplementedCredentialServer struct {
 }
 
+func (UnimplementedCredentialServer) Describe(context.Context, *CredentialDescribeRequest) (*CredentialDescribeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Describe not implemented")
+}
 func (UnimplementedCredentialServer) Issue(context.Context, *CredentialIssueRequest) (*CredentialIssueResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Issue not implemented")
 }
@@ -118,6 +136,24 @@ func RegisterCredentialServer(s grpc.ServiceRegistrar, srv CredentialServer) {
 	s.RegisterService(&Credential_ServiceDesc, srv)
 }
 
+func _Credential_Describe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CredentialDescribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CredentialServer).Describe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plspb.Credential/Describe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CredentialServer).Describe(ctx, req.(*CredentialDescribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Credential_Issue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CredentialIssueRequest)
 	if err := dec(in); err != nil {
@@ -179,6 +215,10 @@ var Credential_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "plspb.Credential",
 	HandlerType: (*CredentialServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Describe",
+			Handler:    _Credential_Describe_Handler,
+		},
 		{
 			MethodName: "Issue",
 			Handler:    _Credential_Issue_Handler,
@@ -209,13 +249,34 @@ type LogClient interface {
 	// List enumerates the log stream the authenticated credential has access to.
 	List(ctx context.Context, in *LogListRequest, opts ...grpc.CallOption) (Log_ListClient, error)
 	// MessageAppend adds a new message to the log stream. If the payload is
-	// larger than 2MB, this RPC will return INVALID_ARGUMENT. If the service
+	// larger than the configured limit (2MB by default), or does not conform
+	// to its media type, this RPC will return INVALID_ARGUMENT. If the service
 	// needs to rate-limit this request, this RPC will return RESOURCE_EXHAUSTED
 	// and additional information will be available in the QuotaFailure and
-	// RetryInfo messages.
+	// RetryInfo messages. If the log stream has been sealed, this RPC will
+	// return FAILED_PRECONDITION.
 	MessageAppend(ctx context.Context, in *LogMessageAppendRequest, opts ...grpc.CallOption) (*LogMessageAppendResponse, error)
+	// MessageAppendBatch adds several messages, possibly to different log
+	// streams, in a single request. Each message is validated, authorized and
+	// rate-limited independently, so some messages may be appended while
+	// others fail. The results are returned in the order of the request.
+	MessageAppendBatch(ctx context.Context, in *LogMessageAppendBatchRequest, opts ...grpc.CallOption) (*LogMessageAppendBatchResponse, error)
+	// MessageAppendStream adds each message sent on the stream, as
+	// MessageAppendBatch does. Messages are stored in batches as they arrive,
+	// and the results for every message are returned when the client closes
+	// the stream.
+	MessageAppendStream(ctx context.Context, opts ...grpc.CallOption) (Log_MessageAppendStreamClient, error)
+	// Seal marks a log stream complete. Once sealed, appends to the log stream
+	// return FAILED_PRECONDITION, and MessageList requests that follow it end
+	// after sending the remaining messages. A log stream can only be sealed
+	// once; sealing it again returns FAILED_PRECONDITION.
+	Seal(ctx context.Context, in *LogSealRequest, opts ...grpc.CallOption) (*LogSealResponse, error)
 	// MessageList retrieves part or all of the messages in a log stream.
-	// Messages are returned in the order received by the service.
+	// Messages are returned in sequence order, which is the order they were
+	// stored by the service. When several log streams, or every log stream in a
+	// context, are requested, their messages are merged into a single stream
+	// ordered by timestamp and then sequence, and each message is tagged with
+	// the log stream it belongs to.
 	MessageList(ctx context.Context, in *LogMessageListRequest, opts ...grpc.CallOption) (Log_MessageListClient, error)
 }
 
@@ -286,8 +347,60 @@ func (c *logClient) MessageAppend(ctx context.Context, in *LogMessageAppendReque
 	return out, nil
 }
 
+func (c *logClient) MessageAppendBatch(ctx context.Context, in *LogMessageAppendBatchRequest, opts ...grpc.CallOption) (*LogMessageAppendBatchResponse, error) {
+	out := new(LogMessageAppendBatchResponse)
+	err := c.cc.Invoke(ctx, "/plspb.Log/MessageAppendBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logClient) MessageAppendStream(ctx context.Context, opts ...grpc.CallOption) (Log_MessageAppendStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Log_ServiceDesc.Streams[1], "/plspb.Log/MessageAppendStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &logMessageAppendStreamClient{stream}
+	return x, nil
+}
+
+type Log_MessageAppendStreamClient interface {
+	Send(*LogMessageAppendRequest) error
+	CloseAndRecv() (*LogMessageAppendBatchResponse, error)
+	grpc.ClientStream
+}
+
+type logMessageAppendStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *logMessageAppendStreamClient) Send(m *LogMessageAppendRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *logMessageAppendStreamClient) CloseAndRecv() (*LogMessageAppendBatchResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(LogMessageAppendBatchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *logClient) Seal(ctx context.Context, in *LogSealRequest, opts ...grpc.CallOption) (*LogSealResponse, error) {
+	out := new(LogSealResponse)
+	err := c.cc.Invoke(ctx, "/plspb.Log/Seal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *logClient) MessageList(ctx context.Context, in *LogMessageListRequest, opts ...grpc.CallOption) (Log_MessageListClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Log_ServiceDesc.Streams[1], "/plspb.Log/MessageList", opts...)
+	stream, err := c.cc.NewStream(ctx, &Log_ServiceDesc.Streams[2], "/plspb.Log/MessageList", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -331,13 +444,34 @@ type LogServer interface {
 	// List enumerates the log stream the authenticated credential has access to.
 	List(*LogListRequest, Log_ListServer) error
 	// MessageAppend adds a new message to the log stream. If the payload is
-	// larger than 2MB, this RPC will return INVALID_ARGUMENT. If the service
+	// larger than the configured limit (2MB by default), or does not conform
+	// to its media type, this RPC will return INVALID_ARGUMENT. If the service
 	// needs to rate-limit this request, this RPC will return RESOURCE_EXHAUSTED
 	// and additional information will be available in the QuotaFailure and
-	// RetryInfo messages.
+	// RetryInfo messages. If the log stream has been sealed, this RPC will
+	// return FAILED_PRECONDITION.
 	MessageAppend(context.Context, *LogMessageAppendRequest) (*LogMessageAppendResponse, error)
+	// MessageAppendBatch adds several messages, possibly to different log
+	// streams, in a single request. Each message is validated, authorized and
+	// rate-limited independently, so some messages may be appended while
+	// others fail. The results are returned in the order of the request.
+	MessageAppendBatch(context.Context, *LogMessageAppendBatchRequest) (*LogMessageAppendBatchResponse, error)
+	// MessageAppendStream adds each message sent on the stream, as
+	// MessageAppendBatch does. Messages are stored in batches as they arrive,
+	// and the results for every message are returned when the client closes
+	// the stream.
+	MessageAppendStream(Log_MessageAppendStreamServer) error
+	// Seal marks a log stream complete. Once sealed, appends to the log stream
+	// return FAILED_PRECONDITION, and MessageList requests that follow it end
+	// after sending the remaining messages. A log stream can only be sealed
+	// once; sealing it again returns FAILED_PRECONDITION.
+	Seal(context.Context, *LogSealRequest) (*LogSealResponse, error)
 	// MessageList retrieves part or all of the messages in a log stream.
-	// Messages are returned in the order received by the service.
+	// Messages are returned in sequence order, which is the order they were
+	// stored by the service. When several log streams, or every log stream in a
+	// context, are requested, their messages are merged into a single stream
+	// ordered by timestamp and then sequence, and each message is tagged with
+	// the log stream it belongs to.
 	MessageList(*LogMessageListRequest, Log_MessageListServer) error
 	mustEmbedUnimplementedLogServer()
 }
@@ -358,6 +492,15 @@ func (UnimplementedLogServer) List(*LogListRequest, Log_ListServer) error {
 func (UnimplementedLogServer) MessageAppend(context.Context, *LogMessageAppendRequest) (*LogMessageAppendResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MessageAppend not implemented")
 }
+func (UnimplementedLogServer) MessageAppendBatch(context.Context, *LogMessageAppendBatchRequest) (*LogMessageAppendBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MessageAppendBatch not implemented")
+}
+func (UnimplementedLogServer) MessageAppendStream(Log_MessageAppendStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method MessageAppendStream not implemented")
+}
+func (UnimplementedLogServer) Seal(context.Context, *LogSealRequest) (*LogSealResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Seal not implemented")
+}
 func (UnimplementedLogServer) MessageList(*LogMessageListRequest, Log_MessageListServer) error {
 	return status.Errorf(codes.Unimplemented, "method MessageList not implemented")
 }
@@ -449,6 +592,68 @@ func _Log_MessageAppend_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Log_MessageAppendBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogMessageAppendBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogServer).MessageAppendBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plspb.Log/MessageAppendBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogServer).MessageAppendBatch(ctx, req.(*LogMessageAppendBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Log_MessageAppendStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(LogServer).MessageAppendStream(&logMessageAppendStreamServer{stream})
+}
+
+type Log_MessageAppendStreamServer interface {
+	SendAndClose(*LogMessageAppendBatchResponse) error
+	Recv() (*LogMessageAppendRequest, error)
+	grpc.ServerStream
+}
+
+type logMessageAppendStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *logMessageAppendStreamServer) SendAndClose(m *LogMessageAppendBatchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *logMessageAppendStreamServer) Recv() (*LogMessageAppendRequest, error) {
+	m := new(LogMessageAppendRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Log_Seal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogSealRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogServer).Seal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/plspb.Log/Seal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogServer).Seal(ctx, req.(*LogSealRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Log_MessageList_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(LogMessageListRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -489,6 +694,14 @@ var Log_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "MessageAppend",
 			Handler:    _Log_MessageAppend_Handler,
 		},
+		{
+			MethodName: "MessageAppendBatch",
+			Handler:    _Log_MessageAppendBatch_Handler,
+		},
+		{
+			MethodName: "Seal",
+			Handler:    _Log_Seal_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -496,6 +709,11 @@ var Log_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Log_List_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "MessageAppendStream",
+			Handler:       _Log_MessageAppendStream_Handler,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "MessageList",
 			Handler:       _Log_MessageList_Handler,
@@ -0,0 +1,330 @@
+package server
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+)
+
+const (
+	// maxBatchRows and maxBatchBytes bound the messages buffered before they
+	// are stored, keeping each insert well within BigQuery's streaming
+	// limits.
+	maxBatchRows  = 500
+	maxBatchBytes = 5 * 1024 * 1024
+
+	// batchFlushInterval is the longest a streamed message is buffered, so
+	// that long-lived streams remain readable as they are written.
+	batchFlushInterval = time.Second
+)
+
+// messageInserter stores a batch of messages. Errors for individual messages
+// are returned by index; any other error applies to the whole batch.
+type messageInserter func(ctx context.Context, messages []*LogMessage) (map[int]error, error)
+
+// batchLog caches what is needed to append to a log, so that the metadata
+// is read, the append authorized and the key parsed only once per batch.
+type batchLog struct {
+	metadata *model.LogMetadata
+	cipher   model.Cipher
+	err      error
+}
+
+// messageBatch appends many messages, possibly to different logs, buffering
+// them so that they are stored with as few inserts as possible.
+type messageBatch struct {
+	logMetadataManager model.LogMetadataManager
+	keyManager         model.KeyManager
+	mediaTypes         *MediaTypeRegistry
+	rateLimiter        *RateLimiter
+	idempotency        *IdempotencyCache
+	follows            *followHub
+	insert             messageInserter
+
+	logs     map[string]*batchLog
+	results  []*plspb.LogMessageAppendResult
+	pending  []int
+	claims   []*idempotencyClaim
+	buffer   []*LogMessage
+	payloads [][]byte
+	size     int
+}
+
+// Add prepares a message and buffers it, storing the buffered messages if
+// the buffer is full. A message that cannot be appended is recorded as a
+// failed result.
+func (b *messageBatch) Add(ctx context.Context, in *plspb.LogMessageAppendRequest) {
+	result := &plspb.LogMessageAppendResult{
+		LogId: in.GetLogId(),
+	}
+	b.results = append(b.results, result)
+
+	message, claim, previous, err := b.prepare(ctx, in)
+	if err != nil {
+		setResultError(result, err)
+		return
+	} else if previous != nil {
+		result.LogMessageId = previous.logMessageID
+		result.Sequence = previous.sequence
+		return
+	}
+
+	result.LogMessageId = message.LogMessageID
+
+	b.pending = append(b.pending, len(b.results)-1)
+	b.claims = append(b.claims, claim)
+	b.buffer = append(b.buffer, message)
+	b.payloads = append(b.payloads, in.GetPayload())
+	b.size += len(message.EncryptedPayload)
+
+	if len(b.buffer) >= maxBatchRows || b.size >= maxBatchBytes {
+		b.Flush(ctx)
+	}
+}
+
+// Flush assigns sequences to the buffered messages and stores them.
+// Sequences are reserved once per log, in the order the messages were added.
+func (b *messageBatch) Flush(ctx context.Context) {
+	if len(b.buffer) == 0 {
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, message := range b.buffer {
+		counts[message.LogID]++
+	}
+
+	next := make(map[string]int64, len(counts))
+	failed := make(map[string]error)
+	for logID, n := range counts {
+		first, err := b.logMetadataManager.ReserveSequence(ctx, logID, n)
+		if err != nil {
+			failed[logID] = err
+			continue
+		}
+
+		next[logID] = first
+	}
+
+	messages := make([]*LogMessage, 0, len(b.buffer))
+	indices := make([]int, 0, len(b.buffer))
+	claims := make([]*idempotencyClaim, 0, len(b.buffer))
+	payloads := make([][]byte, 0, len(b.buffer))
+	for i, message := range b.buffer {
+		index := b.pending[i]
+
+		if err, ok := failed[message.LogID]; ok {
+			setResultError(b.results[index], err)
+			b.claims[i].Release()
+			continue
+		}
+
+		message.Sequence = next[message.LogID]
+		next[message.LogID]++
+
+		b.results[index].Sequence = message.Sequence
+
+		messages = append(messages, message)
+		indices = append(indices, index)
+		claims = append(claims, b.claims[i])
+		payloads = append(payloads, b.payloads[i])
+	}
+
+	if len(messages) > 0 {
+		var stored []*LogMessage
+		var storedPayloads [][]byte
+
+		rowErrs, err := b.insert(ctx, messages)
+		for i, index := range indices {
+			rerr := err
+			if rerr == nil {
+				rerr = rowErrs[i]
+			}
+
+			if rerr != nil {
+				setResultError(b.results[index], rerr)
+				claims[i].Release()
+				continue
+			}
+
+			claims[i].Complete(&idempotencyRecord{
+				logMessageID: messages[i].LogMessageID,
+				sequence:     messages[i].Sequence,
+			})
+
+			stored = append(stored, messages[i])
+			storedPayloads = append(storedPayloads, payloads[i])
+		}
+
+		b.follows.publishAppended(stored, storedPayloads)
+	}
+
+	b.pending = nil
+	b.claims = nil
+	b.buffer = nil
+	b.payloads = nil
+	b.size = 0
+}
+
+// Response returns the result of every message added, in order. The batch
+// must be flushed first.
+func (b *messageBatch) Response() *plspb.LogMessageAppendBatchResponse {
+	return &plspb.LogMessageAppendBatchResponse{
+		Results: b.results,
+	}
+}
+
+// prepare validates and encrypts a message. If the message was already
+// appended with the same idempotency key, only the previous record is
+// returned.
+func (b *messageBatch) prepare(ctx context.Context, in *plspb.LogMessageAppendRequest) (message *LogMessage, claim *idempotencyClaim, previous *idempotencyRecord, err error) {
+	l := b.log(ctx, in.GetLogId())
+	if l.err != nil {
+		return nil, nil, nil, l.err
+	}
+
+	claim, previous, err = b.idempotency.claim(in.GetLogId(), in.GetIdempotencyKey())
+	if err != nil || previous != nil {
+		return nil, nil, previous, err
+	}
+	defer func() {
+		if err != nil {
+			claim.Release()
+		}
+	}()
+
+	mediaType, err := b.mediaTypes.Validate(in.GetMediaType(), in.GetPayload())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	credential, _ := auth.CredentialFromContext(ctx)
+
+	err = b.rateLimiter.Allow(RateLimitSubject{
+		LogID:        in.GetLogId(),
+		Context:      l.metadata.Log.Context,
+		CredentialID: credential.ID,
+	}, len(in.GetPayload()))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ct, err := l.cipher.Encrypt(in.GetPayload())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ts := time.Now()
+	if in.GetTimestamp() != nil {
+		ts = in.GetTimestamp().AsTime()
+	}
+
+	return &LogMessage{
+		LogID:            in.GetLogId(),
+		LogMessageID:     newLogMessageID(in.GetLogId(), in.GetIdempotencyKey()),
+		Timestamp:        ts,
+		MediaType:        mediaType,
+		EncryptedPayload: ct,
+	}, claim, nil, nil
+}
+
+func (b *messageBatch) log(ctx context.Context, logID string) *batchLog {
+	if l, found := b.logs[logID]; found {
+		return l
+	}
+
+	l := &batchLog{}
+	b.logs[logID] = l
+
+	l.metadata, l.err = b.logMetadataManager.Get(ctx, logID)
+	if l.err != nil {
+		return l
+	}
+
+	if l.err = auth.Authorize(ctx, model.ScopeLogAppend, l.metadata.Log); l.err != nil {
+		return l
+	}
+
+	// Reserving a sequence fails if the log is sealed concurrently; this
+	// only rejects appends to a log already known to be sealed early.
+	if l.metadata.Seal != nil {
+		l.err = manager.ErrLogSealed
+		return l
+	}
+
+	l.cipher, l.err = b.keyManager.Cipher(ctx, l.metadata.Key)
+
+	return l
+}
+
+// appendStream adds every message received on a stream to a batch, storing
+// them at least every batchFlushInterval, and responds with the results
+// once the client closes the stream.
+func appendStream(stream plspb.Log_MessageAppendStreamServer, b *messageBatch) error {
+	ctx := stream.Context()
+
+	type received struct {
+		in  *plspb.LogMessageAppendRequest
+		err error
+	}
+
+	ch := make(chan received, 1)
+	go func() {
+		for {
+			in, err := stream.Recv()
+			ch <- received{in: in, err: err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(batchFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case r := <-ch:
+			if r.err == io.EOF {
+				b.Flush(ctx)
+				return stream.SendAndClose(b.Response())
+			} else if r.err != nil {
+				b.Flush(ctx)
+				return r.err
+			}
+
+			b.Add(ctx, r.in)
+		case <-ticker.C:
+			b.Flush(ctx)
+		}
+	}
+}
+
+func setResultError(result *plspb.LogMessageAppendResult, err error) {
+	s := Status(err)
+
+	result.LogMessageId = ""
+	result.Sequence = 0
+	result.ErrorCode = int32(s.Code())
+	result.ErrorMessage = s.Message()
+}
+
+func newMessageBatch(logMetadataManager model.LogMetadataManager, keyManager model.KeyManager,
+	mediaTypes *MediaTypeRegistry, rateLimiter *RateLimiter, idempotency *IdempotencyCache,
+	follows *followHub, insert messageInserter) *messageBatch {
+	return &messageBatch{
+		logMetadataManager: logMetadataManager,
+		keyManager:         keyManager,
+		mediaTypes:         mediaTypes,
+		rateLimiter:        rateLimiter,
+		idempotency:        idempotency,
+		follows:            follows,
+		insert:             insert,
+		logs:               make(map[string]*batchLog),
+	}
+}
@@ -0,0 +1,220 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/puppetlabs/relay-pls/pkg/test/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type mockAppendService_MessageAppendStreamServer struct {
+	grpc.ServerStream
+	Ctx      context.Context
+	Requests []*plspb.LogMessageAppendRequest
+	Response *plspb.LogMessageAppendBatchResponse
+}
+
+func (mas *mockAppendService_MessageAppendStreamServer) Context() context.Context {
+	return mas.Ctx
+}
+
+func (mas *mockAppendService_MessageAppendStreamServer) Recv() (*plspb.LogMessageAppendRequest, error) {
+	if len(mas.Requests) == 0 {
+		return nil, io.EOF
+	}
+
+	r := mas.Requests[0]
+	mas.Requests = mas.Requests[1:]
+
+	return r, nil
+}
+
+func (mas *mockAppendService_MessageAppendStreamServer) SendAndClose(m *plspb.LogMessageAppendBatchResponse) error {
+	mas.Response = m
+	return nil
+}
+
+func newBatchTestServer(t *testing.T) (plspb.LogServer, context.Context, []*model.LogMetadata) {
+	ctrl := gomock.NewController(t)
+
+	cfg, err := opt.NewConfig()
+	require.NoError(t, err)
+
+	km := manager.NewKeyManager()
+
+	lmm := mock.NewMockLogMetadataManager(ctrl)
+
+	mediaTypes, err := server.NewMediaTypeRegistry(cfg)
+	require.NoError(t, err)
+
+	s := server.NewInMemoryServer(cfg, km, lmm, mediaTypes, server.NewRateLimiter(cfg), server.NewIdempotencyCache(cfg))
+
+	logs := []*model.Log{
+		{Context: uuid.New().String(), Name: "stdout"},
+		{Context: uuid.New().String(), Name: "stdout"},
+	}
+
+	ctx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{logs[0].Context},
+	})
+
+	logMetadata, err := createLogMetadata(ctx, logs, km)
+	require.NoError(t, err)
+
+	// Logs may be sealed while they are read from other goroutines, so every
+	// call returns a copy.
+	var mut sync.Mutex
+
+	for _, lm := range logMetadata {
+		lm := lm
+
+		lmm.EXPECT().Get(gomock.Any(), gomock.Eq(lm.LogID)).DoAndReturn(
+			func(ctx context.Context, id string) (*model.LogMetadata, error) {
+				mut.Lock()
+				defer mut.Unlock()
+
+				c := *lm
+				return &c, nil
+			}).AnyTimes()
+		lmm.EXPECT().Seal(gomock.Any(), gomock.Eq(lm.LogID), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, id string, seal *model.LogSeal) (*model.LogSeal, error) {
+				mut.Lock()
+				defer mut.Unlock()
+
+				if lm.Seal != nil {
+					return nil, manager.ErrLogSealed
+				}
+
+				lm.Seal = seal
+				return seal, nil
+			}).AnyTimes()
+	}
+	lmm.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, manager.ErrLogNotFound).AnyTimes()
+	lmm.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, prefixes []string, match func(string) bool) ([]*model.LogMetadata, error) {
+			mut.Lock()
+			defer mut.Unlock()
+
+			var r []*model.LogMetadata
+			for _, lm := range logMetadata {
+				if match(lm.Log.Context) {
+					c := *lm
+					r = append(r, &c)
+				}
+			}
+
+			return r, nil
+		}).AnyTimes()
+	lmm.EXPECT().ListContext(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, logContext string) ([]*model.LogMetadata, error) {
+			mut.Lock()
+			defer mut.Unlock()
+
+			var r []*model.LogMetadata
+			for _, lm := range logMetadata {
+				if lm.Log.Context == logContext {
+					c := *lm
+					r = append(r, &c)
+				}
+			}
+
+			return r, nil
+		}).AnyTimes()
+	expectSequences(lmm)
+
+	return s, ctx, logMetadata
+}
+
+func TestInMemoryServerMessageAppendBatch(t *testing.T) {
+	s, ctx, logMetadata := newBatchTestServer(t)
+
+	resp, err := s.MessageAppendBatch(ctx, &plspb.LogMessageAppendBatchRequest{
+		Messages: []*plspb.LogMessageAppendRequest{
+			{LogId: logMetadata[0].LogID, Payload: []byte("first")},
+			{LogId: logMetadata[1].LogID, Payload: []byte("not granted")},
+			{LogId: uuid.New().String(), Payload: []byte("missing")},
+			{LogId: logMetadata[0].LogID, MediaType: "image/png", Payload: []byte("unsupported")},
+			{LogId: logMetadata[0].LogID, Payload: []byte("second")},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResults(), 5)
+
+	expected := []codes.Code{codes.OK, codes.PermissionDenied, codes.NotFound, codes.InvalidArgument, codes.OK}
+	for i, result := range resp.GetResults() {
+		assert.Equal(t, int32(expected[i]), result.GetErrorCode(), "result %d", i)
+		assert.Equal(t, expected[i] == codes.OK, result.GetLogMessageId() != "", "result %d", i)
+	}
+	assert.Equal(t, int64(1), resp.GetResults()[0].GetSequence())
+	assert.Equal(t, int64(2), resp.GetResults()[4].GetSequence())
+
+	stream := &mockListService_ListMessageServer{Ctx: ctx}
+	require.NoError(t, s.MessageList(&plspb.LogMessageListRequest{LogId: logMetadata[0].LogID}, stream))
+	require.Len(t, stream.Messages, 2)
+	assert.Equal(t, resp.GetResults()[0].GetLogMessageId(), stream.Messages[0].GetLogMessageId())
+	assert.Equal(t, []byte("first"), stream.Messages[0].GetPayload())
+	assert.Equal(t, resp.GetResults()[4].GetLogMessageId(), stream.Messages[1].GetLogMessageId())
+	assert.Equal(t, []byte("second"), stream.Messages[1].GetPayload())
+
+	readOnlyCtx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:     uuid.New().String(),
+		Scopes: []string{model.ScopeLogRead},
+	})
+
+	_, err = s.MessageAppendBatch(readOnlyCtx, &plspb.LogMessageAppendBatchRequest{})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+}
+
+func TestInMemoryServerMessageAppendStream(t *testing.T) {
+	s, ctx, logMetadata := newBatchTestServer(t)
+
+	appendStream := &mockAppendService_MessageAppendStreamServer{Ctx: ctx}
+	for i := 0; i < 1000; i++ {
+		appendStream.Requests = append(appendStream.Requests, &plspb.LogMessageAppendRequest{
+			LogId:   logMetadata[0].LogID,
+			Payload: []byte("message"),
+		})
+	}
+	appendStream.Requests = append(appendStream.Requests, &plspb.LogMessageAppendRequest{
+		LogId:   logMetadata[1].LogID,
+		Payload: []byte("not granted"),
+	})
+
+	require.NoError(t, s.MessageAppendStream(appendStream))
+	require.NotNil(t, appendStream.Response)
+	require.Len(t, appendStream.Response.GetResults(), 1001)
+
+	appended := 0
+	for _, result := range appendStream.Response.GetResults()[:1000] {
+		if result.GetErrorCode() == int32(codes.OK) {
+			appended++
+		} else {
+			assert.Equal(t, int32(codes.ResourceExhausted), result.GetErrorCode())
+		}
+	}
+
+	// The default rate limit of the log allows a burst of 100 messages, plus
+	// whatever is refilled while the test runs.
+	assert.GreaterOrEqual(t, appended, int(opt.DefaultRateLimitLogMessages))
+	assert.Less(t, appended, 1000)
+	assert.Equal(t, int32(codes.PermissionDenied), appendStream.Response.GetResults()[1000].GetErrorCode())
+
+	stream := &mockListService_ListMessageServer{Ctx: ctx}
+	require.NoError(t, s.MessageList(&plspb.LogMessageListRequest{LogId: logMetadata[0].LogID}, stream))
+	assert.Len(t, stream.Messages, appended)
+}
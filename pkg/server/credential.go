@@ -0,0 +1,289 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var CredentialServerSet = wire.NewSet(
+	NewCredentialServer,
+)
+
+type CredentialServer struct {
+	plspb.UnimplementedCredentialServer
+	credentialManager model.CredentialManager
+	ttl               time.Duration
+	maxTTL            time.Duration
+}
+
+func (s *CredentialServer) Describe(ctx context.Context, in *plspb.CredentialDescribeRequest) (*plspb.CredentialDescribeResponse, error) {
+	target, err := s.target(ctx, in.GetCredentialId())
+	if err != nil {
+		return nil, err
+	}
+
+	// The authenticated credential may be out of date (or carried by a signed
+	// token), so the stored credential is always described.
+	credential, err := s.credentialManager.Get(ctx, target.ID)
+	if err == manager.ErrCredentialNotFound {
+		return nil, auth.ErrPermissionDenied
+	} else if err != nil {
+		return nil, err
+	}
+
+	children, err := s.credentialManager.Children(ctx, credential.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	var live int32
+	for _, id := range children {
+		child, err := s.credentialManager.Get(ctx, id)
+		if err == manager.ErrCredentialNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if child.ExpiresAt.IsZero() || now.Before(child.ExpiresAt) {
+			live++
+		}
+	}
+
+	r := &plspb.CredentialDescribeResponse{
+		CredentialId: credential.ID,
+		ParentId:     credential.ParentID,
+		Contexts:     credential.Contexts,
+		Scopes:       credential.Scopes,
+		Children:     live,
+	}
+
+	if !credential.ExpiresAt.IsZero() {
+		r.ExpiresAt = timestamppb.New(credential.ExpiresAt)
+	}
+
+	return r, nil
+}
+
+func (s *CredentialServer) Issue(ctx context.Context, in *plspb.CredentialIssueRequest) (*plspb.CredentialIssueResponse, error) {
+	caller, ok := auth.CredentialFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	// The constraints are checked against the stored parent, which may have
+	// been refreshed since this request was authenticated.
+	parent, err := s.credentialManager.Get(ctx, caller.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !auth.HasScope(parent, model.ScopeCredentialIssue) {
+		return nil, auth.ErrPermissionDenied
+	}
+
+	contexts, err := normalizeStrings("contexts", in.GetContexts())
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, err := normalizeStrings("scopes", in.GetScopes())
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := s.expiresAt(in.GetExpiresAt())
+	if err != nil {
+		return nil, err
+	}
+
+	child, err := auth.ConstrainChild(parent, &model.Credential{
+		ParentID:  parent.ID,
+		Contexts:  contexts,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cm, err := s.credentialManager.Create(ctx, child)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plspb.CredentialIssueResponse{
+		CredentialId: cm.Credential.ID,
+		Contexts:     cm.Credential.Contexts,
+		ExpiresAt:    timestamppb.New(cm.Credential.ExpiresAt),
+		Token:        cm.Token,
+		Scopes:       cm.Credential.Scopes,
+	}, nil
+}
+
+func (s *CredentialServer) Refresh(ctx context.Context, in *plspb.CredentialRefreshRequest) (*plspb.CredentialRefreshResponse, error) {
+	credential, err := s.target(ctx, in.GetCredentialId())
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := s.expiresAt(in.GetExpiresAt())
+	if err != nil {
+		return nil, err
+	}
+
+	if credential.ParentID != "" {
+		parent, err := s.credentialManager.Get(ctx, credential.ParentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !parent.ExpiresAt.IsZero() && expiresAt.After(parent.ExpiresAt) {
+			expiresAt = parent.ExpiresAt
+		}
+	}
+
+	cm, err := s.credentialManager.Refresh(ctx, credential.ID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plspb.CredentialRefreshResponse{
+		CredentialId: cm.Credential.ID,
+		ExpiresAt:    timestamppb.New(cm.Credential.ExpiresAt),
+		Token:        cm.Token,
+	}, nil
+}
+
+func (s *CredentialServer) Revoke(ctx context.Context, in *plspb.CredentialRevokeRequest) (*plspb.CredentialRevokeResponse, error) {
+	credential, err := s.target(ctx, in.GetCredentialId())
+	if err != nil {
+		return nil, err
+	}
+
+	// Every other credential is issued from the root credential, so it is
+	// rotated rather than revoked.
+	if credential.ID == manager.RootCredentialID {
+		return nil, auth.ErrPermissionDenied
+	}
+
+	if err := s.credentialManager.Revoke(ctx, credential.ID); err != nil {
+		return nil, err
+	}
+
+	return &plspb.CredentialRevokeResponse{
+		CredentialId: credential.ID,
+	}, nil
+}
+
+// target resolves the credential a request operates on. If no identifier is
+// given, the authenticated credential is used. Otherwise the identifier must
+// refer to the authenticated credential or one of its descendants.
+func (s *CredentialServer) target(ctx context.Context, id string) (*model.Credential, error) {
+	caller, ok := auth.CredentialFromContext(ctx)
+	if !ok {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	if id == "" || id == caller.ID {
+		return caller, nil
+	}
+
+	credential, err := s.credentialManager.Get(ctx, id)
+	if err == manager.ErrCredentialNotFound {
+		return nil, auth.ErrPermissionDenied
+	} else if err != nil {
+		return nil, err
+	}
+
+	for parentID := credential.ParentID; parentID != ""; {
+		if parentID == caller.ID {
+			return credential, nil
+		}
+
+		parent, err := s.credentialManager.Get(ctx, parentID)
+		if err == manager.ErrCredentialNotFound {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		parentID = parent.ParentID
+	}
+
+	return nil, auth.ErrPermissionDenied
+}
+
+// expiresAt determines the actual expiry for a credential given the requested
+// one. The result is never later than the configured maximum lifetime, so it
+// is always on or before the requested expiration.
+func (s *CredentialServer) expiresAt(requested *timestamppb.Timestamp) (time.Time, error) {
+	now := time.Now().UTC()
+	max := now.Add(s.maxTTL)
+
+	if requested == nil {
+		expiresAt := now.Add(s.ttl)
+		if expiresAt.After(max) {
+			expiresAt = max
+		}
+
+		return expiresAt, nil
+	}
+
+	if err := requested.CheckValid(); err != nil {
+		return time.Time{}, NewFieldError("expires_at", err.Error())
+	}
+
+	expiresAt := requested.AsTime()
+	if !expiresAt.After(now) {
+		return time.Time{}, NewFieldError("expires_at", "must be in the future")
+	}
+
+	if expiresAt.After(max) {
+		expiresAt = max
+	}
+
+	return expiresAt, nil
+}
+
+// normalizeStrings removes duplicates from a list of contexts or scopes,
+// preserving order.
+func normalizeStrings(field string, values []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(values))
+
+	r := make([]string, 0, len(values))
+	for _, c := range values {
+		if c == "" {
+			return nil, NewFieldError(field, "must not contain empty values")
+		}
+
+		if _, found := seen[c]; found {
+			continue
+		}
+		seen[c] = struct{}{}
+
+		r = append(r, c)
+	}
+
+	return r, nil
+}
+
+func NewCredentialServer(cfg *opt.Config, credentialManager model.CredentialManager) plspb.CredentialServer {
+	s := &CredentialServer{
+		credentialManager: credentialManager,
+		ttl:               cfg.CredentialTTL,
+		maxTTL:            cfg.CredentialMaxTTL,
+	}
+
+	return s
+}
@@ -0,0 +1,523 @@
+package server_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/puppetlabs/relay-pls/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCredentialServer(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &opt.Config{
+		CredentialTTL:    time.Hour,
+		CredentialMaxTTL: 2 * time.Hour,
+	}
+
+	cm := manager.NewInMemoryCredentialManager()
+	s := server.NewCredentialServer(cfg, cm)
+
+	root, err := cm.Create(ctx, &model.Credential{
+		Contexts:  []string{"a", "b", "c"},
+		ExpiresAt: time.Now().Add(90 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	rootCtx := auth.WithCredential(ctx, root.Credential)
+
+	_, err = s.Issue(ctx, &plspb.CredentialIssueRequest{Contexts: []string{"a"}})
+	assert.Equal(t, auth.ErrUnauthenticated, err)
+
+	issueResponse, err := s.Issue(rootCtx, &plspb.CredentialIssueRequest{
+		Contexts:  []string{"a", "b", "a"},
+		ExpiresAt: timestamppb.New(time.Now().Add(24 * time.Hour)),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, issueResponse.GetCredentialId())
+	assert.Equal(t, []string{"a", "b"}, issueResponse.GetContexts())
+	assert.True(t, root.Credential.ExpiresAt.Equal(issueResponse.GetExpiresAt().AsTime()))
+
+	credential, err := cm.Authenticate(ctx, issueResponse.GetToken())
+	require.NoError(t, err)
+	assert.Equal(t, issueResponse.GetCredentialId(), credential.ID)
+	assert.Equal(t, root.Credential.ID, credential.ParentID)
+
+	_, err = s.Issue(rootCtx, &plspb.CredentialIssueRequest{Contexts: []string{"d"}})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	childCtx := auth.WithCredential(ctx, credential)
+
+	_, err = s.Refresh(childCtx, &plspb.CredentialRefreshRequest{CredentialId: root.Credential.ID})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	refreshResponse, err := s.Refresh(childCtx, &plspb.CredentialRefreshRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, issueResponse.GetCredentialId(), refreshResponse.GetCredentialId())
+	assert.NotEqual(t, issueResponse.GetToken(), refreshResponse.GetToken())
+
+	_, err = cm.Authenticate(ctx, issueResponse.GetToken())
+	assert.Equal(t, manager.ErrInvalidToken, err)
+
+	_, err = cm.Authenticate(ctx, refreshResponse.GetToken())
+	assert.NoError(t, err)
+
+	_, err = s.Revoke(childCtx, &plspb.CredentialRevokeRequest{})
+	require.NoError(t, err)
+
+	_, err = cm.Authenticate(ctx, refreshResponse.GetToken())
+	assert.Equal(t, manager.ErrInvalidToken, err)
+
+	_, err = s.Issue(rootCtx, &plspb.CredentialIssueRequest{
+		ExpiresAt: timestamppb.New(time.Now().Add(-time.Minute)),
+	})
+	assert.ErrorIs(t, err, server.ErrInvalid)
+}
+
+func TestCredentialServerHierarchy(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &opt.Config{
+		CredentialTTL:    time.Hour,
+		CredentialMaxTTL: 2 * time.Hour,
+	}
+
+	cm := manager.NewInMemoryCredentialManager()
+	s := server.NewCredentialServer(cfg, cm)
+
+	root, err := cm.Create(ctx, &model.Credential{
+		Contexts: []string{"a", "b"},
+	})
+	require.NoError(t, err)
+
+	issue := func(parent *model.Credential, contexts ...string) (*model.Credential, string) {
+		r, err := s.Issue(auth.WithCredential(ctx, parent), &plspb.CredentialIssueRequest{Contexts: contexts})
+		require.NoError(t, err)
+
+		credential, err := cm.Authenticate(ctx, r.GetToken())
+		require.NoError(t, err)
+
+		return credential, r.GetToken()
+	}
+
+	child, childToken := issue(root.Credential, "a", "b")
+	grandchild, grandchildToken := issue(child, "a")
+	sibling, siblingToken := issue(root.Credential, "b")
+
+	assert.Equal(t, child.ID, grandchild.ParentID)
+
+	// Contexts default to those of the parent.
+	inherited, err := s.Issue(auth.WithCredential(ctx, child), &plspb.CredentialIssueRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, inherited.GetContexts())
+
+	_, err = s.Issue(auth.WithCredential(ctx, grandchild), &plspb.CredentialIssueRequest{Contexts: []string{"b"}})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	// Siblings and ancestors are not reachable from a credential.
+	_, err = s.Revoke(auth.WithCredential(ctx, child), &plspb.CredentialRevokeRequest{CredentialId: sibling.ID})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	_, err = s.Revoke(auth.WithCredential(ctx, grandchild), &plspb.CredentialRevokeRequest{CredentialId: child.ID})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	// Credentials describe themselves and their descendants only.
+	describeResponse, err := s.Describe(auth.WithCredential(ctx, child), &plspb.CredentialDescribeRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, child.ID, describeResponse.GetCredentialId())
+	assert.Equal(t, root.Credential.ID, describeResponse.GetParentId())
+	assert.Equal(t, []string{"a", "b"}, describeResponse.GetContexts())
+	assert.True(t, child.ExpiresAt.Equal(describeResponse.GetExpiresAt().AsTime()))
+	assert.Equal(t, int32(2), describeResponse.GetChildren())
+
+	describeResponse, err = s.Describe(auth.WithCredential(ctx, root.Credential), &plspb.CredentialDescribeRequest{CredentialId: grandchild.ID})
+	require.NoError(t, err)
+	assert.Equal(t, child.ID, describeResponse.GetParentId())
+	assert.Equal(t, []string{"a"}, describeResponse.GetContexts())
+	assert.Equal(t, int32(0), describeResponse.GetChildren())
+
+	describeResponse, err = s.Describe(auth.WithCredential(ctx, root.Credential), &plspb.CredentialDescribeRequest{})
+	require.NoError(t, err)
+	assert.Nil(t, describeResponse.GetExpiresAt())
+
+	_, err = s.Describe(auth.WithCredential(ctx, grandchild), &plspb.CredentialDescribeRequest{CredentialId: sibling.ID})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	// A grandchild can be refreshed by its grandparent.
+	refreshResponse, err := s.Refresh(auth.WithCredential(ctx, root.Credential), &plspb.CredentialRefreshRequest{CredentialId: grandchild.ID})
+	require.NoError(t, err)
+	grandchildToken = refreshResponse.GetToken()
+
+	// Revoking the child revokes the whole subtree but leaves the sibling.
+	_, err = s.Revoke(auth.WithCredential(ctx, root.Credential), &plspb.CredentialRevokeRequest{CredentialId: child.ID})
+	require.NoError(t, err)
+
+	for _, token := range []string{childToken, grandchildToken} {
+		_, err = cm.Authenticate(ctx, token)
+		assert.Equal(t, manager.ErrInvalidToken, err)
+	}
+
+	_, err = cm.Authenticate(ctx, siblingToken)
+	assert.NoError(t, err)
+
+	_, err = cm.Get(ctx, grandchild.ID)
+	assert.Equal(t, manager.ErrCredentialNotFound, err)
+}
+
+func TestCredentialServerSignedTokens(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &opt.Config{
+		CredentialTTL:    time.Hour,
+		CredentialMaxTTL: 2 * time.Hour,
+	}
+
+	revocationManager := manager.NewInMemoryRevocationManager()
+
+	revocationList, cleanup, err := manager.NewRevocationList(ctx, cfg, revocationManager)
+	require.NoError(t, err)
+	defer cleanup()
+
+	kh, err := manager.NewInMemorySigningKeyset()
+	require.NoError(t, err)
+
+	cm, err := manager.NewSignedCredentialManager(cfg, manager.NewInMemoryCredentialManager(), revocationList, kh)
+	require.NoError(t, err)
+
+	s := server.NewCredentialServer(cfg, cm)
+
+	root, err := cm.Create(ctx, &model.Credential{
+		Contexts: []string{"a", "b"},
+	})
+	require.NoError(t, err)
+
+	rootCredential, err := cm.Authenticate(ctx, root.Token)
+	require.NoError(t, err)
+	assert.Equal(t, root.Credential.ID, rootCredential.ID)
+
+	issueResponse, err := s.Issue(auth.WithCredential(ctx, rootCredential), &plspb.CredentialIssueRequest{Contexts: []string{"a"}})
+	require.NoError(t, err)
+
+	child, err := cm.Authenticate(ctx, issueResponse.GetToken())
+	require.NoError(t, err)
+	assert.Equal(t, root.Credential.ID, child.ParentID)
+	assert.Equal(t, []string{"a"}, child.Contexts)
+	assert.True(t, issueResponse.GetExpiresAt().AsTime().Equal(child.ExpiresAt))
+
+	grandchildResponse, err := s.Issue(auth.WithCredential(ctx, child), &plspb.CredentialIssueRequest{})
+	require.NoError(t, err)
+
+	_, err = cm.Authenticate(ctx, issueResponse.GetToken()+"x")
+	assert.Equal(t, manager.ErrInvalidToken, err)
+
+	// Refreshing invalidates tokens issued before the refresh.
+	refreshResponse, err := s.Refresh(auth.WithCredential(ctx, child), &plspb.CredentialRefreshRequest{})
+	require.NoError(t, err)
+
+	_, err = cm.Authenticate(ctx, issueResponse.GetToken())
+	assert.Equal(t, manager.ErrInvalidToken, err)
+
+	_, err = cm.Authenticate(ctx, refreshResponse.GetToken())
+	require.NoError(t, err)
+
+	_, err = cm.Authenticate(ctx, grandchildResponse.GetToken())
+	require.NoError(t, err)
+
+	// Revocation applies to the whole subtree.
+	_, err = s.Revoke(auth.WithCredential(ctx, rootCredential), &plspb.CredentialRevokeRequest{CredentialId: child.ID})
+	require.NoError(t, err)
+
+	for _, token := range []string{refreshResponse.GetToken(), grandchildResponse.GetToken()} {
+		_, err = cm.Authenticate(ctx, token)
+		assert.Equal(t, manager.ErrInvalidToken, err)
+	}
+
+	// Revocations survive synchronization with the store.
+	require.NoError(t, revocationList.Sync(ctx))
+
+	_, err = cm.Authenticate(ctx, grandchildResponse.GetToken())
+	assert.Equal(t, manager.ErrInvalidToken, err)
+
+	_, err = cm.Authenticate(ctx, root.Token)
+	assert.NoError(t, err)
+
+	// Revoking expired credentials, as the sweeper does, adds no revocation.
+	revocations, err := revocationManager.List(ctx)
+	require.NoError(t, err)
+
+	expired, err := cm.Create(ctx, &model.Credential{
+		ParentID:  root.Credential.ID,
+		Contexts:  []string{"a"},
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.NoError(t, cm.Revoke(ctx, expired.Credential.ID))
+
+	after, err := revocationManager.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, after, len(revocations))
+}
+
+func TestCredentialServerRootCredential(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &opt.Config{
+		CredentialTTL:    time.Hour,
+		CredentialMaxTTL: 2 * time.Hour,
+		RootTokenFile:    filepath.Join(t.TempDir(), "root-token"),
+	}
+
+	cm := manager.NewInMemoryCredentialManager()
+	s := server.NewCredentialServer(cfg, cm)
+
+	rcm := manager.NewInMemoryRootCredentialManager(cfg, cm)
+
+	created, err := rcm.Bootstrap(ctx)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	token, err := os.ReadFile(cfg.RootTokenFile)
+	require.NoError(t, err)
+
+	root, err := cm.Authenticate(ctx, strings.TrimSpace(string(token)))
+	require.NoError(t, err)
+	assert.Equal(t, manager.RootCredentialID, root.ID)
+	assert.Equal(t, []string{model.WildcardContext}, root.Contexts)
+
+	// The root credential is only created once.
+	created, err = rcm.Bootstrap(ctx)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	// Any context may be granted from the wildcard.
+	issueResponse, err := s.Issue(auth.WithCredential(ctx, root), &plspb.CredentialIssueRequest{Contexts: []string{"tenant-a"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tenant-a"}, issueResponse.GetContexts())
+	assert.Equal(t, model.Scopes, issueResponse.GetScopes())
+
+	// Scopes can only be narrowed, and issuing requires its own scope.
+	readOnlyResponse, err := s.Issue(auth.WithCredential(ctx, root), &plspb.CredentialIssueRequest{Scopes: []string{model.ScopeLogRead}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{model.ScopeLogRead}, readOnlyResponse.GetScopes())
+
+	readOnly, err := cm.Authenticate(ctx, readOnlyResponse.GetToken())
+	require.NoError(t, err)
+
+	_, err = s.Issue(auth.WithCredential(ctx, readOnly), &plspb.CredentialIssueRequest{})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	_, err = auth.ResolveContext(auth.WithCredential(ctx, root), model.ScopeLogCreate, "")
+	assert.Equal(t, auth.ErrContextRequired, err)
+
+	_, err = auth.ResolveContext(auth.WithCredential(ctx, root), model.ScopeLogCreate, model.WildcardContext)
+	assert.Equal(t, auth.ErrInvalidContext, err)
+
+	require.NoError(t, rcm.Rotate(ctx))
+
+	rotated, err := os.ReadFile(cfg.RootTokenFile)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, rotated)
+
+	_, err = cm.Authenticate(ctx, strings.TrimSpace(string(token)))
+	assert.Equal(t, manager.ErrInvalidToken, err)
+
+	_, err = cm.Authenticate(ctx, strings.TrimSpace(string(rotated)))
+	assert.NoError(t, err)
+
+	// The root credential cannot be revoked, even by itself.
+	for _, id := range []string{"", manager.RootCredentialID} {
+		_, err = s.Revoke(auth.WithCredential(ctx, root), &plspb.CredentialRevokeRequest{CredentialId: id})
+		assert.Equal(t, auth.ErrPermissionDenied, err)
+	}
+
+	// Without a destination for the token, an existing root credential is
+	// kept, but a new one cannot be created.
+	created, err = manager.NewInMemoryRootCredentialManager(&opt.Config{}, cm).Bootstrap(ctx)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	_, err = manager.NewInMemoryRootCredentialManager(&opt.Config{}, manager.NewInMemoryCredentialManager()).Bootstrap(ctx)
+	assert.Equal(t, manager.ErrRootTokenDestinationRequired, err)
+}
+
+func TestCredentialServerSignedRootCredentialRollback(t *testing.T) {
+	ctx := context.Background()
+
+	dir := filepath.Join(t.TempDir(), "tokens")
+
+	cfg := &opt.Config{
+		CredentialTTL:    time.Hour,
+		CredentialMaxTTL: 2 * time.Hour,
+		RootTokenFile:    filepath.Join(dir, "root-token"),
+	}
+
+	revocationList, cleanup, err := manager.NewRevocationList(ctx, cfg, manager.NewInMemoryRevocationManager())
+	require.NoError(t, err)
+	defer cleanup()
+
+	kh, err := manager.NewInMemorySigningKeyset()
+	require.NoError(t, err)
+
+	cm, err := manager.NewSignedCredentialManager(cfg, manager.NewInMemoryCredentialManager(), revocationList, kh)
+	require.NoError(t, err)
+
+	s := server.NewCredentialServer(cfg, cm)
+	rcm := manager.NewInMemoryRootCredentialManager(cfg, cm)
+
+	// The token cannot be written, so the new root credential is revoked.
+	_, err = rcm.Bootstrap(ctx)
+	require.Error(t, err)
+
+	_, err = cm.Get(ctx, manager.RootCredentialID)
+	assert.Equal(t, manager.ErrCredentialNotFound, err)
+
+	// Once the token can be written, the root credential is created again
+	// and its tokens, and those it issues, are valid.
+	require.NoError(t, os.Mkdir(dir, 0700))
+
+	created, err := rcm.Bootstrap(ctx)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	token, err := os.ReadFile(cfg.RootTokenFile)
+	require.NoError(t, err)
+
+	root, err := cm.Authenticate(ctx, strings.TrimSpace(string(token)))
+	require.NoError(t, err)
+
+	issueResponse, err := s.Issue(auth.WithCredential(ctx, root), &plspb.CredentialIssueRequest{Contexts: []string{"tenant-a"}})
+	require.NoError(t, err)
+
+	_, err = cm.Authenticate(ctx, issueResponse.GetToken())
+	assert.NoError(t, err)
+
+	require.NoError(t, revocationList.Sync(ctx))
+	require.NoError(t, rcm.Rotate(ctx))
+
+	rotated, err := os.ReadFile(cfg.RootTokenFile)
+	require.NoError(t, err)
+
+	_, err = cm.Authenticate(ctx, strings.TrimSpace(string(rotated)))
+	assert.NoError(t, err)
+
+	_, err = cm.Authenticate(ctx, issueResponse.GetToken())
+	assert.NoError(t, err)
+}
+
+func TestCredentialSweeper(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &opt.Config{
+		CredentialSweepInterval:  time.Hour,
+		CredentialSweepBatchSize: 1,
+	}
+
+	cm := manager.NewInMemoryCredentialManager()
+
+	cs, cleanup, err := manager.NewCredentialSweeper(ctx, cfg, cm, manager.NewInMemoryLeaseManager(), nil)
+	require.NoError(t, err)
+	defer cleanup()
+
+	root, err := cm.Create(ctx, &model.Credential{Contexts: []string{"a"}})
+	require.NoError(t, err)
+
+	live, err := cm.Create(ctx, &model.Credential{
+		ParentID:  root.Credential.ID,
+		Contexts:  []string{"a"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	var expired []string
+	for i := 0; i < 2; i++ {
+		c, err := cm.Create(ctx, &model.Credential{
+			ParentID:  root.Credential.ID,
+			Contexts:  []string{"a"},
+			ExpiresAt: time.Now().Add(50 * time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		expired = append(expired, c.Credential.ID)
+	}
+
+	// Children are swept with their expired parent.
+	grandchild, err := cm.Create(ctx, &model.Credential{
+		ParentID:  expired[0],
+		Contexts:  []string{"a"},
+		ExpiresAt: time.Now().Add(50 * time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	total := 0
+	for i := 0; i < 3; i++ {
+		swept, err := cs.Sweep(ctx)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, swept, cfg.CredentialSweepBatchSize)
+
+		total += swept
+	}
+	assert.GreaterOrEqual(t, total, 2)
+
+	for _, id := range append(expired, grandchild.Credential.ID) {
+		_, err := cm.Get(ctx, id)
+		assert.Equal(t, manager.ErrCredentialNotFound, err)
+	}
+
+	for _, id := range []string{root.Credential.ID, live.Credential.ID} {
+		_, err := cm.Get(ctx, id)
+		assert.NoError(t, err)
+	}
+}
+
+func TestCredentialSweeperMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := &opt.Config{
+		CredentialSweepInterval: 10 * time.Millisecond,
+	}
+
+	exporter, err := telemetry.ProvidePrometheusExporter(telemetry.ProvidePrometheusConfig())
+	require.NoError(t, err)
+
+	cm := manager.NewInMemoryCredentialManager()
+
+	_, err = cm.Create(ctx, &model.Credential{
+		Contexts:  []string{"a"},
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, cleanup, err := manager.NewCredentialSweeper(ctx, cfg, cm, manager.NewInMemoryLeaseManager(), telemetry.ProvideMeter(exporter))
+	require.NoError(t, err)
+	defer cleanup()
+
+	// The sweeper's counters are served by the telemetry server of the
+	// exporter whose meter it was given.
+	ts := telemetry.NewTelemetryServer(exporter, cfg)
+	swept := regexp.MustCompile(`(?m)^` + model.MetricCredentialSweepExpired + `\{[^}]*module="credential-sweeper"[^}]*\} 1$`)
+
+	assert.Eventually(t, func() bool {
+		w := httptest.NewRecorder()
+		ts.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		return swept.MatchString(w.Body.String())
+	}, 5*time.Second, 10*time.Millisecond)
+}
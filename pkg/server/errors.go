@@ -2,9 +2,78 @@ package server
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 )
 
 var (
-	ErrNotFound = errors.New("error: not found")
-	ErrInvalid  = errors.New("error: invalid")
+	ErrInvalid          = errors.New("server: invalid request")
+	ErrAppendInProgress = errors.New("server: an append with this idempotency key is in progress")
+	ErrFollowBehind     = errors.New("server: follower fell too far behind; resume with after_sequence")
 )
+
+// FieldError reports a request field that is not valid. It is returned to
+// clients as INVALID_ARGUMENT with a BadRequest field violation.
+type FieldError struct {
+	Field       string
+	Description string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("server: invalid %s: %s", e.Field, e.Description)
+}
+
+// Is allows callers to check for any invalid request using ErrInvalid.
+func (e *FieldError) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+func NewFieldError(field, description string) *FieldError {
+	return &FieldError{
+		Field:       field,
+		Description: description,
+	}
+}
+
+// QuotaViolation describes a single rate limit that a request exceeded.
+type QuotaViolation struct {
+	Subject     string
+	Description string
+}
+
+// RateLimitError reports that a request exceeded one or more rate limits. It
+// is returned to clients as RESOURCE_EXHAUSTED with QuotaFailure and
+// RetryInfo details.
+type RateLimitError struct {
+	Violations []QuotaViolation
+	RetryDelay time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	subjects := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		subjects[i] = v.Subject
+	}
+
+	return fmt.Sprintf("server: rate limit exceeded for %s; retry in %s", strings.Join(subjects, ", "), e.RetryDelay)
+}
+
+// add records a violation, creating the error if necessary. The retry delay
+// is the longest required by any violation.
+func (e *RateLimitError) add(subject, description string, delay time.Duration) *RateLimitError {
+	if e == nil {
+		e = &RateLimitError{}
+	}
+
+	e.Violations = append(e.Violations, QuotaViolation{
+		Subject:     subject,
+		Description: description,
+	})
+
+	if delay > e.RetryDelay {
+		e.RetryDelay = delay
+	}
+
+	return e
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+)
+
+// filterMaxContextLines is the most messages a filter may return around each
+// match.
+const filterMaxContextLines = 100
+
+// messageFilter selects the messages whose payload matches a pattern. Every
+// mode is compiled to an RE2 expression, so that BigQuery, which also uses
+// RE2, evaluates it the same way.
+type messageFilter struct {
+	expr         string
+	re           *regexp.Regexp
+	contextLines int
+}
+
+// matches returns whether a message matches the filter. A nil filter matches
+// every message.
+func (f *messageFilter) matches(message *plspb.LogMessageListResponse) bool {
+	if f == nil {
+		return true
+	}
+
+	return utf8.Valid(message.GetPayload()) && f.re.Match(message.GetPayload())
+}
+
+// apply returns the messages that match the filter and those within the
+// context lines of a match in the same log. The messages of each log must be
+// in ascending order.
+func (f *messageFilter) apply(messages []*plspb.LogMessageListResponse) []*plspb.LogMessageListResponse {
+	if f == nil {
+		return messages
+	}
+
+	byLog := make(map[string][]int)
+	for i, message := range messages {
+		byLog[message.GetLogId()] = append(byLog[message.GetLogId()], i)
+	}
+
+	keep := make([]bool, len(messages))
+	for _, indexes := range byLog {
+		// Context is taken in sequence order, as in BigQuery, even when
+		// messages of several logs are merged by timestamp.
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return messages[indexes[i]].GetSequence() < messages[indexes[j]].GetSequence()
+		})
+
+		for j, i := range indexes {
+			if !f.matches(messages[i]) {
+				continue
+			}
+
+			for k := j - f.contextLines; k <= j+f.contextLines; k++ {
+				if k >= 0 && k < len(indexes) {
+					keep[indexes[k]] = true
+				}
+			}
+		}
+	}
+
+	var r []*plspb.LogMessageListResponse
+	for i, message := range messages {
+		if keep[i] {
+			r = append(r, message)
+		}
+	}
+
+	return r
+}
+
+// newMessageFilter validates the filter of a list request, returning nil if
+// it has none.
+func newMessageFilter(in *plspb.LogMessageListRequest) (*messageFilter, error) {
+	filter := in.GetFilter()
+	if filter == nil {
+		return nil, nil
+	}
+
+	if filter.GetPattern() == "" {
+		return nil, NewFieldError("filter.pattern", "must not be empty")
+	}
+
+	var expr string
+	switch filter.GetMode() {
+	case plspb.LogMessageFilter_MODE_SUBSTRING:
+		expr = regexp.QuoteMeta(filter.GetPattern())
+	case plspb.LogMessageFilter_MODE_CASE_INSENSITIVE:
+		expr = "(?i)" + regexp.QuoteMeta(filter.GetPattern())
+	case plspb.LogMessageFilter_MODE_REGEX:
+		expr = filter.GetPattern()
+	default:
+		return nil, NewFieldError("filter.mode", "is not a known mode")
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, NewFieldError("filter.pattern", "is not a valid regular expression")
+	}
+
+	switch {
+	case filter.GetContextLines() < 0:
+		return nil, NewFieldError("filter.context_lines", "must not be negative")
+	case filter.GetContextLines() > filterMaxContextLines:
+		return nil, NewFieldError("filter.context_lines", fmt.Sprintf("must be at most %d", filterMaxContextLines))
+	case filter.GetContextLines() > 0 && in.GetFollow():
+		return nil, NewFieldError("filter.context_lines", "cannot be combined with follow")
+	}
+
+	return &messageFilter{
+		expr:         expr,
+		re:           re,
+		contextLines: int(filter.GetContextLines()),
+	}, nil
+}
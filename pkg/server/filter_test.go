@@ -0,0 +1,172 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryServerMessageListFilter(t *testing.T) {
+	s, ctx, logMetadata := newBatchTestServer(t)
+
+	logID := logMetadata[0].LogID
+
+	for _, payload := range []string{
+		"starting build",
+		"compiling",
+		"ERROR: disk full",
+		"retrying",
+		"cleaning up",
+		"error: timeout",
+		"done",
+	} {
+		_, err := s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: logID, Payload: []byte(payload)})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		Name     string
+		Request  *plspb.LogMessageListRequest
+		Expected []string
+	}{
+		{
+			Name: "substring",
+			Request: &plspb.LogMessageListRequest{
+				LogId:  logID,
+				Filter: &plspb.LogMessageFilter{Pattern: "error"},
+			},
+			Expected: []string{"error: timeout"},
+		},
+		{
+			Name: "case-insensitive substring",
+			Request: &plspb.LogMessageListRequest{
+				LogId:  logID,
+				Filter: &plspb.LogMessageFilter{Pattern: "error", Mode: plspb.LogMessageFilter_MODE_CASE_INSENSITIVE},
+			},
+			Expected: []string{"ERROR: disk full", "error: timeout"},
+		},
+		{
+			Name: "regex",
+			Request: &plspb.LogMessageListRequest{
+				LogId:  logID,
+				Filter: &plspb.LogMessageFilter{Pattern: "^(ERROR|done)", Mode: plspb.LogMessageFilter_MODE_REGEX},
+			},
+			Expected: []string{"ERROR: disk full", "done"},
+		},
+		{
+			Name: "substring is not a regex",
+			Request: &plspb.LogMessageListRequest{
+				LogId:  logID,
+				Filter: &plspb.LogMessageFilter{Pattern: "^done"},
+			},
+		},
+		{
+			Name: "context lines",
+			Request: &plspb.LogMessageListRequest{
+				LogId:  logID,
+				Filter: &plspb.LogMessageFilter{Pattern: "error", Mode: plspb.LogMessageFilter_MODE_CASE_INSENSITIVE, ContextLines: 1},
+			},
+			Expected: []string{"compiling", "ERROR: disk full", "retrying", "cleaning up", "error: timeout", "done"},
+		},
+		{
+			Name: "context lines with tail",
+			Request: &plspb.LogMessageListRequest{
+				LogId:  logID,
+				Filter: &plspb.LogMessageFilter{Pattern: "error", ContextLines: 1},
+				Tail:   2,
+			},
+			Expected: []string{"error: timeout", "done"},
+		},
+		{
+			Name: "limit",
+			Request: &plspb.LogMessageListRequest{
+				LogId:  logID,
+				Filter: &plspb.LogMessageFilter{Pattern: "error", Mode: plspb.LogMessageFilter_MODE_CASE_INSENSITIVE},
+				Limit:  1,
+			},
+			Expected: []string{"ERROR: disk full"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stream := &mockListService_ListMessageServer{Ctx: ctx}
+			require.NoError(t, s.MessageList(test.Request, stream))
+
+			var payloads []string
+			for _, message := range stream.Messages {
+				payloads = append(payloads, string(message.GetPayload()))
+			}
+			assert.Equal(t, test.Expected, payloads)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, filter := range []*plspb.LogMessageFilter{
+			{},
+			{Pattern: "(", Mode: plspb.LogMessageFilter_MODE_REGEX},
+			{Pattern: "error", Mode: plspb.LogMessageFilter_Mode(7)},
+			{Pattern: "error", ContextLines: -1},
+			{Pattern: "error", ContextLines: 101},
+		} {
+			err := s.MessageList(&plspb.LogMessageListRequest{LogId: logID, Filter: filter}, &mockListService_ListMessageServer{Ctx: ctx})
+			assert.ErrorIs(t, err, server.ErrInvalid, "%+v", filter)
+		}
+
+		err := s.MessageList(&plspb.LogMessageListRequest{
+			LogId:  logID,
+			Filter: &plspb.LogMessageFilter{Pattern: "error", ContextLines: 1},
+			Follow: true,
+		}, &mockListService_ListMessageServer{Ctx: ctx})
+		assert.ErrorIs(t, err, server.ErrInvalid)
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		followCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream := &mockFollowService_MessageListServer{
+			Ctx:      followCtx,
+			Messages: make(chan *plspb.LogMessageListResponse, 10),
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- s.MessageList(&plspb.LogMessageListRequest{
+				LogId:  logID,
+				Filter: &plspb.LogMessageFilter{Pattern: "error"},
+				Follow: true,
+			}, stream)
+		}()
+
+		receive := func() string {
+			select {
+			case m := <-stream.Messages:
+				return string(m.GetPayload())
+			case err := <-errCh:
+				require.FailNow(t, "follow ended early", "%+v", err)
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "timed out waiting for message")
+			}
+
+			return ""
+		}
+
+		assert.Equal(t, "error: timeout", receive())
+
+		for _, payload := range []string{"no match", "error: again"} {
+			_, err := s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: logID, Payload: []byte(payload)})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, "error: again", receive())
+
+		cancel()
+		<-errCh
+
+		assert.Empty(t, stream.Messages)
+	})
+}
@@ -0,0 +1,555 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// followPollMinInterval and followPollMaxInterval bound how often a log
+	// with followers is polled for messages appended on other instances. The
+	// interval doubles each time a poll finds nothing.
+	followPollMinInterval = time.Second
+	followPollMaxInterval = 30 * time.Second
+
+	// followMaxBacklog is the most messages queued for a follower that is not
+	// keeping up before it is disconnected.
+	followMaxBacklog = 10000
+
+	// followMaxGapAge is how long a missing sequence is waited for before it
+	// is assumed never to be stored, for example because its append failed
+	// or was a retry dropped as a duplicate.
+	followMaxGapAge = time.Minute
+)
+
+// followPoller reads the messages of a log stored after a sequence, in
+// sequence order, including those appended on other instances. It also
+// reports whether the log was sealed before the messages were read, in which
+// case no more messages will follow them.
+type followPoller func(ctx context.Context, logID, key string, afterSequence int64) ([]*plspb.LogMessageListResponse, bool, error)
+
+// errFollowSealed ends the followers of a log that has been sealed. It is
+// never returned to clients; followers instead end once they have sent the
+// remaining messages.
+var errFollowSealed = errors.New("server: log sealed")
+
+// followUpdate is a set of messages delivered to the followers of a log.
+type followUpdate struct {
+	messages []*plspb.LogMessageListResponse
+}
+
+// follower queues the updates for a single follow request. Updates are never
+// blocked on a slow follower; a follower that falls too far behind is ended
+// instead.
+type follower struct {
+	notify chan struct{}
+
+	mut     sync.Mutex
+	pending []followUpdate
+	backlog int
+	err     error
+}
+
+func (f *follower) push(u followUpdate) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if f.err != nil {
+		return
+	}
+
+	f.backlog += len(u.messages)
+	if f.backlog > followMaxBacklog {
+		f.pending = nil
+		f.err = ErrFollowBehind
+	} else {
+		f.pending = append(f.pending, u)
+	}
+
+	f.wake()
+}
+
+// end stops the follower once the updates already queued are delivered.
+func (f *follower) end(err error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if f.err == nil {
+		f.err = err
+	}
+
+	f.wake()
+}
+
+func (f *follower) wake() {
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next waits for queued updates, returning an error once the follower has
+// ended or the context is done.
+func (f *follower) next(ctx context.Context) ([]followUpdate, error) {
+	for {
+		f.mut.Lock()
+		pending, err := f.pending, f.err
+		f.pending = nil
+		f.backlog = 0
+		f.mut.Unlock()
+
+		if len(pending) > 0 {
+			return pending, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-f.notify:
+		}
+	}
+}
+
+type followedLog struct {
+	followers map[*follower]struct{}
+	cancel    context.CancelFunc
+
+	// seen holds the sequences published to the followers, whether appended
+	// on this instance or polled. The poller reads from its floor.
+	seen *sequenceSet
+}
+
+// followHub fans out messages to the followers of each log. Messages appended
+// on this instance are published as soon as they are stored, and a single
+// poller per log, shared by all of its followers, catches up on messages
+// appended elsewhere.
+//
+// The poller reads after the highest sequence up to which the hub has seen
+// every message, so that a message appended elsewhere and stored after a
+// later one is still found, unless it is missing for longer than
+// followMaxGapAge.
+type followHub struct {
+	poll followPoller
+
+	mut  sync.Mutex
+	logs map[string]*followedLog
+}
+
+// subscribe adds a follower to a log. If the log has no poller yet, one is
+// started after the given sequence using the given encryption key.
+func (h *followHub) subscribe(logID, key string, afterSequence int64) *follower {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	l, found := h.logs[logID]
+	if !found {
+		l = &followedLog{
+			followers: make(map[*follower]struct{}),
+			seen:      newSequenceSet(afterSequence),
+		}
+
+		if h.poll != nil {
+			ctx, cancel := context.WithCancel(context.Background())
+			l.cancel = cancel
+
+			go h.run(ctx, logID, key)
+		}
+
+		h.logs[logID] = l
+	}
+
+	f := &follower{
+		notify: make(chan struct{}, 1),
+	}
+	l.followers[f] = struct{}{}
+
+	return f
+}
+
+// unsubscribe removes a follower, stopping the poller of the log once it has
+// no followers left.
+func (h *followHub) unsubscribe(logID string, f *follower) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	l, found := h.logs[logID]
+	if !found {
+		return
+	}
+
+	if _, ok := l.followers[f]; !ok {
+		return
+	}
+
+	delete(l.followers, f)
+
+	if len(l.followers) == 0 {
+		h.stop(logID, l)
+	}
+}
+
+// publish delivers the messages of a log not already published to every
+// follower of the log, returning how many were delivered.
+func (h *followHub) publish(logID string, messages []*plspb.LogMessageListResponse) int {
+	if len(messages) == 0 {
+		return 0
+	}
+
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	l, found := h.logs[logID]
+	if !found {
+		return 0
+	}
+
+	var u followUpdate
+	for _, message := range messages {
+		if l.seen.add(message.GetSequence()) {
+			u.messages = append(u.messages, message)
+		}
+	}
+
+	if len(u.messages) == 0 {
+		return 0
+	}
+
+	for f := range l.followers {
+		f.push(u)
+	}
+
+	return len(u.messages)
+}
+
+// cursor returns the sequence after which to poll a log, or false if the log
+// is no longer followed.
+func (h *followHub) cursor(logID string) (int64, bool) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	l, found := h.logs[logID]
+	if !found {
+		return 0, false
+	}
+
+	l.seen.settle()
+
+	return l.seen.floor, true
+}
+
+// publishAppended publishes stored messages, given their plaintext payloads,
+// to the followers of their logs.
+func (h *followHub) publishAppended(messages []*LogMessage, payloads [][]byte) {
+	updates := make(map[string][]*plspb.LogMessageListResponse)
+	for i, message := range messages {
+		updates[message.LogID] = append(updates[message.LogID], &plspb.LogMessageListResponse{
+			LogMessageId: message.LogMessageID,
+			MediaType:    message.MediaType,
+			Payload:      payloads[i],
+			Timestamp:    timestamppb.New(message.Timestamp),
+			Sequence:     message.Sequence,
+			LogId:        message.LogID,
+		})
+	}
+
+	for logID, messages := range updates {
+		h.publish(logID, messages)
+	}
+}
+
+// end stops every follower of a log with the given error, for example when
+// the log is deleted, or errFollowSealed when it is sealed.
+func (h *followHub) end(logID string, err error) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	l, found := h.logs[logID]
+	if !found {
+		return
+	}
+
+	for f := range l.followers {
+		f.end(err)
+	}
+
+	h.stop(logID, l)
+}
+
+func (h *followHub) stop(logID string, l *followedLog) {
+	if l.cancel != nil {
+		l.cancel()
+	}
+
+	delete(h.logs, logID)
+}
+
+func (h *followHub) run(ctx context.Context, logID, key string) {
+	interval := followPollMinInterval
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		cursor, ok := h.cursor(logID)
+		if !ok {
+			return
+		}
+
+		messages, sealed, err := h.poll(ctx, logID, key, cursor)
+		if errors.Is(err, manager.ErrLogNotFound) {
+			// The log was deleted through another instance.
+			h.end(logID, err)
+			return
+		} else if err == nil && sealed {
+			h.publish(logID, messages)
+			h.end(logID, errFollowSealed)
+			return
+		}
+
+		// Messages above a gap are read again until the gap is filled, so
+		// only those not published before count as found.
+		if err != nil || h.publish(logID, messages) == 0 {
+			interval *= 2
+			if interval > followPollMaxInterval {
+				interval = followPollMaxInterval
+			}
+		} else {
+			interval = followPollMinInterval
+		}
+
+		timer.Reset(interval)
+	}
+}
+
+func newFollowHub(poll followPoller) *followHub {
+	return &followHub{
+		poll: poll,
+		logs: make(map[string]*followedLog),
+	}
+}
+
+// sequenceSet tracks the sequences delivered, so that a message both
+// published and polled is only sent once. Every sequence up to floor is
+// considered delivered. The floor is only raised over sequences that have
+// been recorded, or over a gap that has stayed open for followMaxGapAge, so
+// that a message stored late is not mistaken for one already delivered.
+type sequenceSet struct {
+	floor int64
+	above map[int64]struct{}
+
+	// gapSince is when the sequence after floor was first found missing.
+	gapSince time.Time
+}
+
+// add records a sequence, reporting whether it was not already delivered.
+func (ss *sequenceSet) add(sequence int64) bool {
+	if sequence <= ss.floor {
+		return false
+	}
+
+	if _, found := ss.above[sequence]; found {
+		return false
+	}
+
+	ss.above[sequence] = struct{}{}
+	ss.settle()
+
+	return true
+}
+
+// settle raises the floor over the sequences recorded directly above it,
+// skipping the gap below them once it has been open for too long.
+func (ss *sequenceSet) settle() {
+	floor := ss.floor
+	for {
+		if _, found := ss.above[ss.floor+1]; !found {
+			break
+		}
+
+		delete(ss.above, ss.floor+1)
+		ss.floor++
+	}
+
+	switch {
+	case len(ss.above) == 0:
+		ss.gapSince = time.Time{}
+	case ss.floor != floor || ss.gapSince.IsZero():
+		ss.gapSince = time.Now()
+	case time.Since(ss.gapSince) >= followMaxGapAge:
+		lowest := int64(-1)
+		for sequence := range ss.above {
+			if lowest < 0 || sequence < lowest {
+				lowest = sequence
+			}
+		}
+
+		ss.floor = lowest - 1
+		ss.settle()
+	}
+}
+
+func newSequenceSet(floor int64) *sequenceSet {
+	return &sequenceSet{
+		floor: floor,
+		above: make(map[int64]struct{}),
+	}
+}
+
+// followRequest describes a follower of a log.
+type followRequest struct {
+	logID string
+	key   string
+
+	// afterSequence is the sequence of the last message already sent.
+	afterSequence int64
+
+	// catchUp reads the messages stored after a sequence, in sequence order.
+	catchUp func(afterSequence int64) ([]*plspb.LogMessageListResponse, error)
+
+	// seal returns the seal of the log, or nil if it is not sealed.
+	seal func() (*model.LogSeal, error)
+
+	// match filters the messages to send.
+	match func(message *plspb.LogMessageListResponse) bool
+
+	// send sends a message to the client.
+	send func(message *plspb.LogMessageListResponse) error
+}
+
+// followLog sends the messages appended to a log after the given sequence
+// until the log is sealed, the context is done or the follower is ended.
+// Messages are sent once each, but messages appended concurrently on
+// different instances may arrive out of sequence order.
+//
+// The follower subscribes before catching up on the messages stored since it
+// last read the log, so that nothing appended in between is missed. Once the
+// log is sealed, messages whose sequences were reserved before the seal may
+// still be being stored, so it keeps catching up until it has seen the last
+// sequence of the seal, or until followMaxGapAge has passed, after which the
+// missing messages are assumed never to be stored.
+func followLog(ctx context.Context, hub *followHub, req followRequest) error {
+	f := hub.subscribe(req.logID, req.key, req.afterSequence)
+	defer hub.unsubscribe(req.logID, f)
+
+	sent := newSequenceSet(req.afterSequence)
+
+	deliver := func(messages []*plspb.LogMessageListResponse) error {
+		for _, message := range messages {
+			if !sent.add(message.GetSequence()) || !req.match(message) {
+				continue
+			}
+
+			if err := req.send(message); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	catchUp := func() error {
+		messages, err := req.catchUp(sent.floor)
+		if err != nil {
+			return err
+		}
+
+		return deliver(messages)
+	}
+
+	drain := func(seal *model.LogSeal) error {
+		timer := time.NewTimer(followMaxGapAge)
+		defer timer.Stop()
+
+		for {
+			if sent.floor >= seal.LastSequence {
+				return nil
+			}
+
+			if err := catchUp(); err != nil {
+				return err
+			}
+
+			if sent.floor >= seal.LastSequence {
+				return nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+				return nil
+			case <-time.After(followPollMinInterval):
+			}
+		}
+	}
+
+	// The log may have been sealed before this follower subscribed, in which
+	// case it was not told.
+	seal, err := req.seal()
+	if err != nil {
+		return err
+	} else if seal != nil {
+		return drain(seal)
+	}
+
+	if err := catchUp(); err != nil {
+		return err
+	}
+
+	for {
+		updates, err := f.next(ctx)
+		if err == errFollowSealed {
+			seal, err := req.seal()
+			if err != nil {
+				return err
+			}
+
+			return drain(seal)
+		} else if err != nil {
+			return err
+		}
+
+		for _, u := range updates {
+			if err := deliver(u.messages); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// listMatcher returns whether a message is within the time range of a list
+// request and matches its filter, if given.
+func listMatcher(in *plspb.LogMessageListRequest, filter *messageFilter) func(message *plspb.LogMessageListResponse) bool {
+	return func(message *plspb.LogMessageListResponse) bool {
+		if !filter.matches(message) {
+			return false
+		}
+
+		ts := message.GetTimestamp().AsTime()
+
+		if in.GetStartAt() != nil && ts.Before(in.GetStartAt().AsTime()) {
+			return false
+		}
+
+		if in.GetEndAt() != nil && ts.After(in.GetEndAt().AsTime()) {
+			return false
+		}
+
+		return true
+	}
+}
@@ -0,0 +1,91 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type mockFollowService_MessageListServer struct {
+	grpc.ServerStream
+	Ctx      context.Context
+	Messages chan *plspb.LogMessageListResponse
+}
+
+func (mfs *mockFollowService_MessageListServer) Context() context.Context {
+	return mfs.Ctx
+}
+
+func (mfs *mockFollowService_MessageListServer) Send(m *plspb.LogMessageListResponse) error {
+	mfs.Messages <- m
+	return nil
+}
+
+func TestInMemoryServerMessageListFollow(t *testing.T) {
+	s, ctx, logMetadata := newBatchTestServer(t)
+
+	logID := logMetadata[0].LogID
+
+	_, err := s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: logID, Payload: []byte("before")})
+	require.NoError(t, err)
+
+	followCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream := &mockFollowService_MessageListServer{
+		Ctx:      followCtx,
+		Messages: make(chan *plspb.LogMessageListResponse, 10),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.MessageList(&plspb.LogMessageListRequest{LogId: logID, Follow: true}, stream)
+	}()
+
+	receive := func() string {
+		select {
+		case m := <-stream.Messages:
+			return string(m.GetPayload())
+		case err := <-errCh:
+			require.FailNow(t, "follow ended early", "%+v", err)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for message")
+		}
+
+		return ""
+	}
+
+	assert.Equal(t, "before", receive())
+
+	_, err = s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: logID, Payload: []byte("pushed")})
+	require.NoError(t, err)
+	assert.Equal(t, "pushed", receive())
+
+	_, err = s.MessageAppendBatch(ctx, &plspb.LogMessageAppendBatchRequest{
+		Messages: []*plspb.LogMessageAppendRequest{
+			{LogId: logID, Payload: []byte("batch 1")},
+			{LogId: logID, Payload: []byte("batch 2")},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "batch 1", receive())
+	assert.Equal(t, "batch 2", receive())
+
+	cancel()
+
+	select {
+	case err := <-errCh:
+		assert.Equal(t, codes.Canceled, server.Status(err).Code())
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "follow did not end when the client went away")
+	}
+
+	assert.Empty(t, stream.Messages)
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+)
+
+// maxIdempotencyKeySize is the largest idempotency key accepted, in bytes.
+const maxIdempotencyKeySize = 128
+
+// idempotencyNamespace derives message identifiers from idempotency keys.
+var idempotencyNamespace = uuid.MustParse("5d1f7c5e-3a0b-4a63-9f2e-6c1a8e4b2d97")
+
+// idempotencyRecord is what a retried append returns in place of appending
+// the message again.
+type idempotencyRecord struct {
+	logMessageID string
+	sequence     int64
+}
+
+type idempotencyEntry struct {
+	// record is nil while the append is in progress.
+	record  *idempotencyRecord
+	expires time.Time
+}
+
+// IdempotencyCache remembers the outcome of appends made with an idempotency
+// key for a configured window.
+//
+// The cache is local to this instance and the mapping from a key to the
+// sequence of its message is not persisted. A retry that reaches another
+// instance, or that arrives after the window has expired, is not recognized:
+// it reserves a new sequence and is answered with it. The stored message is
+// still not duplicated, because its identifier, which is also the BigQuery
+// insert ID and the key the in-memory server stores messages by, is derived
+// from the key; the retried row is dropped and its sequence is left as a gap
+// in the log. Readers must therefore not assume that sequences are
+// contiguous.
+type IdempotencyCache struct {
+	window time.Duration
+
+	mut       sync.Mutex
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+}
+
+// claim claims an idempotency key for a new append to a log. If an append
+// with the key has already completed, its record is returned instead. The
+// claim must be completed once the message is stored, or released if it is
+// not. An empty key is never claimed.
+func (c *IdempotencyCache) claim(logID, key string) (*idempotencyClaim, *idempotencyRecord, error) {
+	if len(key) > maxIdempotencyKeySize {
+		return nil, nil, NewFieldError("idempotency_key", "must be at most 128 bytes")
+	}
+
+	if key == "" || c.window <= 0 {
+		return nil, nil, nil
+	}
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	now := time.Now()
+	c.sweep(now)
+
+	cacheKey := logID + "\x00" + key
+
+	if e, found := c.entries[cacheKey]; found {
+		if e.record == nil {
+			return nil, nil, ErrAppendInProgress
+		}
+
+		return nil, e.record, nil
+	}
+
+	c.entries[cacheKey] = &idempotencyEntry{}
+
+	return &idempotencyClaim{cache: c, key: cacheKey}, nil, nil
+}
+
+func (c *IdempotencyCache) complete(key string, record *idempotencyRecord) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.entries[key] = &idempotencyEntry{
+		record:  record,
+		expires: time.Now().Add(c.window),
+	}
+}
+
+func (c *IdempotencyCache) release(key string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	delete(c.entries, key)
+}
+
+func (c *IdempotencyCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.window {
+		return
+	}
+
+	for key, e := range c.entries {
+		// Appends in progress are released or completed by their claim.
+		if e.record != nil && !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+
+	c.lastSweep = now
+}
+
+func NewIdempotencyCache(cfg *opt.Config) *IdempotencyCache {
+	return &IdempotencyCache{
+		window:  cfg.MessageIdempotencyWindow,
+		entries: make(map[string]*idempotencyEntry),
+	}
+}
+
+// idempotencyClaim is held by an append while it is in progress. A nil claim
+// does nothing, so appends without an idempotency key need not check for one.
+type idempotencyClaim struct {
+	cache *IdempotencyCache
+	key   string
+	done  bool
+}
+
+// Complete records the stored message for retries of the append.
+func (ic *idempotencyClaim) Complete(record *idempotencyRecord) {
+	if ic == nil || ic.done {
+		return
+	}
+
+	ic.cache.complete(ic.key, record)
+	ic.done = true
+}
+
+// Release gives up the claim so that the append may be retried. It does
+// nothing once the claim is completed, so it may be deferred.
+func (ic *idempotencyClaim) Release() {
+	if ic == nil || ic.done {
+		return
+	}
+
+	ic.cache.release(ic.key)
+	ic.done = true
+}
+
+// newLogMessageID returns a new message identifier. Appends with an
+// idempotency key always get the same identifier for the same log, so that
+// retries can be recognized wherever they are handled.
+func newLogMessageID(logID, key string) string {
+	if key == "" {
+		return uuid.New().String()
+	}
+
+	return uuid.NewSHA1(idempotencyNamespace, []byte(logID+"\x00"+key)).String()
+}
@@ -0,0 +1,70 @@
+package server_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestInMemoryServerMessageAppendIdempotency(t *testing.T) {
+	s, ctx, logMetadata := newBatchTestServer(t)
+
+	req := &plspb.LogMessageAppendRequest{
+		LogId:          logMetadata[0].LogID,
+		Payload:        []byte("first"),
+		IdempotencyKey: "request-1",
+	}
+
+	first, err := s.MessageAppend(ctx, req)
+	require.NoError(t, err)
+
+	retry, err := s.MessageAppend(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, first.GetLogMessageId(), retry.GetLogMessageId())
+	assert.Equal(t, first.GetSequence(), retry.GetSequence())
+
+	batch, err := s.MessageAppendBatch(ctx, &plspb.LogMessageAppendBatchRequest{
+		Messages: []*plspb.LogMessageAppendRequest{
+			req,
+			{LogId: logMetadata[0].LogID, Payload: []byte("second"), IdempotencyKey: "request-2"},
+			{LogId: logMetadata[0].LogID, Payload: []byte("second"), IdempotencyKey: "request-2"},
+			{LogId: logMetadata[0].LogID, Payload: []byte("third")},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, batch.GetResults(), 4)
+
+	assert.Equal(t, first.GetLogMessageId(), batch.GetResults()[0].GetLogMessageId())
+	assert.Equal(t, int32(codes.OK), batch.GetResults()[1].GetErrorCode())
+	assert.Equal(t, int32(codes.Aborted), batch.GetResults()[2].GetErrorCode())
+	assert.Equal(t, int32(codes.OK), batch.GetResults()[3].GetErrorCode())
+
+	retry, err = s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{
+		LogId:          logMetadata[0].LogID,
+		Payload:        []byte("second"),
+		IdempotencyKey: "request-2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, batch.GetResults()[1].GetLogMessageId(), retry.GetLogMessageId())
+
+	stream := &mockListService_ListMessageServer{Ctx: ctx}
+	require.NoError(t, s.MessageList(&plspb.LogMessageListRequest{LogId: logMetadata[0].LogID}, stream))
+
+	var payloads []string
+	for _, message := range stream.Messages {
+		payloads = append(payloads, string(message.GetPayload()))
+	}
+	assert.Equal(t, []string{"first", "second", "third"}, payloads)
+
+	_, err = s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{
+		LogId:          logMetadata[0].LogID,
+		Payload:        []byte("too long"),
+		IdempotencyKey: strings.Repeat("k", 129),
+	})
+	assert.ErrorIs(t, err, server.ErrInvalid)
+}
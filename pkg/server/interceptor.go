@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hashicorp/vault/api"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// retryDelay is suggested to clients when a backend is unavailable.
+const retryDelay = time.Second
+
+// ErrorInterceptor translates the errors returned by the servers into gRPC
+// statuses, so that clients never see UNKNOWN for an error we understand.
+type ErrorInterceptor struct{}
+
+func (i *ErrorInterceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, Status(err).Err()
+		}
+
+		return resp, nil
+	}
+}
+
+func (i *ErrorInterceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := handler(srv, ss); err != nil {
+			return Status(err).Err()
+		}
+
+		return nil
+	}
+}
+
+// Status converts an error into a gRPC status. Errors that already carry a
+// status are returned as is. Errors that cannot be classified are reported
+// as INTERNAL without exposing their message.
+func Status(err error) *status.Status {
+	if s, ok := status.FromError(err); ok {
+		return s
+	}
+
+	var ferr *FieldError
+	var rerr *RateLimitError
+	var verr *api.ResponseError
+	var gerr *googleapi.Error
+	var nerr net.Error
+
+	switch {
+	case errors.As(err, &ferr):
+		return withDetails(status.New(codes.InvalidArgument, ferr.Error()), &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: ferr.Field, Description: ferr.Description},
+			},
+		})
+	case errors.As(err, &rerr):
+		qf := &errdetails.QuotaFailure{}
+		for _, v := range rerr.Violations {
+			qf.Violations = append(qf.Violations, &errdetails.QuotaFailure_Violation{
+				Subject:     v.Subject,
+				Description: v.Description,
+			})
+		}
+
+		return withDetails(status.New(codes.ResourceExhausted, rerr.Error()), qf, &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(rerr.RetryDelay),
+		})
+	case errors.Is(err, ErrInvalid), errors.Is(err, manager.ErrInvalidLog):
+		return status.New(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrAppendInProgress), errors.Is(err, ErrFollowBehind), errors.Is(err, manager.ErrConcurrentUpdate):
+		return status.New(codes.Aborted, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, manager.ErrLogNotFound), errors.Is(err, manager.ErrCredentialNotFound):
+		return status.New(codes.NotFound, err.Error())
+	case errors.Is(err, manager.ErrLogSealed):
+		return status.New(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, manager.ErrCredentialExists):
+		return status.New(codes.AlreadyExists, err.Error())
+	case errors.Is(err, manager.ErrInvalidToken), errors.Is(err, manager.ErrCredentialExpired):
+		return status.New(codes.Unauthenticated, err.Error())
+	case errors.As(err, &verr):
+		return httpStatus("vault", verr.StatusCode, err)
+	case errors.As(err, &gerr):
+		return httpStatus("bigquery", gerr.Code, err)
+	case errors.As(err, &nerr):
+		return unavailable("backend", err)
+	}
+
+	log.Printf("internal error: %v", err)
+	return status.New(codes.Internal, "internal error")
+}
+
+// httpStatus classifies an error returned by one of our HTTP backends. Only
+// transient failures are reported to clients; anything else is a problem with
+// the service itself.
+func httpStatus(backend string, code int, err error) *status.Status {
+	switch {
+	case code == http.StatusTooManyRequests, code >= http.StatusInternalServerError:
+		return unavailable(backend, err)
+	}
+
+	log.Printf("internal %s error: %v", backend, err)
+	return status.New(codes.Internal, "internal error")
+}
+
+func unavailable(backend string, err error) *status.Status {
+	log.Printf("%s unavailable: %v", backend, err)
+
+	return withDetails(status.New(codes.Unavailable, backend+" unavailable"), &errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryDelay),
+	})
+}
+
+// withDetails attaches details to a status. If they cannot be encoded, the
+// status is still useful without them.
+func withDetails(s *status.Status, details ...proto.Message) *status.Status {
+	ds, err := s.WithDetails(details...)
+	if err != nil {
+		return s
+	}
+
+	return ds
+}
+
+func NewErrorInterceptor() *ErrorInterceptor {
+	return &ErrorInterceptor{}
+}
@@ -0,0 +1,92 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Err      error
+		Expected codes.Code
+	}{
+		{Name: "Status", Err: auth.ErrPermissionDenied, Expected: codes.PermissionDenied},
+		{Name: "Field", Err: server.NewFieldError("name", "must not be empty"), Expected: codes.InvalidArgument},
+		{Name: "Log not found", Err: fmt.Errorf("get: %w", manager.ErrLogNotFound), Expected: codes.NotFound},
+		{Name: "Credential not found", Err: manager.ErrCredentialNotFound, Expected: codes.NotFound},
+		{Name: "Log sealed", Err: manager.ErrLogSealed, Expected: codes.FailedPrecondition},
+		{Name: "Credential exists", Err: manager.ErrCredentialExists, Expected: codes.AlreadyExists},
+		{Name: "Append in progress", Err: server.ErrAppendInProgress, Expected: codes.Aborted},
+		{Name: "Follower behind", Err: server.ErrFollowBehind, Expected: codes.Aborted},
+		{Name: "Invalid token", Err: manager.ErrInvalidToken, Expected: codes.Unauthenticated},
+		{Name: "Canceled", Err: context.Canceled, Expected: codes.Canceled},
+		{Name: "Vault unavailable", Err: &api.ResponseError{StatusCode: http.StatusServiceUnavailable}, Expected: codes.Unavailable},
+		{Name: "Vault forbidden", Err: &api.ResponseError{StatusCode: http.StatusForbidden}, Expected: codes.Internal},
+		{Name: "BigQuery rate limited", Err: &googleapi.Error{Code: http.StatusTooManyRequests}, Expected: codes.Unavailable},
+		{Name: "Unknown", Err: errors.New("boom"), Expected: codes.Internal},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, server.Status(test.Err).Code())
+		})
+	}
+}
+
+func TestStatusDetails(t *testing.T) {
+	s := server.Status(server.NewFieldError("expires_at", "must be in the future"))
+	require.Len(t, s.Details(), 1)
+
+	br, ok := s.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, br.GetFieldViolations(), 1)
+	assert.Equal(t, "expires_at", br.GetFieldViolations()[0].GetField())
+	assert.Equal(t, "must be in the future", br.GetFieldViolations()[0].GetDescription())
+
+	s = server.Status(&api.ResponseError{StatusCode: http.StatusBadGateway})
+	require.Len(t, s.Details(), 1)
+
+	_, ok = s.Details()[0].(*errdetails.RetryInfo)
+	assert.True(t, ok)
+
+	// Unclassified errors must not leak their message.
+	assert.NotContains(t, server.Status(errors.New("secret detail")).Message(), "secret detail")
+}
+
+func TestErrorInterceptor(t *testing.T) {
+	i := server.NewErrorInterceptor()
+
+	_, err := i.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, manager.ErrLogNotFound
+		})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	err = i.StreamServerInterceptor()(nil, nil, &grpc.StreamServerInfo{},
+		func(srv interface{}, ss grpc.ServerStream) error {
+			return server.NewFieldError("name", "must not be empty")
+		})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := i.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+)
+
+const (
+	MediaTypeJSON        = "application/json"
+	MediaTypeOctetStream = "application/octet-stream"
+	MediaTypeText        = "text/plain"
+)
+
+// mediaTypeValidators are the media types the service knows how to accept,
+// along with a check that a payload conforms to the media type, if any.
+var mediaTypeValidators = map[string]func(payload []byte) bool{
+	MediaTypeJSON:        json.Valid,
+	MediaTypeOctetStream: nil,
+	MediaTypeText:        utf8.Valid,
+}
+
+// MediaTypeRegistry validates the media types and payloads of appended
+// messages against the media types enabled in the configuration.
+type MediaTypeRegistry struct {
+	maxPayloadSize int
+	validators     map[string]func(payload []byte) bool
+}
+
+// Validate checks a message payload and returns its normalized media type. An
+// empty media type is treated as application/octet-stream.
+func (r *MediaTypeRegistry) Validate(mediaType string, payload []byte) (string, error) {
+	if r.maxPayloadSize > 0 && len(payload) > r.maxPayloadSize {
+		return "", NewFieldError("payload", fmt.Sprintf("must not be larger than %d bytes", r.maxPayloadSize))
+	}
+
+	if mediaType == "" {
+		mediaType = MediaTypeOctetStream
+	}
+
+	mt, params, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return "", NewFieldError("media_type", err.Error())
+	}
+
+	validator, ok := r.validators[mt]
+	if !ok {
+		return "", NewFieldError("media_type", fmt.Sprintf("must be one of %s", strings.Join(r.MediaTypes(), ", ")))
+	}
+
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		return "", NewFieldError("media_type", "only the utf-8 charset is supported")
+	}
+
+	if validator != nil && !validator(payload) {
+		return "", NewFieldError("payload", fmt.Sprintf("is not valid %s", mt))
+	}
+
+	return mt, nil
+}
+
+// MediaTypes returns the enabled media types in order.
+func (r *MediaTypeRegistry) MediaTypes() []string {
+	mts := make([]string, 0, len(r.validators))
+	for mt := range r.validators {
+		mts = append(mts, mt)
+	}
+
+	sort.Strings(mts)
+
+	return mts
+}
+
+func NewMediaTypeRegistry(cfg *opt.Config) (*MediaTypeRegistry, error) {
+	r := &MediaTypeRegistry{
+		maxPayloadSize: cfg.MessageMaxPayloadSize,
+		validators:     make(map[string]func(payload []byte) bool),
+	}
+
+	mediaTypes := cfg.MessageMediaTypes
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{MediaTypeOctetStream}
+	}
+
+	for _, mediaType := range mediaTypes {
+		mt, _, err := mime.ParseMediaType(mediaType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid media type %q: %w", mediaType, err)
+		}
+
+		validator, ok := mediaTypeValidators[mt]
+		if !ok {
+			return nil, fmt.Errorf("unsupported media type %q", mediaType)
+		}
+
+		r.validators[mt] = validator
+	}
+
+	return r, nil
+}
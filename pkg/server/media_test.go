@@ -0,0 +1,55 @@
+package server_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMediaTypeRegistry(t *testing.T) {
+	cfg := &opt.Config{
+		MessageMaxPayloadSize: 16,
+		MessageMediaTypes:     []string{server.MediaTypeOctetStream, server.MediaTypeText},
+	}
+
+	r, err := server.NewMediaTypeRegistry(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{server.MediaTypeOctetStream, server.MediaTypeText}, r.MediaTypes())
+
+	tests := []struct {
+		Name      string
+		MediaType string
+		Payload   []byte
+		Expected  string
+		Field     string
+	}{
+		{Name: "Default", Payload: []byte{0xff}, Expected: server.MediaTypeOctetStream},
+		{Name: "Normalized", MediaType: "Text/Plain; charset=UTF-8", Payload: []byte("hello"), Expected: server.MediaTypeText},
+		{Name: "Too large", Payload: bytes.Repeat([]byte("a"), 17), Field: "payload"},
+		{Name: "Not enabled", MediaType: server.MediaTypeJSON, Payload: []byte("{}"), Field: "media_type"},
+		{Name: "Malformed", MediaType: "text/", Payload: []byte("hello"), Field: "media_type"},
+		{Name: "Unsupported charset", MediaType: "text/plain; charset=latin1", Payload: []byte("hello"), Field: "media_type"},
+		{Name: "Invalid text", MediaType: server.MediaTypeText, Payload: []byte{0xff}, Field: "payload"},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mt, err := r.Validate(test.MediaType, test.Payload)
+			if test.Field != "" {
+				var ferr *server.FieldError
+				require.ErrorAs(t, err, &ferr)
+				assert.Equal(t, test.Field, ferr.Field)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, mt)
+		})
+	}
+
+	_, err = server.NewMediaTypeRegistry(&opt.Config{MessageMediaTypes: []string{"image/png"}})
+	assert.Error(t, err)
+}
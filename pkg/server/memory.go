@@ -2,11 +2,15 @@ package server
 
 import (
 	"context"
+	"log"
+	"sort"
+	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/google/wire"
 	"github.com/puppetlabs/leg/timeutil/pkg/retry"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
 	"github.com/puppetlabs/relay-pls/pkg/model"
 	"github.com/puppetlabs/relay-pls/pkg/opt"
 	"github.com/puppetlabs/relay-pls/pkg/plspb"
@@ -15,25 +19,87 @@ import (
 
 var InMemoryServerSet = wire.NewSet(
 	NewInMemoryServer,
+	NewMediaTypeRegistry,
+	NewRateLimiter,
+	NewIdempotencyCache,
 )
 
 type InMemoryServer struct {
 	plspb.UnimplementedLogServer
 	logMetadataManager model.LogMetadataManager
 	keyManager         model.KeyManager
-	messages           map[string][]*LogMessage
+	mediaTypes         *MediaTypeRegistry
+	rateLimiter        *RateLimiter
+	idempotency        *IdempotencyCache
+	follows            *followHub
+	systemLogs         *systemLogWriter
+
+	mut      sync.RWMutex
+	messages map[string]*memoryLog
+}
+
+// memoryLog holds the stored messages of a log in sequence order, indexed by
+// their identifiers.
+type memoryLog struct {
+	messages []*LogMessage
+	byID     map[string]*LogMessage
+}
+
+// insert stores a message unless one with the same identifier is already
+// stored. Messages almost always arrive in sequence order, so they are
+// usually appended; concurrent appends may store them slightly out of order.
+func (l *memoryLog) insert(message *LogMessage) {
+	if _, found := l.byID[message.LogMessageID]; found {
+		return
+	}
+
+	l.byID[message.LogMessageID] = message
+
+	i := sort.Search(len(l.messages), func(i int) bool {
+		return l.messages[i].Sequence > message.Sequence
+	})
+
+	l.messages = append(l.messages, nil)
+	copy(l.messages[i+1:], l.messages[i:])
+	l.messages[i] = message
+}
+
+// after returns a copy of the messages stored after a sequence.
+func (l *memoryLog) after(afterSequence int64) []*LogMessage {
+	i := sort.Search(len(l.messages), func(i int) bool {
+		return l.messages[i].Sequence > afterSequence
+	})
+
+	return append([]*LogMessage(nil), l.messages[i:]...)
+}
+
+func newMemoryLog() *memoryLog {
+	return &memoryLog{
+		byID: make(map[string]*LogMessage),
+	}
 }
 
 func (s *InMemoryServer) Create(ctx context.Context, in *plspb.LogCreateRequest) (*plspb.LogCreateResponse, error) {
-	if in.GetContext() == "" || in.GetName() == "" {
-		return nil, ErrInvalid
+	if in.GetName() == "" {
+		return nil, NewFieldError("name", "must not be empty")
+	}
+
+	if err := auth.RequireScope(ctx, model.ScopeLogCreate); err != nil {
+		return nil, err
 	}
 
+	logContext, err := auth.ResolveContext(ctx, model.ScopeLogCreate, in.GetContext())
+	if err != nil {
+		return nil, err
+	}
+
+	credential, _ := auth.CredentialFromContext(ctx)
+
 	lm, err := s.logMetadataManager.Create(ctx,
 		&model.Log{
-			Context: in.Context,
-			Name:    in.Name,
-		})
+			Context: logContext,
+			Name:    in.GetName(),
+		}, credential.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -44,24 +110,131 @@ func (s *InMemoryServer) Create(ctx context.Context, in *plspb.LogCreateRequest)
 }
 
 func (s *InMemoryServer) Delete(ctx context.Context, in *plspb.LogDeleteRequest) (*plspb.LogDeleteResponse, error) {
-	return nil, nil
+	lmm, err := s.logMetadataManager.Get(ctx, in.GetLogId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auth.Authorize(ctx, model.ScopeLogDelete, lmm.Log); err != nil {
+		return nil, err
+	}
+
+	if err := s.logMetadataManager.Delete(ctx, in.GetLogId()); err != nil {
+		return nil, err
+	}
+
+	s.mut.Lock()
+	delete(s.messages, in.GetLogId())
+	s.mut.Unlock()
+
+	if err := s.logMetadataManager.Purged(ctx, in.GetLogId()); err != nil {
+		log.Printf("failed to record purge of messages for log %s: %v", in.GetLogId(), err)
+	}
+
+	s.follows.end(in.GetLogId(), manager.ErrLogNotFound)
+
+	return &plspb.LogDeleteResponse{}, nil
 }
 
 func (s *InMemoryServer) List(in *plspb.LogListRequest, stream plspb.Log_ListServer) error {
+	ctx := stream.Context()
+
+	if err := auth.RequireScope(ctx, model.ScopeLogRead); err != nil {
+		return err
+	}
+
+	match, err := auth.ListMatcher(ctx, in.GetContexts())
+	if err != nil {
+		return err
+	}
+
+	lms, err := s.logMetadataManager.List(ctx, auth.ListPrefixes(ctx, in.GetContexts()), match)
+	if err != nil {
+		return err
+	}
+
+	for _, lm := range lms {
+		if err := stream.Send(logListResponse(lm)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+func (s *InMemoryServer) Seal(ctx context.Context, in *plspb.LogSealRequest) (*plspb.LogSealResponse, error) {
+	lm, err := s.logMetadataManager.Get(ctx, in.GetLogId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auth.Authorize(ctx, model.ScopeLogAppend, lm.Log); err != nil {
+		return nil, err
+	}
+
+	seal, err := s.logMetadataManager.Seal(ctx, in.GetLogId(), requestedSeal(in))
+	if err != nil {
+		return nil, err
+	}
+
+	s.follows.end(in.GetLogId(), errFollowSealed)
+
+	return sealResponse(in.GetLogId(), seal), nil
+}
+
 func (s *InMemoryServer) MessageAppend(ctx context.Context, in *plspb.LogMessageAppendRequest) (*plspb.LogMessageAppendResponse, error) {
 	lmm, err := s.logMetadataManager.Get(ctx, in.GetLogId())
 	if err != nil {
 		return nil, err
 	}
 
+	if err := auth.Authorize(ctx, model.ScopeLogAppend, lmm.Log); err != nil {
+		return nil, err
+	}
+
+	// A concurrent seal is caught when the sequence is reserved.
+	if lmm.Seal != nil {
+		return nil, manager.ErrLogSealed
+	}
+
+	claim, previous, err := s.idempotency.claim(in.GetLogId(), in.GetIdempotencyKey())
+	if err != nil {
+		return nil, err
+	} else if previous != nil {
+		return &plspb.LogMessageAppendResponse{
+			LogId:        in.GetLogId(),
+			LogMessageId: previous.logMessageID,
+			Sequence:     previous.sequence,
+		}, nil
+	}
+	defer claim.Release()
+
+	mediaType, err := s.mediaTypes.Validate(in.GetMediaType(), in.GetPayload())
+	if err != nil {
+		return nil, err
+	}
+
+	credential, _ := auth.CredentialFromContext(ctx)
+
+	err = s.rateLimiter.Allow(RateLimitSubject{
+		LogID:        in.GetLogId(),
+		Context:      lmm.Log.Context,
+		CredentialID: credential.ID,
+	}, len(in.GetPayload()))
+	if err != nil {
+		return nil, err
+	}
+
 	ct, err := s.keyManager.Encrypt(ctx, lmm.Key, in.GetPayload())
 	if err != nil {
 		return nil, err
 	}
 
+	sequence, err := s.logMetadataManager.ReserveSequence(ctx, in.GetLogId(), 1)
+	if err != nil {
+		return nil, err
+	}
+
 	ts := time.Now()
 	if in.GetTimestamp() != nil {
 		ts = in.GetTimestamp().AsTime()
@@ -69,65 +242,262 @@ func (s *InMemoryServer) MessageAppend(ctx context.Context, in *plspb.LogMessage
 
 	message := &LogMessage{
 		LogID:            in.GetLogId(),
-		LogMessageID:     uuid.New().String(),
+		LogMessageID:     newLogMessageID(in.GetLogId(), in.GetIdempotencyKey()),
 		Timestamp:        ts,
+		MediaType:        mediaType,
+		Sequence:         sequence,
 		EncryptedPayload: ct,
 	}
 
-	if s.messages == nil {
-		s.messages = make(map[string][]*LogMessage)
-	}
-	if s.messages[in.GetLogId()] == nil {
-		s.messages[in.GetLogId()] = make([]*LogMessage, 0)
+	if _, err := s.insertMessages(ctx, []*LogMessage{message}); err != nil {
+		return nil, err
 	}
 
-	s.messages[in.GetLogId()] = append(s.messages[in.GetLogId()], message)
+	s.follows.publishAppended([]*LogMessage{message}, [][]byte{in.GetPayload()})
+
+	claim.Complete(&idempotencyRecord{
+		logMessageID: message.LogMessageID,
+		sequence:     message.Sequence,
+	})
 
 	return &plspb.LogMessageAppendResponse{
 		LogId:        message.LogID,
 		LogMessageId: message.LogMessageID,
+		Sequence:     message.Sequence,
 	}, nil
 }
 
+func (s *InMemoryServer) MessageAppendBatch(ctx context.Context, in *plspb.LogMessageAppendBatchRequest) (*plspb.LogMessageAppendBatchResponse, error) {
+	if err := auth.RequireScope(ctx, model.ScopeLogAppend); err != nil {
+		return nil, err
+	}
+
+	b := s.newMessageBatch()
+	for _, message := range in.GetMessages() {
+		b.Add(ctx, message)
+	}
+	b.Flush(ctx)
+
+	return b.Response(), nil
+}
+
+func (s *InMemoryServer) MessageAppendStream(stream plspb.Log_MessageAppendStreamServer) error {
+	if err := auth.RequireScope(stream.Context(), model.ScopeLogAppend); err != nil {
+		return err
+	}
+
+	return appendStream(stream, s.newMessageBatch())
+}
+
 func (s *InMemoryServer) MessageList(in *plspb.LogMessageListRequest, stream plspb.Log_MessageListServer) error {
-	ctx := context.Background()
+	ctx := stream.Context()
 
-	lmm, err := s.logMetadataManager.Get(ctx, in.GetLogId())
+	window, err := newListWindow(in)
 	if err != nil {
 		return err
 	}
 
-	for _, message := range s.messages[in.GetLogId()] {
-		payload, err := s.keyManager.Decrypt(ctx, lmm.Key, message.EncryptedPayload)
-		if err != nil {
-			return err
-		}
-		resp := &plspb.LogMessageListResponse{
-			LogMessageId: message.LogMessageID,
-			Payload:      payload,
-			Timestamp:    timestamppb.New(message.Timestamp),
+	filter, err := newMessageFilter(in)
+	if err != nil {
+		return err
+	}
+
+	lms, err := listLogs(ctx, in, s.logMetadataManager)
+	if err != nil || len(lms) == 0 {
+		return err
+	}
+
+	afterSequence, err := listAfterSequence(in, func(logMessageID string) (int64, bool, error) {
+		if message := s.findMessage(lms[0].LogID, logMessageID); message != nil {
+			return message.Sequence, true, nil
 		}
-		err = retry.Wait(ctx, func(ctx context.Context) (bool, error) {
-			if serr := stream.Send(resp); serr != nil {
+
+		return 0, false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Context lines around a match are only known once every message in
+	// the time range has been read, so the filter is applied separately.
+	match := listMatcher(in, nil)
+
+	send := func(message *plspb.LogMessageListResponse) error {
+		return retry.Wait(ctx, func(ctx context.Context) (bool, error) {
+			if serr := stream.Send(message); serr != nil {
 				return false, serr
 			}
 
 			return true, nil
 		})
+	}
+
+	var messages []*plspb.LogMessageListResponse
+	for _, lm := range lms {
+		logMessages, err := s.listMessages(ctx, lm.Key, lm.LogID, afterSequence)
 		if err != nil {
 			return err
 		}
+
+		messages = append(messages, logMessages...)
 	}
 
-	return nil
+	if len(lms) > 1 {
+		mergeMessages(messages)
+	}
+
+	after := make(map[string]int64, len(lms))
+	for _, lm := range lms {
+		after[lm.LogID] = afterSequence
+	}
+
+	var matched []*plspb.LogMessageListResponse
+	for _, message := range messages {
+		if message.GetSequence() > after[message.GetLogId()] {
+			after[message.GetLogId()] = message.GetSequence()
+		}
+
+		if match(message) {
+			matched = append(matched, message)
+		}
+	}
+
+	for _, message := range window.apply(filter.apply(matched)) {
+		if err := send(message); err != nil {
+			return err
+		}
+	}
+
+	if !in.GetFollow() {
+		return nil
+	}
+
+	var reqs []followRequest
+	for _, lm := range lms {
+		if lm.Seal != nil && after[lm.LogID] >= lm.Seal.LastSequence {
+			continue
+		}
+
+		lm := lm
+		reqs = append(reqs, followRequest{
+			logID:         lm.LogID,
+			key:           lm.Key,
+			afterSequence: after[lm.LogID],
+			catchUp: func(afterSequence int64) ([]*plspb.LogMessageListResponse, error) {
+				return s.listMessages(ctx, lm.Key, lm.LogID, afterSequence)
+			},
+			seal: func() (*model.LogSeal, error) {
+				current, err := s.logMetadataManager.Get(ctx, lm.LogID)
+				if err != nil {
+					return nil, err
+				}
+
+				return current.Seal, nil
+			},
+			match: listMatcher(in, filter),
+			send:  send,
+		})
+	}
+
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	return followLogs(ctx, s.follows, reqs)
+}
+
+// listMessages decrypts the messages of a log stored after a sequence.
+func (s *InMemoryServer) listMessages(ctx context.Context, key, logID string, afterSequence int64) ([]*plspb.LogMessageListResponse, error) {
+	var r []*plspb.LogMessageListResponse
+
+	for _, message := range s.storedMessages(logID, afterSequence) {
+		payload, err := s.keyManager.Decrypt(ctx, key, message.EncryptedPayload)
+		if err != nil {
+			return nil, err
+		}
+
+		r = append(r, &plspb.LogMessageListResponse{
+			LogMessageId: message.LogMessageID,
+			MediaType:    message.MediaType,
+			Payload:      payload,
+			Timestamp:    timestamppb.New(message.Timestamp),
+			Sequence:     message.Sequence,
+			LogId:        message.LogID,
+		})
+	}
+
+	return r, nil
+}
+
+// storedMessages returns the messages of a log stored after a sequence, in
+// sequence order.
+func (s *InMemoryServer) storedMessages(logID string, afterSequence int64) []*LogMessage {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	l, found := s.messages[logID]
+	if !found {
+		return nil
+	}
+
+	return l.after(afterSequence)
+}
+
+func (s *InMemoryServer) insertMessages(ctx context.Context, messages []*LogMessage) (map[int]error, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.messages == nil {
+		s.messages = make(map[string]*memoryLog)
+	}
+
+	// Like BigQuery, drop messages already stored with the same identifier.
+	for _, message := range messages {
+		l, found := s.messages[message.LogID]
+		if !found {
+			l = newMemoryLog()
+			s.messages[message.LogID] = l
+		}
+
+		l.insert(message)
+	}
+
+	return nil, nil
+}
+
+func (s *InMemoryServer) findMessage(logID, logMessageID string) *LogMessage {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	l, found := s.messages[logID]
+	if !found {
+		return nil
+	}
+
+	return l.byID[logMessageID]
+}
+
+// WriteSystemLog appends messages to a log kept by the service itself.
+func (s *InMemoryServer) WriteSystemLog(ctx context.Context, name string, messages []*model.SystemLogMessage) error {
+	return s.systemLogs.write(ctx, name, messages)
+}
+
+func (s *InMemoryServer) newMessageBatch() *messageBatch {
+	return newMessageBatch(s.logMetadataManager, s.keyManager, s.mediaTypes, s.rateLimiter, s.idempotency, s.follows, s.insertMessages)
 }
 
 func NewInMemoryServer(cfg *opt.Config,
-	keyManager model.KeyManager, logMetadataManager model.LogMetadataManager) plspb.LogServer {
+	keyManager model.KeyManager, logMetadataManager model.LogMetadataManager,
+	mediaTypes *MediaTypeRegistry, rateLimiter *RateLimiter, idempotency *IdempotencyCache) plspb.LogServer {
 	s := &InMemoryServer{
 		logMetadataManager: logMetadataManager,
 		keyManager:         keyManager,
+		mediaTypes:         mediaTypes,
+		rateLimiter:        rateLimiter,
+		idempotency:        idempotency,
+		follows:            newFollowHub(nil),
 	}
+	s.systemLogs = newSystemLogWriter(logMetadataManager, keyManager, s.follows, s.insertMessages)
 
 	return s
 }
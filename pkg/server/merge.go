@@ -0,0 +1,289 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+)
+
+// listMaxLogs is the most logs whose messages a single list request may
+// merge.
+const listMaxLogs = 100
+
+// listLogs resolves the logs whose messages a list request retrieves, either
+// those identified by log_id and log_ids or every log in a context, and
+// checks that the caller may read each of them.
+func listLogs(ctx context.Context, in *plspb.LogMessageListRequest, logMetadataManager model.LogMetadataManager) ([]*model.LogMetadata, error) {
+	var lms []*model.LogMetadata
+
+	if in.GetContext() != "" {
+		if in.GetLogId() != "" || len(in.GetLogIds()) > 0 {
+			return nil, NewFieldError("context", "cannot be combined with log_id or log_ids")
+		}
+
+		var err error
+		lms, err = contextLogs(ctx, in.GetContext(), logMetadataManager)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		logIDs := in.GetLogIds()
+		if in.GetLogId() != "" || len(logIDs) == 0 {
+			logIDs = append([]string{in.GetLogId()}, logIDs...)
+		}
+
+		seen := make(map[string]struct{}, len(logIDs))
+		for _, logID := range logIDs {
+			if _, found := seen[logID]; found {
+				continue
+			}
+			seen[logID] = struct{}{}
+
+			if len(seen) > listMaxLogs {
+				return nil, NewFieldError("log_ids", fmt.Sprintf("must not request more than %d log streams", listMaxLogs))
+			}
+
+			lm, err := logMetadataManager.Get(ctx, logID)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := auth.Authorize(ctx, model.ScopeLogRead, lm.Log); err != nil {
+				return nil, err
+			}
+
+			lms = append(lms, lm)
+		}
+	}
+
+	if len(lms) > 1 {
+		if in.GetAfterSequence() != 0 {
+			return nil, NewFieldError("after_sequence", "cannot be used with more than one log stream")
+		} else if in.GetAfterMessageId() != "" {
+			return nil, NewFieldError("after_message_id", "cannot be used with more than one log stream")
+		}
+	}
+
+	return lms, nil
+}
+
+// contextLogs returns the metadata, including encryption keys, of every log
+// in a context.
+func contextLogs(ctx context.Context, requested string, logMetadataManager model.LogMetadataManager) ([]*model.LogMetadata, error) {
+	if err := auth.RequireScope(ctx, model.ScopeLogRead); err != nil {
+		return nil, err
+	}
+
+	logContext, err := auth.ResolveContext(ctx, model.ScopeLogRead, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	listed, err := logMetadataManager.ListContext(ctx, logContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(listed) > listMaxLogs {
+		return nil, NewFieldError("context", fmt.Sprintf("contains more than %d log streams", listMaxLogs))
+	}
+
+	// Listed metadata does not include encryption keys.
+	lms := make([]*model.LogMetadata, 0, len(listed))
+	for _, l := range listed {
+		lm, err := logMetadataManager.Get(ctx, l.LogID)
+		if errors.Is(err, manager.ErrLogNotFound) {
+			// Deleted since it was listed.
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		lms = append(lms, lm)
+	}
+
+	return lms, nil
+}
+
+// followReorderWindow is how long messages followed from several logs are
+// held so that those arriving close together can be sent in order.
+const followReorderWindow = 250 * time.Millisecond
+
+// messageBefore returns whether a message of one log is ordered before a
+// message of another, by timestamp, then by sequence.
+func messageBefore(a, b *plspb.LogMessageListResponse) bool {
+	ta, tb := a.GetTimestamp().AsTime(), b.GetTimestamp().AsTime()
+	if !ta.Equal(tb) {
+		return ta.Before(tb)
+	}
+
+	return a.GetSequence() < b.GetSequence()
+}
+
+// mergeMessages orders the messages of several logs by timestamp, then by
+// sequence.
+func mergeMessages(messages []*plspb.LogMessageListResponse) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messageBefore(messages[i], messages[j])
+	})
+}
+
+type heldMessage struct {
+	message *plspb.LogMessageListResponse
+	arrived time.Time
+}
+
+// followMerger holds the messages followed from several logs for the reorder
+// window, in order.
+type followMerger struct {
+	held []heldMessage
+}
+
+func (m *followMerger) add(message *plspb.LogMessageListResponse, now time.Time) {
+	i := sort.Search(len(m.held), func(i int) bool {
+		return messageBefore(message, m.held[i].message)
+	})
+
+	m.held = append(m.held, heldMessage{})
+	copy(m.held[i+1:], m.held[i:])
+	m.held[i] = heldMessage{message: message, arrived: now}
+}
+
+// release removes and returns, in order, every message held for the reorder
+// window and those ordered before it.
+func (m *followMerger) release(now time.Time) []*plspb.LogMessageListResponse {
+	n := 0
+	for i, h := range m.held {
+		if now.Sub(h.arrived) >= followReorderWindow {
+			n = i + 1
+		}
+	}
+
+	return m.take(n)
+}
+
+// flush removes and returns every message held, in order.
+func (m *followMerger) flush() []*plspb.LogMessageListResponse {
+	return m.take(len(m.held))
+}
+
+func (m *followMerger) take(n int) []*plspb.LogMessageListResponse {
+	messages := make([]*plspb.LogMessageListResponse, n)
+	for i := range messages {
+		messages[i] = m.held[i].message
+	}
+
+	m.held = append(m.held[:0], m.held[n:]...)
+
+	return messages
+}
+
+// next returns when the next message is due to be released, or false if no
+// messages are held.
+func (m *followMerger) next() (time.Time, bool) {
+	if len(m.held) == 0 {
+		return time.Time{}, false
+	}
+
+	due := m.held[0].arrived
+	for _, h := range m.held[1:] {
+		if h.arrived.Before(due) {
+			due = h.arrived
+		}
+	}
+
+	return due.Add(followReorderWindow), true
+}
+
+// followLogs follows several logs at once, sending their messages to the
+// client until every log is sealed, the context is done or any follower
+// fails. Messages are held for followReorderWindow and sent by timestamp,
+// then by sequence, so messages of different logs that arrive close together
+// are in order; one that arrives later than that is sent as soon as it
+// arrives.
+func followLogs(ctx context.Context, hub *followHub, reqs []followRequest) error {
+	if len(reqs) == 1 {
+		return followLog(ctx, hub, reqs[0])
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Every follower sends to the same stream, which only this goroutine
+	// sends to from here on.
+	send := reqs[0].send
+
+	arrivals := make(chan *plspb.LogMessageListResponse)
+	errs := make(chan error, len(reqs))
+	for _, req := range reqs {
+		req := req
+		req.send = func(message *plspb.LogMessageListResponse) error {
+			select {
+			case arrivals <- message:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		go func() {
+			errs <- followLog(ctx, hub, req)
+		}()
+	}
+
+	timer := time.NewTimer(followReorderWindow)
+	defer timer.Stop()
+
+	var err error
+	sendAll := func(messages []*plspb.LogMessageListResponse) {
+		for _, message := range messages {
+			if err != nil {
+				return
+			}
+
+			if serr := send(message); serr != nil {
+				err = serr
+				cancel()
+			}
+		}
+	}
+
+	merger := &followMerger{}
+	for remaining := len(reqs); remaining > 0; {
+		var due <-chan time.Time
+		if next, ok := merger.next(); ok {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(time.Until(next))
+			due = timer.C
+		}
+
+		select {
+		case message := <-arrivals:
+			merger.add(message, time.Now())
+		case <-due:
+			sendAll(merger.release(time.Now()))
+		case ferr := <-errs:
+			remaining--
+			if ferr != nil && err == nil {
+				err = ferr
+				cancel()
+			}
+		}
+	}
+
+	sendAll(merger.flush())
+
+	return err
+}
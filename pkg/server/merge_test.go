@@ -0,0 +1,159 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestInMemoryServerMessageListMerged(t *testing.T) {
+	s, ctx, logMetadata := newBatchTestServer(t)
+
+	bothCtx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{logMetadata[0].Log.Context, logMetadata[1].Log.Context},
+	})
+
+	start := time.Now().Add(-time.Hour)
+	for i, payload := range []string{"a0", "b1", "a2", "b3"} {
+		_, err := s.MessageAppend(bothCtx, &plspb.LogMessageAppendRequest{
+			LogId:     logMetadata[i%2].LogID,
+			Payload:   []byte(payload),
+			Timestamp: timestamppb.New(start.Add(time.Duration(i) * time.Second)),
+		})
+		require.NoError(t, err)
+	}
+
+	list := func(ctx context.Context, in *plspb.LogMessageListRequest) ([]string, []string, error) {
+		stream := &mockListService_ListMessageServer{Ctx: ctx}
+		err := s.MessageList(in, stream)
+
+		var payloads, logIDs []string
+		for _, message := range stream.Messages {
+			payloads = append(payloads, string(message.GetPayload()))
+			logIDs = append(logIDs, message.GetLogId())
+		}
+
+		return payloads, logIDs, err
+	}
+
+	t.Run("log IDs", func(t *testing.T) {
+		payloads, logIDs, err := list(bothCtx, &plspb.LogMessageListRequest{
+			LogId:  logMetadata[1].LogID,
+			LogIds: []string{logMetadata[0].LogID, logMetadata[1].LogID},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a0", "b1", "a2", "b3"}, payloads)
+		assert.Equal(t, []string{
+			logMetadata[0].LogID, logMetadata[1].LogID,
+			logMetadata[0].LogID, logMetadata[1].LogID,
+		}, logIDs)
+
+		payloads, _, err = list(bothCtx, &plspb.LogMessageListRequest{
+			LogIds:  []string{logMetadata[0].LogID, logMetadata[1].LogID},
+			StartAt: timestamppb.New(start.Add(time.Second)),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b1", "a2", "b3"}, payloads)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		payloads, logIDs, err := list(ctx, &plspb.LogMessageListRequest{Context: logMetadata[0].Log.Context})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a0", "a2"}, payloads)
+		assert.Equal(t, []string{logMetadata[0].LogID, logMetadata[0].LogID}, logIDs)
+
+		_, _, err = list(ctx, &plspb.LogMessageListRequest{Context: logMetadata[1].Log.Context})
+		assert.ErrorIs(t, err, auth.ErrPermissionDenied)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, _, err := list(ctx, &plspb.LogMessageListRequest{
+			LogIds: []string{logMetadata[0].LogID, logMetadata[1].LogID},
+		})
+		assert.ErrorIs(t, err, auth.ErrPermissionDenied)
+
+		for _, req := range []*plspb.LogMessageListRequest{
+			{Context: logMetadata[0].Log.Context, LogId: logMetadata[0].LogID},
+			{LogIds: []string{logMetadata[0].LogID, logMetadata[1].LogID}, AfterSequence: 1},
+		} {
+			_, _, err := list(bothCtx, req)
+			assert.ErrorIs(t, err, server.ErrInvalid)
+		}
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		stream := &mockFollowService_MessageListServer{
+			Ctx:      bothCtx,
+			Messages: make(chan *plspb.LogMessageListResponse, 10),
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- s.MessageList(&plspb.LogMessageListRequest{
+				LogIds: []string{logMetadata[0].LogID, logMetadata[1].LogID},
+				Follow: true,
+			}, stream)
+		}()
+
+		received := make(map[string]string)
+		var order []string
+		receive := func(n int) {
+			for i := 0; i < n; i++ {
+				select {
+				case m := <-stream.Messages:
+					received[string(m.GetPayload())] = m.GetLogId()
+					order = append(order, string(m.GetPayload()))
+				case err := <-errCh:
+					require.FailNow(t, "follow ended early", "%+v", err)
+				case <-time.After(5 * time.Second):
+					require.FailNow(t, "timed out waiting for message")
+				}
+			}
+		}
+
+		receive(4)
+
+		// Messages arriving close together are sent by timestamp, not in the
+		// order they were appended.
+		now := time.Now()
+		_, err := s.MessageAppend(bothCtx, &plspb.LogMessageAppendRequest{
+			LogId:     logMetadata[1].LogID,
+			Payload:   []byte("b5"),
+			Timestamp: timestamppb.New(now.Add(time.Second)),
+		})
+		require.NoError(t, err)
+		_, err = s.MessageAppend(bothCtx, &plspb.LogMessageAppendRequest{
+			LogId:     logMetadata[0].LogID,
+			Payload:   []byte("a4"),
+			Timestamp: timestamppb.New(now),
+		})
+		require.NoError(t, err)
+
+		receive(2)
+		assert.Equal(t, logMetadata[0].LogID, received["a4"])
+		assert.Equal(t, logMetadata[1].LogID, received["b5"])
+		assert.Equal(t, []string{"a4", "b5"}, order[len(order)-2:])
+
+		for _, lm := range logMetadata {
+			_, err := s.Seal(bothCtx, &plspb.LogSealRequest{LogId: lm.LogID})
+			require.NoError(t, err)
+		}
+
+		select {
+		case err := <-errCh:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "follow did not end when every log was sealed")
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,6 +15,9 @@ const (
 	QueryColumnPayload QueryColumn = iota
 	QueryColumnTimestamp
 	QueryColumnLogMessageID
+	QueryColumnMediaType
+	QueryColumnSequence
+	QueryColumnLogID
 )
 
 const (
@@ -23,7 +28,9 @@ type BigQueryTableQueryBuilder struct {
 	client *bigquery.Client
 	table  *bigquery.Table
 
-	parameters map[string]bigquery.QueryParameter
+	parameters   map[string]bigquery.QueryParameter
+	descending   bool
+	contextLines int
 }
 
 func (qb *BigQueryTableQueryBuilder) WithClient(client *bigquery.Client) {
@@ -41,6 +48,37 @@ func (qb *BigQueryTableQueryBuilder) WithLog(logID string) {
 	}
 }
 
+// logEncryptionKey pairs a log with its key when querying several logs.
+type logEncryptionKey struct {
+	LogID string `bigquery:"log_id"`
+	Key   string `bigquery:"key"`
+}
+
+// WithLogs restricts the query to several logs, given the encryption key of
+// each, in place of WithLog and WithEncryptionKey.
+func (qb *BigQueryTableQueryBuilder) WithLogs(encryptionKeys map[string]string) {
+	logIDs := make([]string, 0, len(encryptionKeys))
+	for logID := range encryptionKeys {
+		logIDs = append(logIDs, logID)
+	}
+	sort.Strings(logIDs)
+
+	keys := make([]logEncryptionKey, len(logIDs))
+	for i, logID := range logIDs {
+		keys[i] = logEncryptionKey{LogID: logID, Key: encryptionKeys[logID]}
+	}
+
+	qb.parameters["logIDs"] = bigquery.QueryParameter{
+		Name:  "logIDs",
+		Value: logIDs,
+	}
+
+	qb.parameters["encryptionKeys"] = bigquery.QueryParameter{
+		Name:  "encryptionKeys",
+		Value: keys,
+	}
+}
+
 func (qb *BigQueryTableQueryBuilder) WithEncryptionKey(encryptionKey string) {
 	qb.parameters["encryptionKey"] = bigquery.QueryParameter{
 		Name:  "encryptionKey",
@@ -76,18 +114,75 @@ func (qb *BigQueryTableQueryBuilder) Before(before *time.Time) {
 	}
 }
 
+// WithAfterSequence restricts the query to messages after the given
+// sequence. A sequence is an exact position in the log, so it supersedes any
+// timestamp set with After.
+func (qb *BigQueryTableQueryBuilder) WithAfterSequence(afterSequence int64) {
+	delete(qb.parameters, "after")
+
+	qb.parameters["afterSequence"] = bigquery.QueryParameter{
+		Name:  "afterSequence",
+		Value: afterSequence,
+	}
+}
+
+// WithDescending returns the newest messages first.
+func (qb *BigQueryTableQueryBuilder) WithDescending() {
+	qb.descending = true
+}
+
+// WithLimit returns at most the given number of messages.
+func (qb *BigQueryTableQueryBuilder) WithLimit(limit int) {
+	qb.parameters["limit"] = bigquery.QueryParameter{
+		Name:  "limit",
+		Value: limit,
+	}
+}
+
+// WithFilter returns only the messages whose payload, as a string, contains a
+// match of an RE2 expression, along with the given number of messages around
+// each match in the same log.
+func (qb *BigQueryTableQueryBuilder) WithFilter(expr string, contextLines int) {
+	qb.parameters["filter"] = bigquery.QueryParameter{
+		Name:  "filter",
+		Value: expr,
+	}
+
+	qb.contextLines = contextLines
+}
+
 func (qb *BigQueryTableQueryBuilder) Build() (*bigquery.Query, error) {
 	var sb strings.Builder
 
+	_, multiple := qb.parameters["logIDs"]
+	_, filtered := qb.parameters["filter"]
+
+	if filtered {
+		// The filter applies to the decrypted payload, so the messages are
+		// decrypted before they are filtered.
+		sb.WriteString("WITH messages AS (\n")
+	}
+
 	sb.WriteString("SELECT ")
-	sb.WriteString("aead.decrypt_bytes(FROM_BASE64(@encryptionKey), encrypted_payload, b'')")
-	sb.WriteString(", timestamp, log_message_id\n")
+	if multiple {
+		sb.WriteString("aead.decrypt_bytes(FROM_BASE64(k.key), m.encrypted_payload, b'')")
+	} else {
+		sb.WriteString("aead.decrypt_bytes(FROM_BASE64(@encryptionKey), m.encrypted_payload, b'')")
+	}
+	sb.WriteString(" AS payload, m.timestamp, m.log_message_id, m.media_type, m.sequence, m.log_id\n")
 
 	sb.WriteString("FROM `")
 	sb.WriteString(strings.Join([]string{qb.table.ProjectID, qb.table.DatasetID, qb.table.TableID}, "."))
-	sb.WriteString("`\n")
+	sb.WriteString("` AS m\n")
 
-	sb.WriteString("WHERE log_id = @logID\n")
+	if multiple {
+		// The IN filter on the clustering column lets BigQuery prune the
+		// blocks it scans; the join only selects the key for each row.
+		sb.WriteString("JOIN UNNEST(@encryptionKeys) AS k ON k.log_id = m.log_id\n")
+		sb.WriteString("WHERE m.log_id IN UNNEST(@logIDs)\n")
+	} else {
+		sb.WriteString("WHERE m.log_id = @logID\n")
+	}
 
 	if _, ok := qb.parameters["startAt"]; ok {
 		sb.WriteString("AND timestamp >= TIMESTAMP(@startAt)\n")
@@ -105,7 +200,49 @@ func (qb *BigQueryTableQueryBuilder) Build() (*bigquery.Query, error) {
 		sb.WriteString("AND timestamp < TIMESTAMP(@before)\n")
 	}
 
-	sb.WriteString("ORDER BY timestamp\n")
+	if _, ok := qb.parameters["afterSequence"]; ok {
+		sb.WriteString("AND sequence > @afterSequence\n")
+	}
+
+	if filtered {
+		sb.WriteString(")\n")
+		sb.WriteString("SELECT payload, timestamp, log_message_id, media_type, sequence, log_id\n")
+
+		// As in messageFilter, payloads that are not valid UTF-8 never
+		// match. SAFE_CONVERT_BYTES_TO_STRING would instead replace the
+		// invalid bytes, while SAFE_CAST returns NULL for them.
+		match := "(SAFE_CAST(payload AS STRING) IS NOT NULL AND REGEXP_CONTAINS(SAFE_CAST(payload AS STRING), @filter))"
+		if qb.contextLines > 0 {
+			// A message is kept if any message within the context lines of
+			// it in the same log matches.
+			fmt.Fprintf(&sb, "FROM (SELECT *, LOGICAL_OR(%s) OVER ("+
+				"PARTITION BY log_id ORDER BY sequence, timestamp ROWS BETWEEN %d PRECEDING AND %d FOLLOWING"+
+				") AS matched FROM messages)\n", match, qb.contextLines, qb.contextLines)
+			sb.WriteString("WHERE matched\n")
+		} else {
+			sb.WriteString("FROM messages\n")
+			sb.WriteString("WHERE " + match + "\n")
+		}
+	}
+
+	direction := ""
+	if qb.descending {
+		direction = " DESC"
+	}
+
+	if multiple {
+		// Sequences are only comparable within a log, so messages from
+		// several logs are interleaved by time.
+		sb.WriteString("ORDER BY timestamp" + direction + ", sequence" + direction + "\n")
+	} else {
+		// Messages stored before sequences were assigned have none, and sort
+		// first.
+		sb.WriteString("ORDER BY sequence" + direction + ", timestamp" + direction + "\n")
+	}
+
+	if _, ok := qb.parameters["limit"]; ok {
+		sb.WriteString("LIMIT @limit\n")
+	}
 
 	if qb.client != nil {
 		q := qb.client.Query(sb.String())
@@ -120,6 +257,93 @@ func (qb *BigQueryTableQueryBuilder) Build() (*bigquery.Query, error) {
 	return nil, nil
 }
 
+// BuildSequence builds a query for the sequence of a single message of the
+// log.
+func (qb *BigQueryTableQueryBuilder) BuildSequence(logMessageID string) (*bigquery.Query, error) {
+	var sb strings.Builder
+
+	sb.WriteString("SELECT sequence\n")
+
+	sb.WriteString("FROM `")
+	sb.WriteString(strings.Join([]string{qb.table.ProjectID, qb.table.DatasetID, qb.table.TableID}, "."))
+	sb.WriteString("`\n")
+
+	sb.WriteString("WHERE log_id = @logID AND log_message_id = @logMessageID\n")
+
+	if qb.client != nil {
+		q := qb.client.Query(sb.String())
+
+		q.Parameters = append(q.Parameters, qb.parameters["logID"], bigquery.QueryParameter{
+			Name:  "logMessageID",
+			Value: logMessageID,
+		})
+
+		return q, nil
+	}
+
+	return nil, nil
+}
+
+// BuildLastSequences builds a query for the highest sequence stored for each
+// log, regardless of any other restriction.
+func (qb *BigQueryTableQueryBuilder) BuildLastSequences() (*bigquery.Query, error) {
+	var sb strings.Builder
+
+	sb.WriteString("SELECT log_id, MAX(sequence)\n")
+
+	sb.WriteString("FROM `")
+	sb.WriteString(strings.Join([]string{qb.table.ProjectID, qb.table.DatasetID, qb.table.TableID}, "."))
+	sb.WriteString("`\n")
+
+	logIDs, multiple := qb.parameters["logIDs"]
+	if multiple {
+		sb.WriteString("WHERE log_id IN UNNEST(@logIDs)\n")
+	} else {
+		sb.WriteString("WHERE log_id = @logID\n")
+	}
+
+	sb.WriteString("GROUP BY log_id\n")
+
+	if qb.client != nil {
+		q := qb.client.Query(sb.String())
+
+		if multiple {
+			q.Parameters = append(q.Parameters, logIDs)
+		} else {
+			q.Parameters = append(q.Parameters, qb.parameters["logID"])
+		}
+
+		return q, nil
+	}
+
+	return nil, nil
+}
+
+// BuildDelete builds a DML statement that removes every message of the log.
+// It runs at batch priority, as only the MessagePurger waits on it.
+func (qb *BigQueryTableQueryBuilder) BuildDelete() (*bigquery.Query, error) {
+	var sb strings.Builder
+
+	sb.WriteString("DELETE FROM `")
+	sb.WriteString(strings.Join([]string{qb.table.ProjectID, qb.table.DatasetID, qb.table.TableID}, "."))
+	sb.WriteString("`\n")
+
+	sb.WriteString("WHERE log_id = @logID\n")
+
+	if qb.client != nil {
+		q := qb.client.Query(sb.String())
+		q.Priority = bigquery.BatchPriority
+
+		if value, ok := qb.parameters["logID"]; ok {
+			q.Parameters = append(q.Parameters, value)
+		}
+
+		return q, nil
+	}
+
+	return nil, nil
+}
+
 func NewBigQueryTableQueryBuilder() *BigQueryTableQueryBuilder {
 	return &BigQueryTableQueryBuilder{
 		parameters: make(map[string]bigquery.QueryParameter),
@@ -130,15 +354,22 @@ type LogMessage struct {
 	LogID            string
 	LogMessageID     string
 	Timestamp        time.Time
+	MediaType        string
+	Sequence         int64
 	EncryptedPayload []byte
 }
 
+// Save uses the message identifier as the insert ID, so that BigQuery drops
+// rows inserted again by a retry.
+//
 //nolint:gocritic
 func (lm *LogMessage) Save() (map[string]bigquery.Value, string, error) {
 	return map[string]bigquery.Value{
 		"log_id":            lm.LogID,
 		"log_message_id":    lm.LogMessageID,
 		"timestamp":         lm.Timestamp,
+		"media_type":        lm.MediaType,
+		"sequence":          lm.Sequence,
 		"encrypted_payload": lm.EncryptedPayload,
-	}, "", nil
+	}, lm.LogMessageID, nil
 }
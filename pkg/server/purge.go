@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/google/wire"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	messagePurgeLease = "message-purge"
+
+	// messagePurgeDeferral is how long a log whose delete job was rejected is
+	// left before it is tried again. BigQuery flushes the streaming buffer
+	// within about 90 minutes of an insert.
+	messagePurgeDeferral = 30 * time.Minute
+
+	// bigQueryReasonInvalidQuery is the error reason BigQuery gives a DML
+	// statement over rows still in the streaming buffer.
+	bigQueryReasonInvalidQuery = "invalidQuery"
+)
+
+var BigQueryMessagePurgerSet = wire.NewSet(
+	NewMessagePurger,
+	NewBigQueryClient,
+	NewBigQueryTable,
+)
+
+// MessagePurger periodically removes the stored messages of deleted logs
+// from BigQuery. A DML delete fails while any of a log's rows are still in
+// the streaming buffer, so a log is only marked purged once its delete job
+// has completed; until then its purge is deferred and retried on a later
+// pass, while the pass moves on to the next log. Only the replica holding the
+// purge lease runs jobs in any given pass.
+type MessagePurger struct {
+	client             *bigquery.Client
+	table              *bigquery.Table
+	logMetadataManager model.LogMetadataManager
+	leaseManager       model.LeaseManager
+	meter              *metric.Meter
+	interval           time.Duration
+	batchSize          int
+}
+
+// Purge removes the messages of up to the configured batch size of deleted
+// logs, returning the number purged.
+func (mp *MessagePurger) Purge(ctx context.Context) (int, error) {
+	// The lease outlives the interval so that a slow pass, waiting on its
+	// jobs, is not interrupted by another replica.
+	held, err := mp.leaseManager.Acquire(ctx, messagePurgeLease, 2*mp.interval)
+	if err != nil || !held {
+		return 0, err
+	}
+
+	ids, err := mp.logMetadataManager.ListDeleted(ctx, mp.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		err := mp.deleteMessages(ctx, id)
+		mp.countOutcomeMetric(ctx, model.MetricLogDeleteMessages, err)
+		if isRejectedQueryError(err) {
+			if err := mp.logMetadataManager.DeferPurge(ctx, id, time.Now().Add(messagePurgeDeferral)); err != nil {
+				return purged, err
+			}
+
+			continue
+		} else if err != nil {
+			return purged, err
+		}
+
+		if err := mp.logMetadataManager.Purged(ctx, id); err != nil {
+			return purged, err
+		}
+
+		purged++
+	}
+
+	return purged, nil
+}
+
+// deleteMessages runs a job deleting every message of a log and waits for it
+// to complete.
+func (mp *MessagePurger) deleteMessages(ctx context.Context, id string) error {
+	qb := NewBigQueryTableQueryBuilder()
+	qb.WithClient(mp.client)
+	qb.WithTable(mp.table)
+
+	qb.WithLog(id)
+
+	q, err := qb.BuildDelete()
+	if err != nil {
+		return err
+	}
+
+	job, err := q.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return err
+	}
+
+	return status.Err()
+}
+
+func (mp *MessagePurger) run(ctx context.Context) {
+	ticker := time.NewTicker(mp.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := mp.Purge(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to purge messages of deleted logs: %v", err)
+			}
+		}
+	}
+}
+
+func (mp *MessagePurger) countOutcomeMetric(ctx context.Context, name string, err error) {
+	if mp.meter == nil {
+		return
+	}
+
+	outcome := model.MetricValueSuccess
+	if err != nil {
+		outcome = model.MetricValueFailed
+	}
+
+	counter := metric.Must(*mp.meter).NewInt64Counter(name)
+	counter.Add(ctx, 1,
+		attribute.String(model.MetricLabelModule, "message-purger"),
+		attribute.String(model.MetricLabelOutcome, outcome),
+	)
+}
+
+// isRejectedQueryError determines whether BigQuery rejected a DML statement
+// as invalid, which it does when rows the statement affects are still in the
+// streaming buffer. A failed job reports the reason in its status, while a
+// statement rejected up front fails with an API error.
+func isRejectedQueryError(err error) bool {
+	var berr *bigquery.Error
+	if errors.As(err, &berr) {
+		return berr.Reason == bigQueryReasonInvalidQuery
+	}
+
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) || gerr.Code != http.StatusBadRequest {
+		return false
+	}
+
+	for _, item := range gerr.Errors {
+		if item.Reason == bigQueryReasonInvalidQuery {
+			return true
+		}
+	}
+
+	return false
+}
+
+func NewMessagePurger(ctx context.Context, cfg *opt.Config,
+	logMetadataManager model.LogMetadataManager, leaseManager model.LeaseManager,
+	bigQueryClient *bigquery.Client, bigQueryTable *bigquery.Table,
+	meter *metric.Meter) (*MessagePurger, func(), error) {
+	mp := &MessagePurger{
+		client:             bigQueryClient,
+		table:              bigQueryTable,
+		logMetadataManager: logMetadataManager,
+		leaseManager:       leaseManager,
+		meter:              meter,
+		interval:           cfg.MessagePurgeInterval,
+		batchSize:          cfg.MessagePurgeBatchSize,
+	}
+
+	if mp.interval <= 0 {
+		mp.interval = opt.DefaultMessagePurgeInterval
+	}
+
+	if mp.batchSize <= 0 {
+		mp.batchSize = opt.DefaultMessagePurgeBatchSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go mp.run(ctx)
+
+	return mp, cancel, nil
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitIdleTimeout is how long a bucket may go unused before it is
+// discarded. Buckets refill well within this time, so a discarded bucket is
+// indistinguishable from a new one.
+const rateLimitIdleTimeout = time.Minute
+
+type rateLimitBucket struct {
+	messages *rate.Limiter
+	bytes    *rate.Limiter
+	lastUsed time.Time
+}
+
+// RateLimiter applies token bucket limits on the messages and bytes appended
+// per second to each log, context and credential. Because every scope must
+// admit a message, a single busy log cannot use up the quota of its context,
+// and a single context cannot use up the quota of its credential.
+type RateLimiter struct {
+	defaults       map[string]opt.RateLimit
+	overrides      map[string]opt.RateLimit
+	maxPayloadSize int
+
+	mut       sync.Mutex
+	buckets   map[string]*rateLimitBucket
+	lastSweep time.Time
+}
+
+// RateLimitSubject identifies what a message is being appended to and by
+// whom.
+type RateLimitSubject struct {
+	LogID        string
+	Context      string
+	CredentialID string
+}
+
+// Allow admits a message of the given size or returns a RateLimitError
+// describing which limits were exceeded and how long to wait. A rejected
+// message does not consume any quota.
+func (rl *RateLimiter) Allow(subject RateLimitSubject, size int) error {
+	rl.mut.Lock()
+	defer rl.mut.Unlock()
+
+	now := time.Now()
+	rl.sweep(now)
+
+	keys := []struct {
+		scope string
+		id    string
+	}{
+		{opt.RateLimitScopeLog, subject.LogID},
+		{opt.RateLimitScopeContext, subject.Context},
+		{opt.RateLimitScopeCredential, subject.CredentialID},
+	}
+
+	var reservations []*rate.Reservation
+	var rerr *RateLimitError
+
+	reserve := func(key, unit string, l *rate.Limiter, n int) {
+		if l == nil {
+			return
+		}
+
+		r := l.ReserveN(now, n)
+		if !r.OK() {
+			// Only possible if n exceeds the burst, which is never smaller
+			// than the largest payload allowed.
+			rerr = rerr.add(key, fmt.Sprintf("%s per second exceeded", unit), time.Second)
+			return
+		}
+
+		reservations = append(reservations, r)
+
+		if delay := r.DelayFrom(now); delay > 0 {
+			rerr = rerr.add(key, fmt.Sprintf("%s per second exceeded", unit), delay)
+		}
+	}
+
+	for _, k := range keys {
+		if k.id == "" {
+			continue
+		}
+
+		key := k.scope + ":" + k.id
+
+		b := rl.bucket(key, k.scope, now)
+		reserve(key, "messages", b.messages, 1)
+		reserve(key, "bytes", b.bytes, size)
+	}
+
+	if rerr != nil {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+
+		return rerr
+	}
+
+	return nil
+}
+
+func (rl *RateLimiter) bucket(key, scope string, now time.Time) *rateLimitBucket {
+	b, found := rl.buckets[key]
+	if !found {
+		limit, ok := rl.overrides[key]
+		if !ok {
+			limit = rl.defaults[scope]
+		}
+
+		b = &rateLimitBucket{}
+
+		if limit.Messages > 0 {
+			b.messages = rate.NewLimiter(rate.Limit(limit.Messages), burst(limit.Messages, 1))
+		}
+
+		if limit.Bytes > 0 {
+			b.bytes = rate.NewLimiter(rate.Limit(limit.Bytes), burst(limit.Bytes, rl.maxPayloadSize))
+		}
+
+		rl.buckets[key] = b
+	}
+
+	b.lastUsed = now
+
+	return b
+}
+
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rateLimitIdleTimeout {
+		return
+	}
+
+	for key, b := range rl.buckets {
+		if now.Sub(b.lastUsed) >= rateLimitIdleTimeout {
+			delete(rl.buckets, key)
+		}
+	}
+
+	rl.lastSweep = now
+}
+
+// burst allows one second's worth of tokens at once, but never less than the
+// largest single request.
+func burst(limit float64, min int) int {
+	b := int(math.Ceil(limit))
+	if b < min {
+		b = min
+	}
+
+	return b
+}
+
+func NewRateLimiter(cfg *opt.Config) *RateLimiter {
+	return &RateLimiter{
+		defaults: map[string]opt.RateLimit{
+			opt.RateLimitScopeLog:        cfg.RateLimitLog,
+			opt.RateLimitScopeContext:    cfg.RateLimitContext,
+			opt.RateLimitScopeCredential: cfg.RateLimitCredential,
+		},
+		overrides:      cfg.RateLimitOverrides,
+		maxPayloadSize: cfg.MessageMaxPayloadSize,
+		buckets:        make(map[string]*rateLimitBucket),
+	}
+}
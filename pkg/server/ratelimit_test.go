@@ -0,0 +1,79 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRateLimiter(t *testing.T) {
+	cfg := &opt.Config{
+		MessageMaxPayloadSize: 100,
+		RateLimitLog:          opt.RateLimit{Messages: 2},
+		RateLimitContext:      opt.RateLimit{Messages: 3, Bytes: 100},
+		RateLimitOverrides: map[string]opt.RateLimit{
+			"log:quiet": {Messages: 1},
+		},
+	}
+
+	rl := server.NewRateLimiter(cfg)
+
+	noisy := server.RateLimitSubject{LogID: "noisy", Context: "runs/1", CredentialID: "c"}
+	other := server.RateLimitSubject{LogID: "other", Context: "runs/1", CredentialID: "c"}
+
+	require.NoError(t, rl.Allow(noisy, 10))
+	require.NoError(t, rl.Allow(noisy, 10))
+
+	err := rl.Allow(noisy, 10)
+
+	var rerr *server.RateLimitError
+	require.ErrorAs(t, err, &rerr)
+	require.Len(t, rerr.Violations, 1)
+	assert.Equal(t, "log:noisy", rerr.Violations[0].Subject)
+	assert.Greater(t, rerr.RetryDelay, time.Duration(0))
+	assert.LessOrEqual(t, rerr.RetryDelay, 500*time.Millisecond)
+
+	// The rejected message did not use up the context's quota.
+	require.NoError(t, rl.Allow(other, 10))
+
+	// Both the context's message and byte limits are now exhausted.
+	err = rl.Allow(other, 90)
+	require.ErrorAs(t, err, &rerr)
+	assert.Len(t, rerr.Violations, 2)
+
+	quiet := server.RateLimitSubject{LogID: "quiet", Context: "runs/2"}
+	require.NoError(t, rl.Allow(quiet, 10))
+	assert.Error(t, rl.Allow(quiet, 10))
+
+	s := server.Status(err)
+	assert.Equal(t, codes.ResourceExhausted, s.Code())
+	require.Len(t, s.Details(), 2)
+
+	qf, ok := s.Details()[0].(*errdetails.QuotaFailure)
+	require.True(t, ok)
+	assert.Len(t, qf.GetViolations(), 2)
+
+	ri, ok := s.Details()[1].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Equal(t, rerr.RetryDelay, ri.GetRetryDelay().AsDuration())
+}
+
+func TestParseRateLimitOverrides(t *testing.T) {
+	overrides, err := opt.ParseRateLimitOverrides([]string{"context:runs/1=50/524288", "credential:abc=0/1024"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]opt.RateLimit{
+		"context:runs/1": {Messages: 50, Bytes: 524288},
+		"credential:abc": {Bytes: 1024},
+	}, overrides)
+
+	for _, value := range []string{"context:runs/1", "runs/1=1/1", "step:a=1/1", "log:a=1", "log:a=x/1", "log:a=1/-1"} {
+		_, err := opt.ParseRateLimitOverrides([]string{value})
+		assert.Error(t, err, value)
+	}
+}
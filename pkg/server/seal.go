@@ -0,0 +1,48 @@
+package server
+
+import (
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// requestedSeal returns the seal described by a request, completed at the
+// time of the request unless the client says otherwise.
+func requestedSeal(in *plspb.LogSealRequest) *model.LogSeal {
+	seal := &model.LogSeal{
+		SealedAt: time.Now(),
+		ExitCode: in.ExitCode,
+	}
+
+	if in.GetSealedAt() != nil {
+		seal.SealedAt = in.GetSealedAt().AsTime()
+	}
+
+	return seal
+}
+
+func sealResponse(logID string, seal *model.LogSeal) *plspb.LogSealResponse {
+	return &plspb.LogSealResponse{
+		LogId:        logID,
+		SealedAt:     timestamppb.New(seal.SealedAt),
+		LastSequence: seal.LastSequence,
+	}
+}
+
+func logListResponse(lm *model.LogMetadata) *plspb.LogListResponse {
+	resp := &plspb.LogListResponse{
+		LogId:   lm.LogID,
+		Context: lm.Log.Context,
+		Name:    lm.Log.Name,
+	}
+
+	if lm.Seal != nil {
+		resp.Sealed = true
+		resp.SealedAt = timestamppb.New(lm.Seal.SealedAt)
+		resp.ExitCode = lm.Seal.ExitCode
+	}
+
+	return resp
+}
@@ -0,0 +1,222 @@
+package server_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/puppetlabs/relay-pls/pkg/test/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestInMemoryServerSeal(t *testing.T) {
+	s, ctx, logMetadata := newBatchTestServer(t)
+
+	logID := logMetadata[0].LogID
+
+	_, err := s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: logID, Payload: []byte("first")})
+	require.NoError(t, err)
+
+	stream := &mockFollowService_MessageListServer{
+		Ctx:      ctx,
+		Messages: make(chan *plspb.LogMessageListResponse, 10),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.MessageList(&plspb.LogMessageListRequest{LogId: logID, Follow: true}, stream)
+	}()
+
+	select {
+	case m := <-stream.Messages:
+		assert.Equal(t, []byte("first"), m.GetPayload())
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for message")
+	}
+
+	_, err = s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: logID, Payload: []byte("last")})
+	require.NoError(t, err)
+
+	readOnlyCtx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{logMetadata[0].Log.Context},
+		Scopes:   []string{model.ScopeLogRead},
+	})
+
+	_, err = s.Seal(readOnlyCtx, &plspb.LogSealRequest{LogId: logID})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	exitCode := int32(3)
+	sealedAt := time.Now().Add(-time.Minute).UTC()
+
+	sealResponse, err := s.Seal(ctx, &plspb.LogSealRequest{
+		LogId:    logID,
+		ExitCode: &exitCode,
+		SealedAt: timestamppb.New(sealedAt),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, logID, sealResponse.GetLogId())
+	assert.True(t, sealedAt.Equal(sealResponse.GetSealedAt().AsTime()))
+
+	// The follower drains the remaining messages and ends.
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "follow did not end when the log was sealed")
+	}
+
+	require.Len(t, stream.Messages, 1)
+	assert.Equal(t, []byte("last"), (<-stream.Messages).GetPayload())
+
+	_, err = s.Seal(ctx, &plspb.LogSealRequest{LogId: logID})
+	assert.Equal(t, codes.FailedPrecondition, server.Status(err).Code())
+
+	_, err = s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: logID, Payload: []byte("late")})
+	assert.Equal(t, codes.FailedPrecondition, server.Status(err).Code())
+
+	batch, err := s.MessageAppendBatch(ctx, &plspb.LogMessageAppendBatchRequest{
+		Messages: []*plspb.LogMessageAppendRequest{
+			{LogId: logID, Payload: []byte("late")},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(codes.FailedPrecondition), batch.GetResults()[0].GetErrorCode())
+
+	// Following a sealed log only returns what it has.
+	listStream := &mockListService_ListMessageServer{Ctx: ctx}
+	require.NoError(t, s.MessageList(&plspb.LogMessageListRequest{LogId: logID, Follow: true}, listStream))
+	assert.Len(t, listStream.Messages, 2)
+
+	logStream := &mockListService_ListServer{Ctx: ctx}
+	require.NoError(t, s.List(&plspb.LogListRequest{}, logStream))
+	require.Len(t, logStream.Logs, 1)
+	assert.True(t, logStream.Logs[0].GetSealed())
+	assert.True(t, sealedAt.Equal(logStream.Logs[0].GetSealedAt().AsTime()))
+	require.NotNil(t, logStream.Logs[0].ExitCode)
+	assert.Equal(t, exitCode, logStream.Logs[0].GetExitCode())
+}
+
+func TestInMemoryServerSealDrain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	cfg, err := opt.NewConfig()
+	require.NoError(t, err)
+
+	km := manager.NewKeyManager()
+	lmm := mock.NewMockLogMetadataManager(ctrl)
+
+	mediaTypes, err := server.NewMediaTypeRegistry(cfg)
+	require.NoError(t, err)
+
+	s := server.NewInMemoryServer(cfg, km, lmm, mediaTypes, server.NewRateLimiter(cfg), server.NewIdempotencyCache(cfg))
+
+	log := &model.Log{Context: uuid.New().String(), Name: "stdout"}
+
+	ctx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{log.Context},
+	})
+
+	logMetadata, err := createLogMetadata(ctx, []*model.Log{log}, km)
+	require.NoError(t, err)
+
+	lm := logMetadata[0]
+
+	var mut sync.Mutex
+	next := int64(1)
+	sealOnReserve := false
+
+	lmm.EXPECT().Get(gomock.Any(), gomock.Eq(lm.LogID)).DoAndReturn(
+		func(ctx context.Context, id string) (*model.LogMetadata, error) {
+			mut.Lock()
+			defer mut.Unlock()
+
+			c := *lm
+			return &c, nil
+		}).AnyTimes()
+	lmm.EXPECT().Seal(gomock.Any(), gomock.Eq(lm.LogID), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, id string, seal *model.LogSeal) (*model.LogSeal, error) {
+			mut.Lock()
+			defer mut.Unlock()
+
+			sealed := *seal
+			sealed.LastSequence = next - 1
+
+			lm.Seal = &sealed
+			return &sealed, nil
+		}).AnyTimes()
+	lmm.EXPECT().ReserveSequence(gomock.Any(), gomock.Eq(lm.LogID), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, id string, n int) (int64, error) {
+			mut.Lock()
+			first := next
+			next += int64(n)
+			seal := sealOnReserve
+			mut.Unlock()
+
+			// Seal the log after the sequence is reserved but before the
+			// message is stored, giving the follower time to catch up on
+			// the messages stored so far.
+			if seal {
+				_, err := s.Seal(ctx, &plspb.LogSealRequest{LogId: id})
+				require.NoError(t, err)
+
+				time.Sleep(100 * time.Millisecond)
+			}
+
+			return first, nil
+		}).AnyTimes()
+
+	_, err = s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: lm.LogID, Payload: []byte("first")})
+	require.NoError(t, err)
+
+	stream := &mockFollowService_MessageListServer{
+		Ctx:      ctx,
+		Messages: make(chan *plspb.LogMessageListResponse, 10),
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.MessageList(&plspb.LogMessageListRequest{LogId: lm.LogID, Follow: true}, stream)
+	}()
+
+	select {
+	case m := <-stream.Messages:
+		assert.Equal(t, []byte("first"), m.GetPayload())
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out waiting for message")
+	}
+
+	mut.Lock()
+	sealOnReserve = true
+	mut.Unlock()
+
+	_, err = s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: lm.LogID, Payload: []byte("racing")})
+	require.NoError(t, err)
+
+	// The follower keeps catching up until it has the last sequence of the
+	// seal.
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "follow did not end when the log was sealed")
+	}
+
+	require.Len(t, stream.Messages, 1)
+	m := <-stream.Messages
+	assert.Equal(t, []byte("racing"), m.GetPayload())
+	assert.Equal(t, int64(2), m.GetSequence())
+}
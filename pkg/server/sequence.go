@@ -0,0 +1,37 @@
+package server
+
+import (
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+)
+
+// messageSequenceLookup finds the sequence of a message in the log being
+// listed, reporting whether the message exists.
+type messageSequenceLookup func(logMessageID string) (int64, bool, error)
+
+// listAfterSequence resolves the resume point of a list request to the
+// sequence after which messages are returned, or 0 to start from the
+// beginning of the log.
+func listAfterSequence(in *plspb.LogMessageListRequest, lookup messageSequenceLookup) (int64, error) {
+	if in.GetAfterSequence() < 0 {
+		return 0, NewFieldError("after_sequence", "must not be negative")
+	}
+
+	if in.GetAfterMessageId() == "" {
+		return in.GetAfterSequence(), nil
+	}
+
+	if in.GetAfterSequence() != 0 {
+		return 0, NewFieldError("after_message_id", "cannot be combined with after_sequence")
+	}
+
+	sequence, found, err := lookup(in.GetAfterMessageId())
+	if err != nil {
+		return 0, err
+	} else if !found {
+		return 0, NewFieldError("after_message_id", "does not identify a message in this log")
+	} else if sequence == 0 {
+		return 0, NewFieldError("after_message_id", "identifies a message appended before sequences were assigned")
+	}
+
+	return sequence, nil
+}
@@ -2,13 +2,15 @@ package server
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"cloud.google.com/go/bigquery"
-	"github.com/google/uuid"
 	"github.com/google/wire"
 	"github.com/puppetlabs/leg/timeutil/pkg/retry"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
 	"github.com/puppetlabs/relay-pls/pkg/model"
 	"github.com/puppetlabs/relay-pls/pkg/opt"
 	"github.com/puppetlabs/relay-pls/pkg/plspb"
@@ -21,6 +23,9 @@ import (
 
 var BigQueryServerSet = wire.NewSet(
 	NewBigQueryServer,
+	NewMediaTypeRegistry,
+	NewRateLimiter,
+	NewIdempotencyCache,
 	NewBigQueryClient,
 	NewBigQueryTable,
 )
@@ -31,19 +36,35 @@ type BigQueryServer struct {
 	table              *bigquery.Table
 	logMetadataManager model.LogMetadataManager
 	keyManager         model.KeyManager
+	mediaTypes         *MediaTypeRegistry
+	rateLimiter        *RateLimiter
+	idempotency        *IdempotencyCache
+	follows            *followHub
+	systemLogs         *systemLogWriter
 	meter              *metric.Meter
 }
 
 func (s *BigQueryServer) Create(ctx context.Context, in *plspb.LogCreateRequest) (*plspb.LogCreateResponse, error) {
-	if in.GetContext() == "" || in.GetName() == "" {
-		return nil, ErrInvalid
+	if in.GetName() == "" {
+		return nil, NewFieldError("name", "must not be empty")
 	}
 
+	if err := auth.RequireScope(ctx, model.ScopeLogCreate); err != nil {
+		return nil, err
+	}
+
+	logContext, err := auth.ResolveContext(ctx, model.ScopeLogCreate, in.GetContext())
+	if err != nil {
+		return nil, err
+	}
+
+	credential, _ := auth.CredentialFromContext(ctx)
+
 	lm, err := s.logMetadataManager.Create(ctx,
 		&model.Log{
-			Context: in.Context,
-			Name:    in.Name,
-		})
+			Context: logContext,
+			Name:    in.GetName(),
+		}, credential.ID)
 	s.countOutcomeMetric(ctx, model.MetricLogCreateMetadata, err)
 	if err != nil {
 		return nil, err
@@ -55,13 +76,80 @@ func (s *BigQueryServer) Create(ctx context.Context, in *plspb.LogCreateRequest)
 }
 
 func (s *BigQueryServer) Delete(ctx context.Context, in *plspb.LogDeleteRequest) (*plspb.LogDeleteResponse, error) {
-	return nil, nil
+	lm, err := s.logMetadataManager.Get(ctx, in.GetLogId())
+	s.countOutcomeMetric(ctx, model.MetricLogGetMetadata, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auth.Authorize(ctx, model.ScopeLogDelete, lm.Log); err != nil {
+		return nil, err
+	}
+
+	// Once the key is gone, the stored messages can no longer be decrypted,
+	// so the log is effectively deleted before its rows are removed.
+	err = s.logMetadataManager.Delete(ctx, in.GetLogId())
+	s.countOutcomeMetric(ctx, model.MetricLogDeleteMetadata, err)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stored messages are removed later by the MessagePurger, which
+	// retries until none of them remain in the streaming buffer.
+	s.follows.end(in.GetLogId(), manager.ErrLogNotFound)
+
+	return &plspb.LogDeleteResponse{}, nil
 }
 
 func (s *BigQueryServer) List(in *plspb.LogListRequest, stream plspb.Log_ListServer) error {
+	ctx := stream.Context()
+
+	if err := auth.RequireScope(ctx, model.ScopeLogRead); err != nil {
+		return err
+	}
+
+	match, err := auth.ListMatcher(ctx, in.GetContexts())
+	if err != nil {
+		return err
+	}
+
+	lms, err := s.logMetadataManager.List(ctx, auth.ListPrefixes(ctx, in.GetContexts()), match)
+	s.countOutcomeMetric(ctx, model.MetricLogListMetadata, err)
+	if err != nil {
+		return err
+	}
+
+	for _, lm := range lms {
+		if err := stream.Send(logListResponse(lm)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+func (s *BigQueryServer) Seal(ctx context.Context, in *plspb.LogSealRequest) (*plspb.LogSealResponse, error) {
+	lm, err := s.logMetadataManager.Get(ctx, in.GetLogId())
+	s.countOutcomeMetric(ctx, model.MetricLogGetMetadata, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := auth.Authorize(ctx, model.ScopeLogAppend, lm.Log); err != nil {
+		return nil, err
+	}
+
+	seal, err := s.logMetadataManager.Seal(ctx, in.GetLogId(), requestedSeal(in))
+	s.countOutcomeMetric(ctx, model.MetricLogSeal, err)
+	if err != nil {
+		return nil, err
+	}
+
+	s.follows.end(in.GetLogId(), errFollowSealed)
+
+	return sealResponse(in.GetLogId(), seal), nil
+}
+
 func (s *BigQueryServer) MessageAppend(ctx context.Context, in *plspb.LogMessageAppendRequest) (*plspb.LogMessageAppendResponse, error) {
 	lmm, err := s.logMetadataManager.Get(ctx, in.GetLogId())
 	s.countOutcomeMetric(ctx, model.MetricLogGetMetadata, err)
@@ -69,12 +157,56 @@ func (s *BigQueryServer) MessageAppend(ctx context.Context, in *plspb.LogMessage
 		return nil, err
 	}
 
+	if err := auth.Authorize(ctx, model.ScopeLogAppend, lmm.Log); err != nil {
+		return nil, err
+	}
+
+	// A concurrent seal is caught when the sequence is reserved.
+	if lmm.Seal != nil {
+		return nil, manager.ErrLogSealed
+	}
+
+	claim, previous, err := s.idempotency.claim(in.GetLogId(), in.GetIdempotencyKey())
+	if err != nil {
+		return nil, err
+	} else if previous != nil {
+		return &plspb.LogMessageAppendResponse{
+			LogId:        in.GetLogId(),
+			LogMessageId: previous.logMessageID,
+			Sequence:     previous.sequence,
+		}, nil
+	}
+	defer claim.Release()
+
+	mediaType, err := s.mediaTypes.Validate(in.GetMediaType(), in.GetPayload())
+	if err != nil {
+		return nil, err
+	}
+
+	credential, _ := auth.CredentialFromContext(ctx)
+
+	err = s.rateLimiter.Allow(RateLimitSubject{
+		LogID:        in.GetLogId(),
+		Context:      lmm.Log.Context,
+		CredentialID: credential.ID,
+	}, len(in.GetPayload()))
+	s.countOutcomeMetric(ctx, model.MetricLogRateLimit, err)
+	if err != nil {
+		return nil, err
+	}
+
 	ct, err := s.keyManager.Encrypt(ctx, lmm.Key, in.GetPayload())
 	s.countOutcomeMetric(ctx, model.MetricLogEncryptMessage, err)
 	if err != nil {
 		return nil, err
 	}
 
+	sequence, err := s.logMetadataManager.ReserveSequence(ctx, in.GetLogId(), 1)
+	s.countOutcomeMetric(ctx, model.MetricLogReserveSequence, err)
+	if err != nil {
+		return nil, err
+	}
+
 	logs := make([]*LogMessage, 0)
 
 	ts := time.Now()
@@ -84,49 +216,93 @@ func (s *BigQueryServer) MessageAppend(ctx context.Context, in *plspb.LogMessage
 
 	message := &LogMessage{
 		LogID:            in.GetLogId(),
-		LogMessageID:     uuid.New().String(),
+		LogMessageID:     newLogMessageID(in.GetLogId(), in.GetIdempotencyKey()),
 		Timestamp:        ts,
+		MediaType:        mediaType,
+		Sequence:         sequence,
 		EncryptedPayload: ct,
 	}
 
 	logs = append(logs, message)
 
-	inserter := s.table.Inserter()
-
-	err = retry.Wait(ctx, func(ctx context.Context) (bool, error) {
-		ierr := inserter.Put(ctx, logs)
-		if ierr != nil {
-			return false, ierr
-		}
-
-		return true, nil
-	})
-	s.countOutcomeMetric(ctx, model.MetricLogInsertMessage, err)
+	rowErrs, err := s.insertMessages(ctx, logs)
+	if err == nil {
+		err = rowErrs[0]
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	claim.Complete(&idempotencyRecord{
+		logMessageID: message.LogMessageID,
+		sequence:     message.Sequence,
+	})
+
+	s.follows.publishAppended(logs, [][]byte{in.GetPayload()})
+
 	return &plspb.LogMessageAppendResponse{
 		LogId:        message.LogID,
 		LogMessageId: message.LogMessageID,
+		Sequence:     message.Sequence,
 	}, nil
 }
 
+func (s *BigQueryServer) MessageAppendBatch(ctx context.Context, in *plspb.LogMessageAppendBatchRequest) (*plspb.LogMessageAppendBatchResponse, error) {
+	if err := auth.RequireScope(ctx, model.ScopeLogAppend); err != nil {
+		return nil, err
+	}
+
+	b := s.newMessageBatch()
+	for _, message := range in.GetMessages() {
+		b.Add(ctx, message)
+	}
+	b.Flush(ctx)
+
+	return b.Response(), nil
+}
+
+func (s *BigQueryServer) MessageAppendStream(stream plspb.Log_MessageAppendStreamServer) error {
+	if err := auth.RequireScope(stream.Context(), model.ScopeLogAppend); err != nil {
+		return err
+	}
+
+	return appendStream(stream, s.newMessageBatch())
+}
+
 func (s *BigQueryServer) MessageList(in *plspb.LogMessageListRequest, stream plspb.Log_MessageListServer) error {
-	ctx := context.Background()
+	ctx := stream.Context()
 
-	lm, err := s.logMetadataManager.Get(ctx, in.GetLogId())
-	s.countOutcomeMetric(ctx, model.MetricLogGetMetadata, err)
+	window, err := newListWindow(in)
+	if err != nil {
+		return err
+	}
+
+	filter, err := newMessageFilter(in)
 	if err != nil {
 		return err
 	}
 
+	lms, err := listLogs(ctx, in, s.logMetadataManager)
+	s.countOutcomeMetric(ctx, model.MetricLogGetMetadata, err)
+	if err != nil || len(lms) == 0 {
+		return err
+	}
+
 	qb := NewBigQueryTableQueryBuilder()
 	qb.WithClient(s.client)
 	qb.WithTable(s.table)
 
-	qb.WithLog(in.GetLogId())
-	qb.WithEncryptionKey(lm.Key)
+	if len(lms) == 1 {
+		qb.WithLog(lms[0].LogID)
+		qb.WithEncryptionKey(lms[0].Key)
+	} else {
+		encryptionKeys := make(map[string]string, len(lms))
+		for _, lm := range lms {
+			encryptionKeys[lm.LogID] = lm.Key
+		}
+
+		qb.WithLogs(encryptionKeys)
+	}
 
 	if in.GetStartAt() != nil {
 		startAt := in.GetStartAt().AsTime()
@@ -138,68 +314,322 @@ func (s *BigQueryServer) MessageList(in *plspb.LogMessageListRequest, stream pls
 		qb.WithEndAt(&endAt)
 	}
 
+	afterSequence, err := listAfterSequence(in, func(logMessageID string) (int64, bool, error) {
+		return s.messageSequence(ctx, qb, logMessageID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if afterSequence > 0 {
+		qb.WithAfterSequence(afterSequence)
+	}
+
+	if filter != nil {
+		qb.WithFilter(filter.expr, filter.contextLines)
+	}
+
+	// The tail is read newest first, and sent in ascending order once it is
+	// complete.
+	if window.descending || window.tail > 0 {
+		qb.WithDescending()
+	}
+
+	if window.tail > 0 {
+		qb.WithLimit(window.tail)
+	} else if window.limit > 0 {
+		qb.WithLimit(window.limit)
+	}
+
+	send := func(message *plspb.LogMessageListResponse) error {
+		err := retry.Wait(ctx, func(ctx context.Context) (bool, error) {
+			if serr := stream.Send(message); serr != nil {
+				return false, serr
+			}
+
+			return true, nil
+		})
+		s.countOutcomeMetric(ctx, model.MetricLogStreamMessage, err)
+
+		return err
+	}
+
+	after := make(map[string]int64, len(lms))
+	for _, lm := range lms {
+		after[lm.LogID] = afterSequence
+	}
+
+	// Following a tail resumes after every message already stored, not just
+	// those in the tail.
+	if window.tail > 0 && in.GetFollow() {
+		if err := s.lastSequences(ctx, qb, after); err != nil {
+			return err
+		}
+	}
+
+	var tail []*plspb.LogMessageListResponse
+	err = s.queryMessages(ctx, qb, func(message *plspb.LogMessageListResponse) error {
+		if message.GetSequence() > after[message.GetLogId()] {
+			after[message.GetLogId()] = message.GetSequence()
+		}
+
+		if window.tail > 0 {
+			tail = append(tail, message)
+			return nil
+		}
+
+		return send(message)
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := len(tail) - 1; i >= 0; i-- {
+		if err := send(tail[i]); err != nil {
+			return err
+		}
+	}
+
+	if !in.GetFollow() {
+		return nil
+	}
+
+	var reqs []followRequest
+	for _, lm := range lms {
+		if lm.Seal != nil && after[lm.LogID] >= lm.Seal.LastSequence {
+			continue
+		}
+
+		lm := lm
+		reqs = append(reqs, followRequest{
+			logID:         lm.LogID,
+			key:           lm.Key,
+			afterSequence: after[lm.LogID],
+			catchUp: func(afterSequence int64) ([]*plspb.LogMessageListResponse, error) {
+				return s.readMessages(ctx, lm.LogID, lm.Key, afterSequence)
+			},
+			seal: func() (*model.LogSeal, error) {
+				return s.seal(ctx, lm.LogID)
+			},
+			match: listMatcher(in, filter),
+			send:  send,
+		})
+	}
+
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	return followLogs(ctx, s.follows, reqs)
+}
+
+// queryMessages runs a message query, calling fn with each message in turn.
+func (s *BigQueryServer) queryMessages(ctx context.Context, qb *BigQueryTableQueryBuilder, fn func(message *plspb.LogMessageListResponse) error) error {
+	q, err := qb.Build()
+	if err != nil {
+		return err
+	}
+
+	it, err := q.Read(ctx)
+	if err != nil {
+		return err
+	}
+
 	for {
-		q, err := qb.Build()
+		var values []bigquery.Value
+		err := it.Next(&values)
+
+		if err == iterator.Done {
+			break
+		}
 		if err != nil {
 			return err
 		}
 
-		it, err := q.Read(ctx)
-		if err != nil {
+		message := &plspb.LogMessageListResponse{}
+
+		if payload, ok := values[QueryColumnPayload].([]byte); ok {
+			message.Payload = payload
+		}
+
+		if ts, ok := values[QueryColumnTimestamp].(time.Time); ok {
+			message.Timestamp = timestamppb.New(ts)
+		}
+
+		if logMessageID, ok := values[QueryColumnLogMessageID].(string); ok {
+			message.LogMessageId = logMessageID
+		}
+
+		// Messages appended before media types were stored have none.
+		message.MediaType = MediaTypeOctetStream
+		if mediaType, ok := values[QueryColumnMediaType].(string); ok && mediaType != "" {
+			message.MediaType = mediaType
+		}
+
+		if sequence, ok := values[QueryColumnSequence].(int64); ok {
+			message.Sequence = sequence
+		}
+
+		if logID, ok := values[QueryColumnLogID].(string); ok {
+			message.LogId = logID
+		}
+
+		if err := fn(message); err != nil {
 			return err
 		}
+	}
 
-		ts := time.Now().UTC()
-		for {
-			if it == nil {
-				break
-			}
+	return nil
+}
 
-			var values []bigquery.Value
-			err := it.Next(&values)
+// pollMessages checks whether a log is sealed, then reads its messages
+// stored after a sequence for its followers.
+func (s *BigQueryServer) pollMessages(ctx context.Context, logID, key string, afterSequence int64) ([]*plspb.LogMessageListResponse, bool, error) {
+	seal, err := s.seal(ctx, logID)
+	if err != nil {
+		return nil, false, err
+	}
 
-			if err == iterator.Done {
-				break
-			}
-			if err != nil {
-				continue
-			}
+	messages, err := s.readMessages(ctx, logID, key, afterSequence)
+	s.countOutcomeMetric(ctx, model.MetricLogPollMessages, err)
 
-			message := &plspb.LogMessageListResponse{}
+	return messages, seal != nil, err
+}
 
-			if payload, ok := values[QueryColumnPayload].([]byte); ok {
-				message.Payload = payload
-			}
+// readMessages reads the messages of a log stored after a sequence.
+func (s *BigQueryServer) readMessages(ctx context.Context, logID, key string, afterSequence int64) ([]*plspb.LogMessageListResponse, error) {
+	qb := NewBigQueryTableQueryBuilder()
+	qb.WithClient(s.client)
+	qb.WithTable(s.table)
 
-			ts, ok := values[QueryColumnTimestamp].(time.Time)
-			if ok {
-				message.Timestamp = timestamppb.New(ts)
-			}
+	qb.WithLog(logID)
+	qb.WithEncryptionKey(key)
+	qb.WithAfterSequence(afterSequence)
 
-			if logMessageID, ok := values[QueryColumnLogMessageID].(string); ok {
-				message.LogMessageId = logMessageID
-			}
+	var messages []*plspb.LogMessageListResponse
+	err := s.queryMessages(ctx, qb, func(message *plspb.LogMessageListResponse) error {
+		messages = append(messages, message)
+		return nil
+	})
 
-			err = retry.Wait(ctx, func(ctx context.Context) (bool, error) {
-				if serr := stream.Send(message); serr != nil {
-					return false, serr
-				}
+	return messages, err
+}
 
-				return true, nil
-			})
-			s.countOutcomeMetric(ctx, model.MetricLogStreamMessage, err)
-		}
+// seal returns the seal of a log, or nil if it is not sealed.
+func (s *BigQueryServer) seal(ctx context.Context, logID string) (*model.LogSeal, error) {
+	lm, err := s.logMetadataManager.Get(ctx, logID)
+	s.countOutcomeMetric(ctx, model.MetricLogGetMetadata, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return lm.Seal, nil
+}
+
+// lastSequences raises the sequence after which each log being queried is
+// followed to the highest sequence stored for it.
+func (s *BigQueryServer) lastSequences(ctx context.Context, qb *BigQueryTableQueryBuilder, after map[string]int64) error {
+	q, err := qb.BuildLastSequences()
+	if err != nil {
+		return err
+	}
+
+	it, err := q.Read(ctx)
+	if err != nil {
+		return err
+	}
 
-		if !in.GetFollow() {
+	for {
+		var values []bigquery.Value
+		err := it.Next(&values)
+
+		if err == iterator.Done {
 			break
 		}
+		if err != nil {
+			return err
+		}
 
-		qb.After(&ts)
+		logID, _ := values[0].(string)
+		sequence, _ := values[1].(int64)
+
+		if sequence > after[logID] {
+			after[logID] = sequence
+		}
 	}
 
 	return nil
 }
 
+// messageSequence looks up the sequence of a message of the log being
+// queried.
+func (s *BigQueryServer) messageSequence(ctx context.Context, qb *BigQueryTableQueryBuilder, logMessageID string) (int64, bool, error) {
+	q, err := qb.BuildSequence(logMessageID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	it, err := q.Read(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var values []bigquery.Value
+	if err := it.Next(&values); err == iterator.Done {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	sequence, _ := values[0].(int64)
+
+	return sequence, true, nil
+}
+
+// insertMessages streams rows into the table. Errors that apply to the whole
+// request are retried; errors for individual rows are not, as retrying would
+// duplicate the rows that were inserted.
+func (s *BigQueryServer) insertMessages(ctx context.Context, messages []*LogMessage) (map[int]error, error) {
+	inserter := s.table.Inserter()
+
+	var rowErrs map[int]error
+	err := retry.Wait(ctx, func(ctx context.Context) (bool, error) {
+		ierr := inserter.Put(ctx, messages)
+
+		var perr bigquery.PutMultiError
+		if errors.As(ierr, &perr) {
+			rowErrs = make(map[int]error, len(perr))
+			for _, rerr := range perr {
+				rowErrs[rerr.RowIndex] = rerr.Errors
+			}
+
+			return true, nil
+		} else if ierr != nil {
+			return false, ierr
+		}
+
+		return true, nil
+	})
+
+	metricErr := err
+	for _, rerr := range rowErrs {
+		metricErr = rerr
+		break
+	}
+	s.countOutcomeMetric(ctx, model.MetricLogInsertMessage, metricErr)
+
+	return rowErrs, err
+}
+
+// WriteSystemLog appends messages to a log kept by the service itself.
+func (s *BigQueryServer) WriteSystemLog(ctx context.Context, name string, messages []*model.SystemLogMessage) error {
+	return s.systemLogs.write(ctx, name, messages)
+}
+
+func (s *BigQueryServer) newMessageBatch() *messageBatch {
+	return newMessageBatch(s.logMetadataManager, s.keyManager, s.mediaTypes, s.rateLimiter, s.idempotency, s.follows, s.insertMessages)
+}
+
 func (s *BigQueryServer) countOutcomeMetric(ctx context.Context, name string, err error) {
 	attrs := []attribute.KeyValue{
 		attribute.String(model.MetricLabelOutcome, model.MetricValueSuccess),
@@ -229,6 +659,7 @@ func (s *BigQueryServer) countMetric(ctx context.Context, name string, additiona
 
 func NewBigQueryServer(cfg *opt.Config,
 	keyManager model.KeyManager, logMetadataManager model.LogMetadataManager,
+	mediaTypes *MediaTypeRegistry, rateLimiter *RateLimiter, idempotency *IdempotencyCache,
 	bigQueryClient *bigquery.Client, bigQueryTable *bigquery.Table,
 	meter *metric.Meter) plspb.LogServer {
 
@@ -238,8 +669,13 @@ func NewBigQueryServer(cfg *opt.Config,
 
 		keyManager:         keyManager,
 		logMetadataManager: logMetadataManager,
+		mediaTypes:         mediaTypes,
+		rateLimiter:        rateLimiter,
+		idempotency:        idempotency,
 		meter:              meter,
 	}
+	s.follows = newFollowHub(s.pollMessages)
+	s.systemLogs = newSystemLogWriter(logMetadataManager, keyManager, s.follows, s.insertMessages)
 
 	return s
 }
@@ -250,6 +686,8 @@ func NewBigQueryTable(ctx context.Context, cfg *opt.Config, client *bigquery.Cli
 		{Name: "log_message_id", Type: bigquery.StringFieldType, Required: true},
 		{Name: "timestamp", Type: bigquery.TimestampFieldType, Required: true},
 		{Name: "encrypted_payload", Type: bigquery.BytesFieldType},
+		{Name: "media_type", Type: bigquery.StringFieldType},
+		{Name: "sequence", Type: bigquery.IntegerFieldType},
 	}
 
 	metadata := &bigquery.TableMetadata{
@@ -266,11 +704,44 @@ func NewBigQueryTable(ctx context.Context, cfg *opt.Config, client *bigquery.Cli
 	err := table.Create(ctx, metadata)
 	if e, ok := err.(*googleapi.Error); ok && e.Code != http.StatusConflict {
 		return nil, err
+	} else if ok {
+		if err := updateBigQueryTableSchema(ctx, table, schema); err != nil {
+			return nil, err
+		}
 	}
 
 	return table, nil
 }
 
+// updateBigQueryTableSchema adds any columns missing from an existing table.
+// New columns are always nullable, so rows written before they were added
+// remain valid.
+func updateBigQueryTableSchema(ctx context.Context, table *bigquery.Table, schema bigquery.Schema) error {
+	metadata, err := table.Metadata(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]struct{}, len(metadata.Schema))
+	for _, field := range metadata.Schema {
+		existing[field.Name] = struct{}{}
+	}
+
+	update := metadata.Schema
+	for _, field := range schema {
+		if _, found := existing[field.Name]; !found {
+			update = append(update, field)
+		}
+	}
+
+	if len(update) == len(metadata.Schema) {
+		return nil
+	}
+
+	_, err = table.Update(ctx, bigquery.TableMetadataToUpdate{Schema: update}, metadata.ETag)
+	return err
+}
+
 func NewBigQueryClient(ctx context.Context, cfg *opt.Config) (*bigquery.Client, error) {
 	return bigquery.NewClient(ctx, cfg.Project)
 }
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
 	"github.com/puppetlabs/relay-pls/pkg/manager"
 	"github.com/puppetlabs/relay-pls/pkg/model"
 	"github.com/puppetlabs/relay-pls/pkg/opt"
@@ -17,6 +20,7 @@ import (
 	"github.com/puppetlabs/relay-pls/pkg/test/mock"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -27,14 +31,34 @@ const (
 
 type mockListService_ListMessageServer struct {
 	grpc.ServerStream
+	Ctx      context.Context
 	Messages []*plspb.LogMessageListResponse
 }
 
+func (mls *mockListService_ListMessageServer) Context() context.Context {
+	return mls.Ctx
+}
+
 func (mls *mockListService_ListMessageServer) Send(m *plspb.LogMessageListResponse) error {
 	mls.Messages = append(mls.Messages, m)
 	return nil
 }
 
+type mockListService_ListServer struct {
+	grpc.ServerStream
+	Ctx  context.Context
+	Logs []*plspb.LogListResponse
+}
+
+func (mls *mockListService_ListServer) Context() context.Context {
+	return mls.Ctx
+}
+
+func (mls *mockListService_ListServer) Send(m *plspb.LogListResponse) error {
+	mls.Logs = append(mls.Logs, m)
+	return nil
+}
+
 func TestBigQueryServer(t *testing.T) {
 	if testing.Short() {
 		t.SkipNow()
@@ -65,7 +89,10 @@ func TestBigQueryServer(t *testing.T) {
 
 	lmm := mock.NewMockLogMetadataManager(ctrl)
 
-	s := server.NewBigQueryServer(cfg, km, lmm, bigqueryClient, bigqueryTable, nil)
+	mediaTypes, err := server.NewMediaTypeRegistry(cfg)
+	assert.NoError(t, err)
+
+	s := server.NewBigQueryServer(cfg, km, lmm, mediaTypes, server.NewRateLimiter(cfg), server.NewIdempotencyCache(cfg), bigqueryClient, bigqueryTable, nil)
 
 	testLogMessages(t, cfg, s, km, lmm)
 }
@@ -84,13 +111,199 @@ func TestInMemoryServer(t *testing.T) {
 
 	lmm := mock.NewMockLogMetadataManager(ctrl)
 
-	s := server.NewInMemoryServer(cfg, km, lmm)
+	mediaTypes, err := server.NewMediaTypeRegistry(cfg)
+	assert.NoError(t, err)
+
+	s := server.NewInMemoryServer(cfg, km, lmm, mediaTypes, server.NewRateLimiter(cfg), server.NewIdempotencyCache(cfg))
 
 	testLogMessages(t, cfg, s, km, lmm)
 }
 
+func TestInMemoryServerDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	cfg, err := opt.NewConfig()
+	assert.NoError(t, err)
+
+	km := manager.NewKeyManager()
+
+	lmm := mock.NewMockLogMetadataManager(ctrl)
+
+	mediaTypes, err := server.NewMediaTypeRegistry(cfg)
+	assert.NoError(t, err)
+
+	s := server.NewInMemoryServer(cfg, km, lmm, mediaTypes, server.NewRateLimiter(cfg), server.NewIdempotencyCache(cfg))
+
+	log := &model.Log{Context: uuid.New().String(), Name: "stdout"}
+
+	credential := &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{log.Context},
+	}
+
+	ctx := auth.WithCredential(context.Background(), credential)
+
+	logMetadata, err := createLogMetadata(ctx, []*model.Log{log}, km)
+	assert.NoError(t, err)
+
+	deleted := false
+	lmm.EXPECT().Create(gomock.Any(), gomock.Eq(log), gomock.Eq(credential.ID)).Return(logMetadata[0], nil).AnyTimes()
+	lmm.EXPECT().Get(gomock.Any(), gomock.Eq(logMetadata[0].LogID)).DoAndReturn(
+		func(ctx context.Context, id string) (*model.LogMetadata, error) {
+			if deleted {
+				return nil, manager.ErrLogNotFound
+			}
+
+			return logMetadata[0], nil
+		}).AnyTimes()
+	lmm.EXPECT().Delete(gomock.Any(), gomock.Eq(logMetadata[0].LogID)).DoAndReturn(
+		func(ctx context.Context, id string) error {
+			deleted = true
+			return nil
+		}).Times(1)
+	// The messages are removed as the log is deleted, so nothing is left to
+	// purge later.
+	lmm.EXPECT().Purged(gomock.Any(), gomock.Eq(logMetadata[0].LogID)).Return(nil).Times(1)
+	expectSequences(lmm)
+
+	createResponse, err := s.Create(ctx, &plspb.LogCreateRequest{Context: log.Context, Name: log.Name})
+	assert.NoError(t, err)
+
+	_, err = s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{
+		LogId:   createResponse.GetLogId(),
+		Payload: []byte("test-message"),
+	})
+	assert.NoError(t, err)
+
+	readOnlyCtx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{log.Context},
+		Scopes:   []string{model.ScopeLogRead},
+	})
+
+	_, err = s.Delete(readOnlyCtx, &plspb.LogDeleteRequest{LogId: createResponse.GetLogId()})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+
+	deleteResponse, err := s.Delete(ctx, &plspb.LogDeleteRequest{LogId: createResponse.GetLogId()})
+	assert.NoError(t, err)
+	assert.NotNil(t, deleteResponse)
+
+	_, err = s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{
+		LogId:   createResponse.GetLogId(),
+		Payload: []byte("test-message"),
+	})
+	assert.Equal(t, codes.NotFound, server.Status(err).Code())
+
+	err = s.MessageList(&plspb.LogMessageListRequest{LogId: createResponse.GetLogId()}, &mockListService_ListMessageServer{Ctx: ctx})
+	assert.Equal(t, codes.NotFound, server.Status(err).Code())
+
+	_, err = s.Delete(ctx, &plspb.LogDeleteRequest{LogId: createResponse.GetLogId()})
+	assert.Equal(t, codes.NotFound, server.Status(err).Code())
+}
+
+func TestInMemoryServerList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	cfg, err := opt.NewConfig()
+	assert.NoError(t, err)
+
+	km := manager.NewKeyManager()
+
+	lmm := mock.NewMockLogMetadataManager(ctrl)
+
+	mediaTypes, err := server.NewMediaTypeRegistry(cfg)
+	assert.NoError(t, err)
+
+	s := server.NewInMemoryServer(cfg, km, lmm, mediaTypes, server.NewRateLimiter(cfg), server.NewIdempotencyCache(cfg))
+
+	all := []*model.LogMetadata{
+		{LogID: uuid.New().String(), Log: &model.Log{Context: "runs/1/steps/a", Name: "stdout"}},
+		{LogID: uuid.New().String(), Log: &model.Log{Context: "runs/1/steps/a", Name: "stderr"}},
+		{LogID: uuid.New().String(), Log: &model.Log{Context: "runs/1/steps/b", Name: "stdout"}},
+		{LogID: uuid.New().String(), Log: &model.Log{Context: "runs/2/steps/a", Name: "stdout"}},
+	}
+
+	// Only contexts beneath the prefixes are searched, as in Vault.
+	beneath := func(prefixes []string, logContext string) bool {
+		for _, prefix := range prefixes {
+			if logContext == prefix || strings.HasPrefix(logContext, prefix+"/") {
+				return true
+			}
+		}
+
+		return len(prefixes) == 0
+	}
+
+	lmm.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, prefixes []string, match func(string) bool) ([]*model.LogMetadata, error) {
+			var r []*model.LogMetadata
+			for _, lm := range all {
+				if beneath(prefixes, lm.Log.Context) && match(lm.Log.Context) {
+					r = append(r, lm)
+				}
+			}
+
+			return r, nil
+		}).AnyTimes()
+
+	ctx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{"runs/1/*"},
+		Scopes:   []string{model.ScopeLogRead},
+	})
+
+	tests := []struct {
+		Name     string
+		Contexts []string
+		Expected []string
+	}{
+		{
+			Name:     "All granted contexts",
+			Expected: []string{all[0].LogID, all[1].LogID, all[2].LogID},
+		},
+		{
+			Name:     "Exact context",
+			Contexts: []string{"runs/1/steps/a"},
+			Expected: []string{all[0].LogID, all[1].LogID},
+		},
+		{
+			Name:     "Pattern",
+			Contexts: []string{"runs/1/steps/*"},
+			Expected: []string{all[0].LogID, all[1].LogID, all[2].LogID},
+		},
+		{
+			Name:     "Context not granted",
+			Contexts: []string{"runs/2/steps/a"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stream := &mockListService_ListServer{Ctx: ctx}
+			assert.NoError(t, s.List(&plspb.LogListRequest{Contexts: test.Contexts}, stream))
+
+			var ids []string
+			for _, l := range stream.Logs {
+				ids = append(ids, l.GetLogId())
+			}
+			assert.Equal(t, test.Expected, ids)
+		})
+	}
+
+	err = s.List(&plspb.LogListRequest{Contexts: []string{"runs/*/steps"}}, &mockListService_ListServer{Ctx: ctx})
+	assert.Equal(t, auth.ErrInvalidPattern, err)
+
+	appendOnlyCtx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{"runs/1/*"},
+		Scopes:   []string{model.ScopeLogAppend},
+	})
+
+	err = s.List(&plspb.LogListRequest{}, &mockListService_ListServer{Ctx: appendOnlyCtx})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
+}
+
 func testLogMessages(t *testing.T, cfg *opt.Config, s plspb.LogServer, km model.KeyManager, lmm *mock.MockLogMetadataManager) {
-	ctx := context.Background()
+	credential := &model.Credential{ID: uuid.New().String()}
 
 	logs := []*model.Log{}
 	for logIndex := 0; logIndex < MAX_LOG_COUNT; logIndex++ {
@@ -104,8 +317,12 @@ func testLogMessages(t *testing.T, cfg *opt.Config, s plspb.LogServer, km model.
 				Context: logContext,
 				Name:    "stderr",
 			})
+
+		credential.Contexts = append(credential.Contexts, logContext)
 	}
 
+	ctx := auth.WithCredential(context.Background(), credential)
+
 	logMetadata, err := createLogMetadata(ctx, logs, km)
 	assert.NoError(t, err)
 
@@ -134,35 +351,108 @@ func testLogMessages(t *testing.T, cfg *opt.Config, s plspb.LogServer, km model.
 			assert.NotNil(t, messageResponse)
 			assert.NotEmpty(t, messageResponse.GetLogMessageId())
 			assert.Equal(t, messageResponse.GetLogId(), createResponse.GetLogId())
+			assert.Equal(t, int64(messageIndex+1), messageResponse.GetSequence())
 
 			expectedMessages[messageResponse.GetLogMessageId()] = &plspb.LogMessageListResponse{
 				LogMessageId: messageResponse.GetLogMessageId(),
+				MediaType:    server.MediaTypeOctetStream,
 				Payload:      payload,
 				Timestamp:    ts,
+				Sequence:     messageResponse.GetSequence(),
 			}
 		}
 
-		stream := &mockListService_ListMessageServer{}
+		stream := &mockListService_ListMessageServer{Ctx: ctx}
 		err = s.MessageList(&plspb.LogMessageListRequest{Follow: false, LogId: createResponse.GetLogId()}, stream)
 		assert.NoError(t, err)
 		assert.Equal(t, MAX_LOG_MESSAGE_COUNT, len(stream.Messages))
 
-		var lastTimestamp time.Time
 		for index, message := range stream.Messages {
 			expected, ok := expectedMessages[message.GetLogMessageId()]
 			assert.True(t, ok)
 
 			assert.Equal(t, expected.GetLogMessageId(), message.GetLogMessageId())
+			assert.Equal(t, expected.GetMediaType(), message.GetMediaType())
 			assert.Equal(t, expected.GetPayload(), message.GetPayload())
 			assert.Equal(t, expected.GetTimestamp().AsTime().Truncate(time.Microsecond), message.GetTimestamp().AsTime().Truncate(time.Microsecond))
+			assert.Equal(t, int64(index+1), message.GetSequence())
+		}
 
-			if index > 0 {
-				assert.False(t, message.GetTimestamp().AsTime().Truncate(time.Microsecond).Before(lastTimestamp))
-			}
+		stream = &mockListService_ListMessageServer{Ctx: ctx}
+		err = s.MessageList(&plspb.LogMessageListRequest{LogId: createResponse.GetLogId(), AfterSequence: 2}, stream)
+		assert.NoError(t, err)
+		if assert.Len(t, stream.Messages, MAX_LOG_MESSAGE_COUNT-2) {
+			assert.Equal(t, int64(3), stream.Messages[0].GetSequence())
+		}
+
+		afterMessageID := stream.Messages[0].GetLogMessageId()
+
+		stream = &mockListService_ListMessageServer{Ctx: ctx}
+		err = s.MessageList(&plspb.LogMessageListRequest{LogId: createResponse.GetLogId(), AfterMessageId: afterMessageID}, stream)
+		assert.NoError(t, err)
+		if assert.Len(t, stream.Messages, MAX_LOG_MESSAGE_COUNT-3) {
+			assert.Equal(t, int64(4), stream.Messages[0].GetSequence())
+		}
 
-			lastTimestamp = message.GetTimestamp().AsTime().Truncate(time.Microsecond)
+		for _, req := range []*plspb.LogMessageListRequest{
+			{LogId: createResponse.GetLogId(), AfterSequence: 2, AfterMessageId: afterMessageID},
+			{LogId: createResponse.GetLogId(), AfterSequence: -1},
+			{LogId: createResponse.GetLogId(), AfterMessageId: uuid.New().String()},
+		} {
+			err = s.MessageList(req, &mockListService_ListMessageServer{Ctx: ctx})
+			assert.ErrorIs(t, err, server.ErrInvalid)
 		}
+
+		otherCtx := auth.WithCredential(context.Background(), &model.Credential{
+			ID:       uuid.New().String(),
+			Contexts: []string{uuid.New().String()},
+		})
+
+		_, err = s.MessageAppend(otherCtx, &plspb.LogMessageAppendRequest{
+			LogId:   createResponse.GetLogId(),
+			Payload: []byte("denied"),
+		})
+		assert.Equal(t, auth.ErrPermissionDenied, err)
+
+		err = s.MessageList(&plspb.LogMessageListRequest{LogId: createResponse.GetLogId()}, &mockListService_ListMessageServer{Ctx: otherCtx})
+		assert.Equal(t, auth.ErrPermissionDenied, err)
+
+		appendOnlyCtx := auth.WithCredential(context.Background(), &model.Credential{
+			ID:       uuid.New().String(),
+			Contexts: []string{log.Context},
+			Scopes:   []string{model.ScopeLogAppend},
+		})
+
+		err = s.MessageList(&plspb.LogMessageListRequest{LogId: createResponse.GetLogId()}, &mockListService_ListMessageServer{Ctx: appendOnlyCtx})
+		assert.Equal(t, auth.ErrPermissionDenied, err)
+
+		readOnlyCtx := auth.WithCredential(context.Background(), &model.Credential{
+			ID:       uuid.New().String(),
+			Contexts: []string{log.Context},
+			Scopes:   []string{model.ScopeLogRead},
+		})
+
+		_, err = s.MessageAppend(readOnlyCtx, &plspb.LogMessageAppendRequest{
+			LogId:   createResponse.GetLogId(),
+			Payload: []byte("denied"),
+		})
+		assert.Equal(t, auth.ErrPermissionDenied, err)
+
+		_, err = s.Create(readOnlyCtx, &plspb.LogCreateRequest{Context: log.Context, Name: log.Name})
+		assert.Equal(t, auth.ErrPermissionDenied, err)
 	}
+
+	singleContextCtx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{logs[0].Context},
+	})
+
+	createResponse, err := s.Create(singleContextCtx, &plspb.LogCreateRequest{Name: logs[0].Name})
+	assert.NoError(t, err)
+	assert.Equal(t, logMetadata[0].LogID, createResponse.GetLogId())
+
+	_, err = s.Create(singleContextCtx, &plspb.LogCreateRequest{Context: logs[2].Context, Name: logs[2].Name})
+	assert.Equal(t, auth.ErrPermissionDenied, err)
 }
 
 func createLogMetadata(ctx context.Context, logs []*model.Log, km model.KeyManager) ([]*model.LogMetadata, error) {
@@ -190,14 +480,40 @@ func setExpectations(ctx context.Context, logs []*model.Log, logMetadata []*mode
 	for index, log := range logs {
 		m.
 			EXPECT().
-			Create(ctx, gomock.Eq(log)).
+			Create(gomock.Any(), gomock.Eq(log), gomock.Any()).
 			Return(logMetadata[index], nil).
 			AnyTimes()
 
 		m.
 			EXPECT().
-			Get(ctx, gomock.Eq(logMetadata[index].LogID)).
+			Get(gomock.Any(), gomock.Eq(logMetadata[index].LogID)).
 			Return(logMetadata[index], nil).
 			AnyTimes()
 	}
+
+	expectSequences(m)
+}
+
+// expectSequences reserves sequences for any log, counting from 1 for each.
+func expectSequences(m *mock.MockLogMetadataManager) {
+	var mut sync.Mutex
+	next := make(map[string]int64)
+
+	m.
+		EXPECT().
+		ReserveSequence(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, id string, n int) (int64, error) {
+			mut.Lock()
+			defer mut.Unlock()
+
+			if next[id] == 0 {
+				next[id] = 1
+			}
+
+			first := next[id]
+			next[id] += int64(n)
+
+			return first, nil
+		}).
+		AnyTimes()
 }
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/puppetlabs/relay-pls/pkg/model"
+)
+
+// systemLogWriter appends messages to the logs kept by the service itself.
+// Unlike a messageBatch, it acts on no client's behalf: messages are not
+// authorized, rate limited or validated, and the logs are created in the
+// system context, which credentials may at most be granted to read.
+type systemLogWriter struct {
+	logMetadataManager model.LogMetadataManager
+	keyManager         model.KeyManager
+	follows            *followHub
+	insert             messageInserter
+
+	mut  sync.Mutex
+	logs map[string]*model.LogMetadata
+}
+
+// write stores messages in the named system log, in order, reserving their
+// sequences together.
+func (w *systemLogWriter) write(ctx context.Context, name string, messages []*model.SystemLogMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	lm, err := w.log(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	cipher, err := w.keyManager.Cipher(ctx, lm.Key)
+	if err != nil {
+		return err
+	}
+
+	stored := make([]*LogMessage, len(messages))
+	payloads := make([][]byte, len(messages))
+	for i, message := range messages {
+		ct, err := cipher.Encrypt(message.Payload)
+		if err != nil {
+			return err
+		}
+
+		stored[i] = &LogMessage{
+			LogID:            lm.LogID,
+			LogMessageID:     newLogMessageID(lm.LogID, ""),
+			Timestamp:        message.Timestamp,
+			MediaType:        message.MediaType,
+			EncryptedPayload: ct,
+		}
+		payloads[i] = message.Payload
+	}
+
+	first, err := w.logMetadataManager.ReserveSequence(ctx, lm.LogID, len(stored))
+	if err != nil {
+		return err
+	}
+
+	for i, message := range stored {
+		message.Sequence = first + int64(i)
+	}
+
+	rowErrs, err := w.insert(ctx, stored)
+	if err != nil {
+		return err
+	}
+
+	var rerr error
+	var published []*LogMessage
+	var publishedPayloads [][]byte
+	for i, message := range stored {
+		if rowErrs[i] != nil {
+			rerr = rowErrs[i]
+			continue
+		}
+
+		published = append(published, message)
+		publishedPayloads = append(publishedPayloads, payloads[i])
+	}
+
+	w.follows.publishAppended(published, publishedPayloads)
+
+	return rerr
+}
+
+// log returns the metadata of the named system log, creating the log the
+// first time it is written to.
+func (w *systemLogWriter) log(ctx context.Context, name string) (*model.LogMetadata, error) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	if lm, found := w.logs[name]; found {
+		return lm, nil
+	}
+
+	lm, err := w.logMetadataManager.Create(ctx, &model.Log{
+		Context: model.SystemContext,
+		Name:    name,
+	}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	w.logs[name] = lm
+
+	return lm, nil
+}
+
+func newSystemLogWriter(logMetadataManager model.LogMetadataManager, keyManager model.KeyManager,
+	follows *followHub, insert messageInserter) *systemLogWriter {
+	return &systemLogWriter{
+		logMetadataManager: logMetadataManager,
+		keyManager:         keyManager,
+		follows:            follows,
+		insert:             insert,
+		logs:               make(map[string]*model.LogMetadata),
+	}
+}
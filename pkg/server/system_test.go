@@ -0,0 +1,124 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/puppetlabs/relay-pls/pkg/audit"
+	"github.com/puppetlabs/relay-pls/pkg/auth"
+	"github.com/puppetlabs/relay-pls/pkg/manager"
+	"github.com/puppetlabs/relay-pls/pkg/model"
+	"github.com/puppetlabs/relay-pls/pkg/opt"
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/puppetlabs/relay-pls/pkg/test/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestInMemoryServerWriteSystemLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	cfg, err := opt.NewConfig()
+	require.NoError(t, err)
+
+	km := manager.NewKeyManager()
+	lmm := mock.NewMockLogMetadataManager(ctrl)
+
+	mediaTypes, err := server.NewMediaTypeRegistry(cfg)
+	require.NoError(t, err)
+
+	s := server.NewInMemoryServer(cfg, km, lmm, mediaTypes, server.NewRateLimiter(cfg), server.NewIdempotencyCache(cfg))
+
+	log := &model.Log{Context: model.SystemContext, Name: "audit"}
+
+	logMetadata, err := createLogMetadata(context.Background(), []*model.Log{log}, km)
+	require.NoError(t, err)
+
+	// The log is created once, without a creator, and then remembered.
+	lmm.EXPECT().Create(gomock.Any(), gomock.Eq(log), gomock.Eq("")).Return(logMetadata[0], nil).Times(1)
+	lmm.EXPECT().Get(gomock.Any(), gomock.Eq(logMetadata[0].LogID)).Return(logMetadata[0], nil).AnyTimes()
+	expectSequences(lmm)
+
+	writer, ok := s.(model.SystemLogWriter)
+	require.True(t, ok)
+
+	for _, payloads := range [][]string{{"first", "second"}, {"third"}} {
+		var messages []*model.SystemLogMessage
+		for _, payload := range payloads {
+			messages = append(messages, &model.SystemLogMessage{
+				Timestamp: time.Now(),
+				MediaType: server.MediaTypeText,
+				Payload:   []byte(payload),
+			})
+		}
+
+		require.NoError(t, writer.WriteSystemLog(context.Background(), log.Name, messages))
+	}
+
+	event := &audit.Event{
+		Time:         time.Now().UTC(),
+		CredentialID: "actor",
+		Method:       "/plspb.Log/Delete",
+		LogID:        "log-1",
+		Outcome:      "OK",
+	}
+	require.NoError(t, audit.NewLogSink(writer).Write(context.Background(), []*audit.Event{event}))
+
+	// A credential granted the system context explicitly can read the log,
+	// and nothing else.
+	readerCtx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{model.SystemContext},
+		Scopes:   model.Scopes,
+	})
+
+	stream := &mockListService_ListMessageServer{Ctx: readerCtx}
+	require.NoError(t, s.MessageList(&plspb.LogMessageListRequest{LogId: logMetadata[0].LogID}, stream))
+	require.Len(t, stream.Messages, 4)
+
+	for i, payload := range []string{"first", "second", "third"} {
+		assert.Equal(t, payload, string(stream.Messages[i].GetPayload()))
+	}
+
+	read := &audit.Event{}
+	require.NoError(t, json.Unmarshal(stream.Messages[3].GetPayload(), read))
+	assert.Equal(t, event, read)
+
+	_, err = s.Delete(readerCtx, &plspb.LogDeleteRequest{LogId: logMetadata[0].LogID})
+	assert.ErrorIs(t, err, auth.ErrPermissionDenied)
+
+	_, err = s.Seal(readerCtx, &plspb.LogSealRequest{LogId: logMetadata[0].LogID})
+	assert.ErrorIs(t, err, auth.ErrPermissionDenied)
+
+	_, err = s.MessageAppend(readerCtx, &plspb.LogMessageAppendRequest{
+		LogId:     logMetadata[0].LogID,
+		Timestamp: timestamppb.Now(),
+		MediaType: server.MediaTypeText,
+		Payload:   []byte("forged"),
+	})
+	assert.ErrorIs(t, err, auth.ErrPermissionDenied)
+
+	_, err = s.Create(readerCtx, &plspb.LogCreateRequest{Context: model.SystemContext, Name: "audit"})
+	assert.ErrorIs(t, err, auth.ErrPermissionDenied)
+
+	// Not even the root credential can read or delete the log.
+	rootCtx := auth.WithCredential(context.Background(), &model.Credential{
+		ID:       uuid.New().String(),
+		Contexts: []string{model.WildcardContext},
+	})
+
+	err = s.MessageList(&plspb.LogMessageListRequest{LogId: logMetadata[0].LogID}, &mockListService_ListMessageServer{Ctx: rootCtx})
+	assert.ErrorIs(t, err, auth.ErrPermissionDenied)
+
+	_, err = s.Delete(rootCtx, &plspb.LogDeleteRequest{LogId: logMetadata[0].LogID})
+	assert.ErrorIs(t, err, auth.ErrPermissionDenied)
+
+	_, err = s.Create(rootCtx, &plspb.LogCreateRequest{Context: model.SystemContext, Name: "audit"})
+	assert.ErrorIs(t, err, auth.ErrPermissionDenied)
+}
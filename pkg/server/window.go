@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+)
+
+// listMaxTail is the most messages a list request may tail. The tail is read
+// newest first and must be held in memory to be sent in ascending order.
+const listMaxTail = 10000
+
+// listWindow is the part of a log, after filtering, that a list request
+// returns.
+type listWindow struct {
+	limit      int
+	descending bool
+	tail       int
+}
+
+// newListWindow validates the limit, order and tail of a list request.
+func newListWindow(in *plspb.LogMessageListRequest) (listWindow, error) {
+	w := listWindow{
+		limit: int(in.GetLimit()),
+		tail:  int(in.GetTail()),
+	}
+
+	switch in.GetOrder() {
+	case plspb.LogMessageListRequest_ORDER_ASCENDING:
+	case plspb.LogMessageListRequest_ORDER_DESCENDING:
+		w.descending = true
+	default:
+		return listWindow{}, NewFieldError("order", "is not a known order")
+	}
+
+	switch {
+	case w.limit < 0:
+		return listWindow{}, NewFieldError("limit", "must not be negative")
+	case w.limit > 0 && in.GetFollow():
+		return listWindow{}, NewFieldError("limit", "cannot be combined with follow")
+	case w.tail < 0:
+		return listWindow{}, NewFieldError("tail", "must not be negative")
+	case w.tail > listMaxTail:
+		return listWindow{}, NewFieldError("tail", fmt.Sprintf("must be at most %d", listMaxTail))
+	case w.tail > 0 && w.limit > 0:
+		return listWindow{}, NewFieldError("tail", "cannot be combined with limit")
+	case w.descending && in.GetFollow():
+		return listWindow{}, NewFieldError("order", "cannot be descending when following")
+	case w.descending && w.tail > 0:
+		return listWindow{}, NewFieldError("order", "cannot be descending when tailing")
+	}
+
+	return w, nil
+}
+
+// apply returns the window of messages already filtered and in ascending
+// order.
+func (w listWindow) apply(messages []*plspb.LogMessageListResponse) []*plspb.LogMessageListResponse {
+	if w.tail > 0 && len(messages) > w.tail {
+		messages = messages[len(messages)-w.tail:]
+	}
+
+	if w.descending {
+		reversed := make([]*plspb.LogMessageListResponse, len(messages))
+		for i, message := range messages {
+			reversed[len(messages)-1-i] = message
+		}
+		messages = reversed
+	}
+
+	if w.limit > 0 && len(messages) > w.limit {
+		messages = messages[:w.limit]
+	}
+
+	return messages
+}
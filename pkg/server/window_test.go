@@ -0,0 +1,129 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/puppetlabs/relay-pls/pkg/plspb"
+	"github.com/puppetlabs/relay-pls/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryServerMessageListWindow(t *testing.T) {
+	s, ctx, logMetadata := newBatchTestServer(t)
+
+	logID := logMetadata[0].LogID
+
+	for i := 1; i <= 5; i++ {
+		_, err := s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: logID, Payload: []byte(fmt.Sprint(i))})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		Name     string
+		Request  *plspb.LogMessageListRequest
+		Expected []string
+	}{
+		{
+			Name:     "limit",
+			Request:  &plspb.LogMessageListRequest{LogId: logID, Limit: 2},
+			Expected: []string{"1", "2"},
+		},
+		{
+			Name:     "descending",
+			Request:  &plspb.LogMessageListRequest{LogId: logID, Order: plspb.LogMessageListRequest_ORDER_DESCENDING},
+			Expected: []string{"5", "4", "3", "2", "1"},
+		},
+		{
+			Name:     "descending with limit",
+			Request:  &plspb.LogMessageListRequest{LogId: logID, Order: plspb.LogMessageListRequest_ORDER_DESCENDING, Limit: 2},
+			Expected: []string{"5", "4"},
+		},
+		{
+			Name:     "tail",
+			Request:  &plspb.LogMessageListRequest{LogId: logID, Tail: 2},
+			Expected: []string{"4", "5"},
+		},
+		{
+			Name:     "tail longer than log",
+			Request:  &plspb.LogMessageListRequest{LogId: logID, Tail: 10},
+			Expected: []string{"1", "2", "3", "4", "5"},
+		},
+		{
+			Name:     "tail after sequence",
+			Request:  &plspb.LogMessageListRequest{LogId: logID, Tail: 10, AfterSequence: 3},
+			Expected: []string{"4", "5"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			stream := &mockListService_ListMessageServer{Ctx: ctx}
+			require.NoError(t, s.MessageList(test.Request, stream))
+
+			var payloads []string
+			for _, message := range stream.Messages {
+				payloads = append(payloads, string(message.GetPayload()))
+			}
+			assert.Equal(t, test.Expected, payloads)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, req := range []*plspb.LogMessageListRequest{
+			{LogId: logID, Limit: -1},
+			{LogId: logID, Tail: -1},
+			{LogId: logID, Tail: 10001},
+			{LogId: logID, Tail: 1, Limit: 1},
+			{LogId: logID, Limit: 1, Follow: true},
+			{LogId: logID, Order: plspb.LogMessageListRequest_ORDER_DESCENDING, Follow: true},
+			{LogId: logID, Order: plspb.LogMessageListRequest_ORDER_DESCENDING, Tail: 1},
+			{LogId: logID, Order: plspb.LogMessageListRequest_Order(7)},
+		} {
+			err := s.MessageList(req, &mockListService_ListMessageServer{Ctx: ctx})
+			assert.ErrorIs(t, err, server.ErrInvalid, "%+v", req)
+		}
+	})
+
+	t.Run("tail and follow", func(t *testing.T) {
+		followCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream := &mockFollowService_MessageListServer{
+			Ctx:      followCtx,
+			Messages: make(chan *plspb.LogMessageListResponse, 10),
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- s.MessageList(&plspb.LogMessageListRequest{LogId: logID, Tail: 2, Follow: true}, stream)
+		}()
+
+		receive := func() string {
+			select {
+			case m := <-stream.Messages:
+				return string(m.GetPayload())
+			case err := <-errCh:
+				require.FailNow(t, "follow ended early", "%+v", err)
+			case <-time.After(5 * time.Second):
+				require.FailNow(t, "timed out waiting for message")
+			}
+
+			return ""
+		}
+
+		assert.Equal(t, "4", receive())
+		assert.Equal(t, "5", receive())
+
+		_, err := s.MessageAppend(ctx, &plspb.LogMessageAppendRequest{LogId: logID, Payload: []byte("6")})
+		require.NoError(t, err)
+		assert.Equal(t, "6", receive())
+
+		cancel()
+		<-errCh
+
+		assert.Empty(t, stream.Messages)
+	})
+}
@@ -16,9 +16,15 @@ var ServerSet = wire.NewSet(
 	NewTelemetryServer,
 )
 
-var ProviderSet = wire.NewSet(
+// ExporterSet provides the exporter whose registry the telemetry server
+// serves. It must only be used once per process; everything that records
+// metrics is given the meter of that exporter from MeterSet instead.
+var ExporterSet = wire.NewSet(
 	ProvidePrometheusConfig,
 	ProvidePrometheusExporter,
+)
+
+var MeterSet = wire.NewSet(
 	ProvideMeter,
 )
 
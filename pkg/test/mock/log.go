@@ -6,35 +6,142 @@ package mock
 
 import (
 	context "context"
+	reflect "reflect"
+	time "time"
+
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/puppetlabs/relay-pls/pkg/model"
-	reflect "reflect"
 )
 
-// MockKeyManager is a mock of KeyManager interface
+// MockSystemLogWriter is a mock of SystemLogWriter interface.
+type MockSystemLogWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockSystemLogWriterMockRecorder
+}
+
+// MockSystemLogWriterMockRecorder is the mock recorder for MockSystemLogWriter.
+type MockSystemLogWriterMockRecorder struct {
+	mock *MockSystemLogWriter
+}
+
+// NewMockSystemLogWriter creates a new mock instance.
+func NewMockSystemLogWriter(ctrl *gomock.Controller) *MockSystemLogWriter {
+	mock := &MockSystemLogWriter{ctrl: ctrl}
+	mock.recorder = &MockSystemLogWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSystemLogWriter) EXPECT() *MockSystemLogWriterMockRecorder {
+	return m.recorder
+}
+
+// WriteSystemLog mocks base method.
+func (m *MockSystemLogWriter) WriteSystemLog(ctx context.Context, name string, messages []*model.SystemLogMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteSystemLog", ctx, name, messages)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteSystemLog indicates an expected call of WriteSystemLog.
+func (mr *MockSystemLogWriterMockRecorder) WriteSystemLog(ctx, name, messages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSystemLog", reflect.TypeOf((*MockSystemLogWriter)(nil).WriteSystemLog), ctx, name, messages)
+}
+
+// MockCipher is a mock of Cipher interface.
+type MockCipher struct {
+	ctrl     *gomock.Controller
+	recorder *MockCipherMockRecorder
+}
+
+// MockCipherMockRecorder is the mock recorder for MockCipher.
+type MockCipherMockRecorder struct {
+	mock *MockCipher
+}
+
+// NewMockCipher creates a new mock instance.
+func NewMockCipher(ctrl *gomock.Controller) *MockCipher {
+	mock := &MockCipher{ctrl: ctrl}
+	mock.recorder = &MockCipherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCipher) EXPECT() *MockCipherMockRecorder {
+	return m.recorder
+}
+
+// Decrypt mocks base method.
+func (m *MockCipher) Decrypt(data []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Decrypt", data)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Decrypt indicates an expected call of Decrypt.
+func (mr *MockCipherMockRecorder) Decrypt(data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Decrypt", reflect.TypeOf((*MockCipher)(nil).Decrypt), data)
+}
+
+// Encrypt mocks base method.
+func (m *MockCipher) Encrypt(data []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Encrypt", data)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Encrypt indicates an expected call of Encrypt.
+func (mr *MockCipherMockRecorder) Encrypt(data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Encrypt", reflect.TypeOf((*MockCipher)(nil).Encrypt), data)
+}
+
+// MockKeyManager is a mock of KeyManager interface.
 type MockKeyManager struct {
 	ctrl     *gomock.Controller
 	recorder *MockKeyManagerMockRecorder
 }
 
-// MockKeyManagerMockRecorder is the mock recorder for MockKeyManager
+// MockKeyManagerMockRecorder is the mock recorder for MockKeyManager.
 type MockKeyManagerMockRecorder struct {
 	mock *MockKeyManager
 }
 
-// NewMockKeyManager creates a new mock instance
+// NewMockKeyManager creates a new mock instance.
 func NewMockKeyManager(ctrl *gomock.Controller) *MockKeyManager {
 	mock := &MockKeyManager{ctrl: ctrl}
 	mock.recorder = &MockKeyManagerMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockKeyManager) EXPECT() *MockKeyManagerMockRecorder {
 	return m.recorder
 }
 
-// Create mocks base method
+// Cipher mocks base method.
+func (m *MockKeyManager) Cipher(ctx context.Context, key string) (model.Cipher, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cipher", ctx, key)
+	ret0, _ := ret[0].(model.Cipher)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cipher indicates an expected call of Cipher.
+func (mr *MockKeyManagerMockRecorder) Cipher(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cipher", reflect.TypeOf((*MockKeyManager)(nil).Cipher), ctx, key)
+}
+
+// Create mocks base method.
 func (m *MockKeyManager) Create(ctx context.Context) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Create", ctx)
@@ -43,13 +150,13 @@ func (m *MockKeyManager) Create(ctx context.Context) (string, error) {
 	return ret0, ret1
 }
 
-// Create indicates an expected call of Create
+// Create indicates an expected call of Create.
 func (mr *MockKeyManagerMockRecorder) Create(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockKeyManager)(nil).Create), ctx)
 }
 
-// Decrypt mocks base method
+// Decrypt mocks base method.
 func (m *MockKeyManager) Decrypt(ctx context.Context, key string, data []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Decrypt", ctx, key, data)
@@ -58,13 +165,13 @@ func (m *MockKeyManager) Decrypt(ctx context.Context, key string, data []byte) (
 	return ret0, ret1
 }
 
-// Decrypt indicates an expected call of Decrypt
+// Decrypt indicates an expected call of Decrypt.
 func (mr *MockKeyManagerMockRecorder) Decrypt(ctx, key, data interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Decrypt", reflect.TypeOf((*MockKeyManager)(nil).Decrypt), ctx, key, data)
 }
 
-// Encrypt mocks base method
+// Encrypt mocks base method.
 func (m *MockKeyManager) Encrypt(ctx context.Context, key string, data []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Encrypt", ctx, key, data)
@@ -73,51 +180,79 @@ func (m *MockKeyManager) Encrypt(ctx context.Context, key string, data []byte) (
 	return ret0, ret1
 }
 
-// Encrypt indicates an expected call of Encrypt
+// Encrypt indicates an expected call of Encrypt.
 func (mr *MockKeyManagerMockRecorder) Encrypt(ctx, key, data interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Encrypt", reflect.TypeOf((*MockKeyManager)(nil).Encrypt), ctx, key, data)
 }
 
-// MockLogMetadataManager is a mock of LogMetadataManager interface
+// MockLogMetadataManager is a mock of LogMetadataManager interface.
 type MockLogMetadataManager struct {
 	ctrl     *gomock.Controller
 	recorder *MockLogMetadataManagerMockRecorder
 }
 
-// MockLogMetadataManagerMockRecorder is the mock recorder for MockLogMetadataManager
+// MockLogMetadataManagerMockRecorder is the mock recorder for MockLogMetadataManager.
 type MockLogMetadataManagerMockRecorder struct {
 	mock *MockLogMetadataManager
 }
 
-// NewMockLogMetadataManager creates a new mock instance
+// NewMockLogMetadataManager creates a new mock instance.
 func NewMockLogMetadataManager(ctrl *gomock.Controller) *MockLogMetadataManager {
 	mock := &MockLogMetadataManager{ctrl: ctrl}
 	mock.recorder = &MockLogMetadataManagerMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockLogMetadataManager) EXPECT() *MockLogMetadataManagerMockRecorder {
 	return m.recorder
 }
 
-// Create mocks base method
-func (m *MockLogMetadataManager) Create(ctx context.Context, log *model.Log) (*model.LogMetadata, error) {
+// Create mocks base method.
+func (m *MockLogMetadataManager) Create(ctx context.Context, log *model.Log, creator string) (*model.LogMetadata, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, log)
+	ret := m.ctrl.Call(m, "Create", ctx, log, creator)
 	ret0, _ := ret[0].(*model.LogMetadata)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Create indicates an expected call of Create
-func (mr *MockLogMetadataManagerMockRecorder) Create(ctx, log interface{}) *gomock.Call {
+// Create indicates an expected call of Create.
+func (mr *MockLogMetadataManagerMockRecorder) Create(ctx, log, creator interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockLogMetadataManager)(nil).Create), ctx, log)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockLogMetadataManager)(nil).Create), ctx, log, creator)
 }
 
-// Get mocks base method
+// DeferPurge mocks base method.
+func (m *MockLogMetadataManager) DeferPurge(ctx context.Context, id string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeferPurge", ctx, id, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeferPurge indicates an expected call of DeferPurge.
+func (mr *MockLogMetadataManagerMockRecorder) DeferPurge(ctx, id, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeferPurge", reflect.TypeOf((*MockLogMetadataManager)(nil).DeferPurge), ctx, id, until)
+}
+
+// Delete mocks base method.
+func (m *MockLogMetadataManager) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockLogMetadataManagerMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockLogMetadataManager)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
 func (m *MockLogMetadataManager) Get(ctx context.Context, id string) (*model.LogMetadata, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get", ctx, id)
@@ -126,8 +261,97 @@ func (m *MockLogMetadataManager) Get(ctx context.Context, id string) (*model.Log
 	return ret0, ret1
 }
 
-// Get indicates an expected call of Get
+// Get indicates an expected call of Get.
 func (mr *MockLogMetadataManagerMockRecorder) Get(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockLogMetadataManager)(nil).Get), ctx, id)
 }
+
+// List mocks base method.
+func (m *MockLogMetadataManager) List(ctx context.Context, prefixes []string, match func(string) bool) ([]*model.LogMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, prefixes, match)
+	ret0, _ := ret[0].([]*model.LogMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockLogMetadataManagerMockRecorder) List(ctx, prefixes, match interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLogMetadataManager)(nil).List), ctx, prefixes, match)
+}
+
+// ListContext mocks base method.
+func (m *MockLogMetadataManager) ListContext(ctx context.Context, logContext string) ([]*model.LogMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListContext", ctx, logContext)
+	ret0, _ := ret[0].([]*model.LogMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListContext indicates an expected call of ListContext.
+func (mr *MockLogMetadataManagerMockRecorder) ListContext(ctx, logContext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListContext", reflect.TypeOf((*MockLogMetadataManager)(nil).ListContext), ctx, logContext)
+}
+
+// ListDeleted mocks base method.
+func (m *MockLogMetadataManager) ListDeleted(ctx context.Context, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockLogMetadataManagerMockRecorder) ListDeleted(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockLogMetadataManager)(nil).ListDeleted), ctx, limit)
+}
+
+// Purged mocks base method.
+func (m *MockLogMetadataManager) Purged(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purged", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purged indicates an expected call of Purged.
+func (mr *MockLogMetadataManagerMockRecorder) Purged(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purged", reflect.TypeOf((*MockLogMetadataManager)(nil).Purged), ctx, id)
+}
+
+// ReserveSequence mocks base method.
+func (m *MockLogMetadataManager) ReserveSequence(ctx context.Context, id string, n int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveSequence", ctx, id, n)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveSequence indicates an expected call of ReserveSequence.
+func (mr *MockLogMetadataManagerMockRecorder) ReserveSequence(ctx, id, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveSequence", reflect.TypeOf((*MockLogMetadataManager)(nil).ReserveSequence), ctx, id, n)
+}
+
+// Seal mocks base method.
+func (m *MockLogMetadataManager) Seal(ctx context.Context, id string, seal *model.LogSeal) (*model.LogSeal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Seal", ctx, id, seal)
+	ret0, _ := ret[0].(*model.LogSeal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Seal indicates an expected call of Seal.
+func (mr *MockLogMetadataManagerMockRecorder) Seal(ctx, id, seal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seal", reflect.TypeOf((*MockLogMetadataManager)(nil).Seal), ctx, id, seal)
+}